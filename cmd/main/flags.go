@@ -0,0 +1,213 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/user/log-explorer-tui/pkg/config"
+	"github.com/user/log-explorer-tui/pkg/models"
+	"github.com/user/log-explorer-tui/pkg/ui"
+)
+
+// launchOptions holds command-line flags that seed the initial query context.
+type launchOptions struct {
+	Project  string
+	Filter   string
+	Library  string
+	Since    string
+	Until    string
+	Range    string
+	Severity string
+	Timezone string
+	Stream   bool
+	Fresh    bool
+	URL      string
+}
+
+// parseLaunchOptions parses command-line arguments. A single positional
+// argument is treated as a Logs Explorer URL; flags may appear before or after it.
+func parseLaunchOptions(args []string, output io.Writer) (launchOptions, error) {
+	opts := launchOptions{}
+	fs := flag.NewFlagSet("log-explorer", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprintln(output, "Usage: log-explorer [flags] [logs-explorer-url]")
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.Project, "project", "", "GCP project to query")
+	fs.StringVar(&opts.Filter, "filter", "", "Cloud Logging filter to run at startup")
+	fs.StringVar(&opts.Library, "library", "", "name of a saved library query to run at startup")
+	fs.StringVar(&opts.Since, "since", "", "range start (RFC3339, \"YYYY-MM-DD HH:MM:SS\" or a duration like 2h)")
+	fs.StringVar(&opts.Until, "until", "", "range end (same formats as --since, defaults to now)")
+	fs.StringVar(&opts.Range, "range", "", "relative range ending now, e.g. 15m, 1h, 7d")
+	fs.StringVar(&opts.Severity, "severity", "", "severity filter, e.g. \">=ERROR\" or \"WARNING,ERROR\"")
+	fs.StringVar(&opts.Timezone, "tz", "", "timestamp display timezone: utc or local")
+	fs.BoolVar(&opts.Stream, "stream", false, "start with streaming enabled")
+	fs.BoolVar(&opts.Fresh, "fresh", false, "ignore the saved session and start with a clean context")
+
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return launchOptions{}, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) > 1 {
+		return launchOptions{}, fmt.Errorf("expected at most one Logs Explorer URL, got %d arguments", len(positional))
+	}
+	if len(positional) == 1 {
+		opts.URL = positional[0]
+	}
+
+	if err := opts.validate(); err != nil {
+		return launchOptions{}, err
+	}
+	return opts, nil
+}
+
+func (o launchOptions) validate() error {
+	if o.Range != "" && (o.Since != "" || o.Until != "") {
+		return errors.New("--range cannot be combined with --since/--until")
+	}
+	if o.Until != "" && o.Since == "" {
+		return errors.New("--until requires --since")
+	}
+	if o.Range != "" {
+		if _, err := ui.ParseRangeDuration(o.Range); err != nil {
+			return fmt.Errorf("--range: %w", err)
+		}
+	}
+	if o.Severity != "" {
+		if _, err := ui.ParseSeverityExpr(o.Severity); err != nil {
+			return fmt.Errorf("--severity: %w", err)
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(o.Timezone)) {
+	case "", "utc", "local":
+	default:
+		return fmt.Errorf("--tz must be utc or local, got %q", o.Timezone)
+	}
+	return nil
+}
+
+// applyLaunchOptions seeds appState from the options and returns the startup
+// filter. Explicit flags take precedence over values decoded from the URL.
+func applyLaunchOptions(opts launchOptions, appState *models.AppState, library []config.SavedQueryRecord, now time.Time) (string, error) {
+	project := ""
+	filter := ""
+	urlRange := models.TimeRange{}
+
+	if opts.URL != "" {
+		slg := ui.NewShareLinkGenerator("")
+		q, fs, err := slg.ParseConsoleURL(opts.URL)
+		if err != nil {
+			return "", err
+		}
+		project = q.Project
+		filter = strings.TrimSpace(q.Filter)
+		urlRange = fs.TimeRange
+	}
+
+	if opts.Library != "" {
+		record, ok := findLibraryQuery(library, opts.Library)
+		if !ok {
+			return "", fmt.Errorf("no saved query named %q in the library", opts.Library)
+		}
+		filter = strings.TrimSpace(record.Filter)
+		if record.Project != "" {
+			project = record.Project
+		}
+	}
+
+	if extra := strings.TrimSpace(opts.Filter); extra != "" {
+		if filter != "" {
+			filter = filter + "\n" + extra
+		} else {
+			filter = extra
+		}
+	}
+
+	if strings.TrimSpace(opts.Project) != "" {
+		project = strings.TrimSpace(opts.Project)
+	}
+	if project != "" {
+		appState.CurrentProject = project
+		appState.CurrentQuery.Project = project
+	}
+
+	switch {
+	case opts.Range != "":
+		d, err := ui.ParseRangeDuration(opts.Range)
+		if err != nil {
+			return "", fmt.Errorf("--range: %w", err)
+		}
+		appState.FilterState.TimeRange = models.TimeRange{
+			Start:  now.Add(-d),
+			End:    now,
+			Preset: ui.NewTimePicker().PresetKeyForDuration(d),
+		}
+	case opts.Since != "":
+		start, err := ui.ParseTimeBound(opts.Since, now)
+		if err != nil {
+			return "", fmt.Errorf("--since: %w", err)
+		}
+		end := now
+		if opts.Until != "" {
+			end, err = ui.ParseTimeBound(opts.Until, now)
+			if err != nil {
+				return "", fmt.Errorf("--until: %w", err)
+			}
+		}
+		if !start.Before(end) {
+			return "", errors.New("--since must be before --until")
+		}
+		appState.FilterState.TimeRange = models.TimeRange{Start: start.UTC(), End: end.UTC(), Preset: "custom"}
+	case !urlRange.Start.IsZero() && !urlRange.End.IsZero():
+		appState.FilterState.TimeRange = urlRange
+	}
+
+	if opts.Severity != "" {
+		sf, err := ui.ParseSeverityExpr(opts.Severity)
+		if err != nil {
+			return "", fmt.Errorf("--severity: %w", err)
+		}
+		appState.FilterState.Severity = sf
+	}
+
+	if opts.Stream {
+		appState.StreamState.Enabled = true
+	}
+
+	if filter != "" {
+		appState.CurrentQuery.Filter = filter
+	}
+	return filter, nil
+}
+
+// hasTimeRange reports whether a usable time range has been seeded.
+func hasTimeRange(state *models.AppState) bool {
+	return !state.FilterState.TimeRange.Start.IsZero() && !state.FilterState.TimeRange.End.IsZero()
+}
+
+func findLibraryQuery(library []config.SavedQueryRecord, name string) (config.SavedQueryRecord, bool) {
+	name = strings.TrimSpace(name)
+	for _, record := range library {
+		if record.Name == name {
+			return record, true
+		}
+	}
+	for _, record := range library {
+		if strings.EqualFold(record.Name, name) {
+			return record, true
+		}
+	}
+	return config.SavedQueryRecord{}, false
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/user/log-explorer-tui/pkg/config"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func newTestAppState() models.AppState {
+	return initializeAppState(config.DefaultConfig(), config.State{CurrentProject: "saved-project"})
+}
+
+func TestParseLaunchOptions(t *testing.T) {
+	opts, err := parseLaunchOptions([]string{
+		"--project", "p1", "--range", "1h", "--severity", ">=ERROR",
+		"https://console.cloud.google.com/logs/query?project=p2",
+		"--tz", "local", "--stream", "--fresh",
+	}, io.Discard)
+	if err != nil {
+		t.Fatalf("parseLaunchOptions failed: %v", err)
+	}
+	if opts.Project != "p1" || opts.Range != "1h" || opts.Severity != ">=ERROR" {
+		t.Errorf("Unexpected options %+v", opts)
+	}
+	if opts.Timezone != "local" || !opts.Stream || !opts.Fresh {
+		t.Errorf("Flags after the URL should be parsed, got %+v", opts)
+	}
+	if opts.URL != "https://console.cloud.google.com/logs/query?project=p2" {
+		t.Errorf("Unexpected URL %q", opts.URL)
+	}
+}
+
+func TestParseLaunchOptionsInvalid(t *testing.T) {
+	cases := [][]string{
+		{"--range", "1h", "--since", "2h"},
+		{"--until", "1h"},
+		{"--range", "soon"},
+		{"--severity", ">=LOUD"},
+		{"--tz", "mars"},
+		{"one", "two"},
+		{"--unknown"},
+	}
+	for _, args := range cases {
+		if _, err := parseLaunchOptions(args, io.Discard); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
+}
+
+func TestApplyLaunchOptionsDefaults(t *testing.T) {
+	state := newTestAppState()
+	filter, err := applyLaunchOptions(launchOptions{}, &state, nil, time.Now())
+	if err != nil {
+		t.Fatalf("applyLaunchOptions failed: %v", err)
+	}
+	if filter != "" {
+		t.Errorf("Expected empty startup filter, got %q", filter)
+	}
+	if state.CurrentProject != "saved-project" {
+		t.Errorf("Expected persisted project to be kept, got %q", state.CurrentProject)
+	}
+	if hasTimeRange(&state) {
+		t.Error("Expected no explicit time range without flags")
+	}
+}
+
+func TestApplyLaunchOptionsFlags(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	state := newTestAppState()
+	opts := launchOptions{
+		Project:  "flag-project",
+		Filter:   `resource.type="k8s_container"`,
+		Range:    "7d",
+		Severity: "WARNING,ERROR",
+		Stream:   true,
+	}
+	filter, err := applyLaunchOptions(opts, &state, nil, now)
+	if err != nil {
+		t.Fatalf("applyLaunchOptions failed: %v", err)
+	}
+	if filter != `resource.type="k8s_container"` || state.CurrentQuery.Filter != filter {
+		t.Errorf("Unexpected filter %q", filter)
+	}
+	if state.CurrentProject != "flag-project" {
+		t.Errorf("Expected flag project, got %q", state.CurrentProject)
+	}
+	tr := state.FilterState.TimeRange
+	if tr.Preset != "7d" || !tr.End.Equal(now) || !tr.Start.Equal(now.Add(-7*24*time.Hour)) {
+		t.Errorf("Unexpected time range %+v", tr)
+	}
+	if state.FilterState.Severity.Mode != "individual" || len(state.FilterState.Severity.Levels) != 2 {
+		t.Errorf("Unexpected severity %+v", state.FilterState.Severity)
+	}
+	if !state.StreamState.Enabled {
+		t.Error("Expected streaming to be enabled")
+	}
+}
+
+func TestApplyLaunchOptionsLibraryAndURL(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	library := []config.SavedQueryRecord{
+		{Name: "Checkout errors", Filter: `labels.app="checkout"`, Project: "lib-project"},
+	}
+	state := newTestAppState()
+	opts := launchOptions{
+		Library: "checkout errors",
+		Filter:  "severity>=ERROR",
+		URL:     "https://console.cloud.google.com/logs/query;query=textPayload%3A%22boom%22;timeRange=PT1H?project=url-project",
+	}
+	filter, err := applyLaunchOptions(opts, &state, library, now)
+	if err != nil {
+		t.Fatalf("applyLaunchOptions failed: %v", err)
+	}
+	if filter != "labels.app=\"checkout\"\nseverity>=ERROR" {
+		t.Errorf("Expected library filter AND flag filter, got %q", filter)
+	}
+	if state.CurrentProject != "lib-project" {
+		t.Errorf("Expected library project to override URL, got %q", state.CurrentProject)
+	}
+	if state.FilterState.TimeRange.Preset != "1h" {
+		t.Errorf("Expected URL time range, got %+v", state.FilterState.TimeRange)
+	}
+
+	if _, err := applyLaunchOptions(launchOptions{Library: "missing"}, &state, library, now); err == nil {
+		t.Error("Expected error for unknown library entry")
+	}
+}
+
+func TestApplyLaunchOptionsSinceUntil(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	state := newTestAppState()
+	opts := launchOptions{Since: "2024-03-01T08:00:00Z", Until: "1h"}
+	if _, err := applyLaunchOptions(opts, &state, nil, now); err != nil {
+		t.Fatalf("applyLaunchOptions failed: %v", err)
+	}
+	tr := state.FilterState.TimeRange
+	if tr.Preset != "custom" || !tr.Start.Equal(now.Add(-4*time.Hour)) || !tr.End.Equal(now.Add(-time.Hour)) {
+		t.Errorf("Unexpected time range %+v", tr)
+	}
+
+	opts = launchOptions{Since: "1h", Until: "2h"}
+	if _, err := applyLaunchOptions(opts, &state, nil, now); err == nil {
+		t.Error("Expected error when --since is after --until")
+	}
+}
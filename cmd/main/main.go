@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -19,12 +21,33 @@ import (
 )
 
 func main() {
+	opts, err := parseLaunchOptions(os.Args[1:], os.Stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
 	// Phase 1: Bootstrap
 	// Load configuration
-	cfg, err := config.LoadConfig()
+	// Layers: defaults -> config.json -> preferences.json -> environment -> flags.
+	// The project override block belongs to the config.json layer; it is
+	// resolved again once the project is known.
+	fileCfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	prefs, err := config.LoadPreferences()
+	if err != nil {
+		log.Printf("Warning: Failed to load preferences: %v", err)
+	}
+	cfg, err := config.Resolve(fileCfg, prefs, "", os.LookupEnv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
 
 	// Load state
 	state, err := config.LoadState()
@@ -35,8 +58,30 @@ func main() {
 	// Initialize app state
 	appState := initializeAppState(cfg, state)
 
+	// Restore the previous session unless --fresh, then let flags override it.
+	restoreSession := state.Session != nil && !opts.Fresh
+	if opts.Fresh {
+		appState.CurrentQuery.Filter = ""
+	} else if restoreSession {
+		ui.RestoreSessionState(*state.Session, &appState, time.Now().UTC())
+	}
+
+	// Seed the query context from flags before resolving the project.
+	libraryStore, libraryErr := config.LoadQueryLibrary()
+	startupFilter, err := applyLaunchOptions(opts, &appState, libraryStore.Queries, time.Now().UTC())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	if startupFilter == "" {
+		startupFilter = strings.TrimSpace(appState.CurrentQuery.Filter)
+	}
+
 	// Attempt authentication
 	projectID := appState.CurrentProject
+	if projectID == "" {
+		projectID = cfg.DefaultProject
+	}
 	if projectID == "" {
 		projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
 	}
@@ -67,13 +112,62 @@ func main() {
 	}
 
 	// Phase 2: Start TUI
-	// Build initial startup filter for the past day; execution happens in TUI Init().
-	oneDayAgo := time.Now().AddDate(0, 0, -1)
-	filter := fmt.Sprintf(`timestamp>="%s"`, oneDayAgo.Format(time.RFC3339))
+	// Build initial startup filter; without an explicit range it covers the past day.
+	// Execution happens in TUI Init().
+	filter := startupFilter
+	if !hasTimeRange(&appState) {
+		oneDayAgo := time.Now().AddDate(0, 0, -1)
+		dayClause := fmt.Sprintf(`timestamp>="%s"`, oneDayAgo.Format(time.RFC3339))
+		if filter != "" {
+			filter = filter + "\n" + dayClause
+		} else {
+			filter = dayClause
+		}
+	}
+
+	// Resolve the layers again with the project override block, then flags.
+	// A project switch resolves them for the new project.
+	projectSettings := func(project string, prefs config.Preferences) (config.Config, error) {
+		projectCfg, err := config.Resolve(fileCfg, prefs, project, os.LookupEnv)
+		if opts.Timezone != "" {
+			projectCfg.Timezone = opts.Timezone
+		}
+		return projectCfg, err
+	}
+	projectCfg, err := projectSettings(projectID, prefs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+	if err := ui.ApplyColorOverrides(projectCfg.Colors); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\ncolors: %v\n", err)
+		os.Exit(2)
+	}
 
 	// Create and run the app
 	app := ui.NewApp(&appState)
 	app.SetVimMode(cfg.VimMode)
+	app.SetTimezoneMode(projectCfg.Timezone)
+	app.SetLogOrder(projectCfg.LogOrder)
+	app.SetTimelineBucket(time.Duration(projectCfg.TimelineBucketSeconds) * time.Second)
+	app.SetQueryCacheLimits(time.Duration(projectCfg.QueryCacheTTLSeconds)*time.Second, projectCfg.QueryCacheMax)
+	app.SetFacetFields(cfg.FacetFields)
+	app.SetSeverityInference(cfg.InferSeverity)
+	if err := app.SetRowTemplates(cfg.RowTemplates, cfg.RowTemplate); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\nrowTemplates: %v\n", err)
+		os.Exit(2)
+	}
+	app.SetPreferences(prefs)
+	app.SetPreferencesPersistFn(config.SavePreferences)
+	app.SetProjectSettingsFn(projectSettings)
+	if restoreSession {
+		app.RestoreSessionView(*state.Session)
+	}
+	columnSets, err := config.LoadColumnSets()
+	if err == nil {
+		app.SetColumnSets(columnSets)
+	}
+	app.SetColumnSetsPersistFn(config.SaveColumnSets)
 	historyStore, err := config.LoadQueryHistory()
 	if err == nil {
 		historyFilters := make([]string, 0, len(historyStore.Queries))
@@ -92,8 +186,7 @@ func main() {
 		historyStore = config.AddQueryToHistory(historyStore, filter, project, cfg.MaxHistoryEntries)
 		return config.SaveQueryHistory(historyStore)
 	})
-	libraryStore, err := config.LoadQueryLibrary()
-	if err == nil {
+	if libraryErr == nil {
 		app.SetQueryLibrary(libraryStore.Queries)
 	}
 	app.SetQueryLibraryPersistFn(func(entries []config.SavedQueryRecord) error {
@@ -110,18 +203,23 @@ func main() {
 	})
 
 	// Set up query executor that uses gcloud CLI
-	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
-		queryCtx, queryCancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer queryCancel()
-		project := strings.TrimSpace(appState.CurrentProject)
+	// The project is passed per query so background tabs keep their own project.
+	app.SetProjectQueryExecutor(func(project, filter string) ([]models.LogEntry, error) {
+		project = strings.TrimSpace(project)
 		if project == "" {
 			project = projectID
 		}
-		executor := query.NewExecutor(nil, project, 30*time.Second)
+		execCfg, _ := config.Resolve(fileCfg, prefs, project, os.LookupEnv)
+		timeout := time.Duration(execCfg.TimeoutSeconds) * time.Second
+		queryCtx, queryCancel := context.WithTimeout(context.Background(), timeout)
+		defer queryCancel()
+		executor := query.NewExecutor(nil, project, timeout)
+		executor.SetMessageRules(execCfg.MessageRules)
+		executor.SetSeverityInference(execCfg.InferSeverity, execCfg.SeverityFields)
 
 		req := query.ExecuteRequest{
 			Filter:   filter,
-			PageSize: 100,
+			PageSize: execCfg.PageSize,
 			OrderBy:  "timestamp desc",
 		}
 		resp, err := executor.ExecuteUsingGcloud(queryCtx, req)
@@ -158,6 +256,15 @@ func main() {
 		fmt.Printf("Error running app: %v\n", err)
 		os.Exit(1)
 	}
+
+	// Persist the working context for the next launch.
+	session := app.SnapshotSession()
+	state.Session = &session
+	state.LastQuery = session.Query
+	state.CurrentProject = appState.CurrentProject
+	if err := config.SaveState(state); err != nil {
+		log.Printf("Warning: Failed to save session: %v", err)
+	}
 }
 
 // getGcloudProject reads the default project from gcloud config
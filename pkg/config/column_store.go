@@ -0,0 +1,52 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// ColumnSets stores log list column sets saved per project.
+type ColumnSets struct {
+	Projects map[string][]models.Column `json:"projects"`
+}
+
+// LoadColumnSets loads saved column sets from disk.
+func LoadColumnSets() (ColumnSets, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return ColumnSets{}, err
+	}
+	path := filepath.Join(configDir, "columns.json")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return ColumnSets{Projects: map[string][]models.Column{}}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ColumnSets{}, err
+	}
+	var sets ColumnSets
+	if err := json.Unmarshal(data, &sets); err != nil {
+		return ColumnSets{}, err
+	}
+	if sets.Projects == nil {
+		sets.Projects = map[string][]models.Column{}
+	}
+	return sets, nil
+}
+
+// SaveColumnSets saves column sets to disk.
+func SaveColumnSets(sets ColumnSets) error {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(configDir, "columns.json")
+	data, err := json.MarshalIndent(sets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func TestLoadSaveColumnSets(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldXDG := os.Getenv("XDG_CONFIG_HOME")
+	defer os.Setenv("XDG_CONFIG_HOME", oldXDG)
+	os.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	sets, err := LoadColumnSets()
+	if err != nil {
+		t.Fatalf("LoadColumnSets failed: %v", err)
+	}
+	if len(sets.Projects) != 0 {
+		t.Fatalf("expected no column sets")
+	}
+
+	sets.Projects["p1"] = []models.Column{{Field: "httpRequest.status", Width: 6, Align: "right"}}
+	if err := SaveColumnSets(sets); err != nil {
+		t.Fatalf("SaveColumnSets failed: %v", err)
+	}
+	loaded, err := LoadColumnSets()
+	if err != nil {
+		t.Fatalf("LoadColumnSets after save failed: %v", err)
+	}
+	if cols := loaded.Projects["p1"]; len(cols) != 1 || cols[0].Field != "httpRequest.status" || cols[0].Align != "right" {
+		t.Fatalf("unexpected loaded column sets: %+v", loaded.Projects)
+	}
+}
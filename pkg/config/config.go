@@ -5,28 +5,58 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/user/log-explorer-tui/pkg/models"
 )
 
 // Config represents application configuration
 type Config struct {
-	InitialBatchSize   int           `json:"initialBatchSize"`
-	LoadChunkSize      int           `json:"loadChunkSize"`
-	StreamRefreshMs    int           `json:"streamRefreshMs"`
-	MaxHistoryEntries  int           `json:"maxHistoryEntries"`
-	TimeoutSeconds     int           `json:"timeoutSeconds"`
-	VimMode            bool          `json:"vimMode"`
-	DefaultProject     string        `json:"defaultProject,omitempty"`
+	SchemaVersion         int                      `json:"schemaVersion"`
+	InitialBatchSize      int                      `json:"initialBatchSize"`
+	LoadChunkSize         int                      `json:"loadChunkSize"`
+	StreamRefreshMs       int                      `json:"streamRefreshMs"`
+	MaxHistoryEntries     int                      `json:"maxHistoryEntries"`
+	TimeoutSeconds        int                      `json:"timeoutSeconds"`
+	VimMode               bool                     `json:"vimMode"`
+	DefaultProject        string                   `json:"defaultProject,omitempty"`
+	PageSize              int                      `json:"pageSize"`
+	QueryCacheTTLSeconds  int                      `json:"queryCacheTtlSeconds"`
+	QueryCacheMax         int                      `json:"queryCacheMax"`
+	Timezone              string                   `json:"timezone"`
+	LogOrder              string                   `json:"logOrder"`
+	TimelineBucketSeconds int                      `json:"timelineBucketSeconds"` // 0 sizes buckets to the time range
+	Colors                map[string]string        `json:"colors,omitempty"`
+	FacetFields           []string                 `json:"facetFields,omitempty"`
+	RowTemplates          []RowTemplate            `json:"rowTemplates,omitempty"`
+	RowTemplate           string                   `json:"rowTemplate,omitempty"`
+	MessageRules          []models.MessageRule     `json:"messageRules,omitempty"`
+	InferSeverity         bool                     `json:"inferSeverity"`
+	SeverityFields        []string                 `json:"severityFields,omitempty"`
+	Projects              map[string]ProjectConfig `json:"projects,omitempty"`
+}
+
+// RowTemplate is a named text/template format for log list rows
+type RowTemplate struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
 }
 
 // DefaultConfig returns default configuration values
 func DefaultConfig() Config {
 	return Config{
-		InitialBatchSize:   100,
-		LoadChunkSize:      50,
-		StreamRefreshMs:    2000,
-		MaxHistoryEntries:  50,
-		TimeoutSeconds:     30,
-		VimMode:            true,
+		SchemaVersion:         CurrentSchemaVersion,
+		InitialBatchSize:      100,
+		LoadChunkSize:         50,
+		StreamRefreshMs:       2000,
+		MaxHistoryEntries:     50,
+		TimeoutSeconds:        30,
+		VimMode:               true,
+		PageSize:              100,
+		QueryCacheTTLSeconds:  900,
+		QueryCacheMax:         40,
+		Timezone:              "utc",
+		LogOrder:              "latest_bottom",
+		TimelineBucketSeconds: 0,
 	}
 }
 
@@ -54,7 +84,9 @@ func GetConfigDir() (string, error) {
 	return configDir, nil
 }
 
-// LoadConfig loads configuration from disk, returns default if file doesn't exist
+// LoadConfig loads configuration from disk, returns default if file doesn't exist.
+// Keys missing from the file keep their default values, and files written with
+// an older schema are migrated in place after a backup copy is made.
 func LoadConfig() (Config, error) {
 	configDir, err := GetConfigDir()
 	if err != nil {
@@ -73,11 +105,20 @@ func LoadConfig() (Config, error) {
 		return DefaultConfig(), err
 	}
 	
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	cfg, version, err := decodeConfig(data)
+	if err != nil {
 		return DefaultConfig(), err
 	}
 	
+	if version < CurrentSchemaVersion {
+		if err := backupConfigFile(configPath, data, version); err != nil {
+			return cfg, err
+		}
+		if err := SaveConfig(cfg); err != nil {
+			return cfg, err
+		}
+	}
+	
 	return cfg, nil
 }
 
@@ -103,6 +144,24 @@ type State struct {
 	CurrentProject string    `json:"currentProject,omitempty"`
 	LastQuery      string    `json:"lastQuery,omitempty"`
 	LastUpdated    time.Time `json:"lastUpdated"`
+	Session        *Session  `json:"session,omitempty"`
+}
+
+// Session captures the working context saved on quit and restored on launch.
+// Relative time presets are stored by key and recomputed from the launch time;
+// TimeStart and TimeEnd are only meaningful for the "custom" preset.
+type Session struct {
+	Query          string    `json:"query,omitempty"`
+	TimePreset     string    `json:"timePreset,omitempty"`
+	TimeStart      time.Time `json:"timeStart,omitempty"`
+	TimeEnd        time.Time `json:"timeEnd,omitempty"`
+	SeverityMode   string    `json:"severityMode,omitempty"`
+	SeverityLevels []string  `json:"severityLevels,omitempty"`
+	SeverityMin    string    `json:"severityMin,omitempty"`
+	DetailMode     string    `json:"detailMode,omitempty"`
+	DetailOpen     bool      `json:"detailOpen,omitempty"`
+	ScrollOffset   int       `json:"scrollOffset"`
+	SelectedKey    string    `json:"selectedKey,omitempty"`
 }
 
 // LoadState loads state from disk
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -20,6 +21,13 @@ func TestDefaultConfig(t *testing.T) {
 		{"MaxHistoryEntries", 50, cfg.MaxHistoryEntries},
 		{"TimeoutSeconds", 30, cfg.TimeoutSeconds},
 		{"VimMode", true, cfg.VimMode},
+		{"SchemaVersion", CurrentSchemaVersion, cfg.SchemaVersion},
+		{"PageSize", 100, cfg.PageSize},
+		{"QueryCacheTTLSeconds", 900, cfg.QueryCacheTTLSeconds},
+		{"QueryCacheMax", 40, cfg.QueryCacheMax},
+		{"Timezone", "utc", cfg.Timezone},
+		{"LogOrder", "latest_bottom", cfg.LogOrder},
+		{"TimelineBucketSeconds", 0, cfg.TimelineBucketSeconds},
 	}
 
 	for _, tt := range tests {
@@ -84,7 +92,7 @@ func TestLoadAndSaveConfig(t *testing.T) {
 	}
 
 	defaultCfg := DefaultConfig()
-	if cfg != defaultCfg {
+	if !reflect.DeepEqual(cfg, defaultCfg) {
 		t.Errorf("Loaded config doesn't match default")
 	}
 
@@ -148,6 +156,41 @@ func TestLoadAndSaveState(t *testing.T) {
 	}
 }
 
+func TestLoadAndSaveStateSession(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldXDG := os.Getenv("XDG_CONFIG_HOME")
+	defer os.Setenv("XDG_CONFIG_HOME", oldXDG)
+	os.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	state := State{
+		CurrentProject: "my-project",
+		Session: &Session{
+			Query:          "severity>=ERROR",
+			TimePreset:     "1h",
+			SeverityMode:   "individual",
+			SeverityLevels: []string{"ERROR"},
+			DetailMode:     "full",
+			DetailOpen:     true,
+			ScrollOffset:   12,
+			SelectedKey:    "k",
+		},
+	}
+	if err := SaveState(state); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	loaded, err := LoadState()
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if loaded.Session == nil {
+		t.Fatal("Expected session to be restored")
+	}
+	if !reflect.DeepEqual(*loaded.Session, *state.Session) {
+		t.Errorf("Session mismatch: got %+v, want %+v", *loaded.Session, *state.Session)
+	}
+}
+
 func TestAddQueryToHistory(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -0,0 +1,316 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// CurrentSchemaVersion is the config.json schema written by this build.
+// Files without a schemaVersion key are treated as version 1.
+const CurrentSchemaVersion = 3
+
+// EnvPrefix is the prefix for environment variable overrides.
+const EnvPrefix = "LOG_EXPLORER_"
+
+// ProjectConfig overrides selected settings for a single project.
+// Zero values inherit the global setting.
+type ProjectConfig struct {
+	PageSize              int               `json:"pageSize,omitempty"`
+	TimeoutSeconds        int               `json:"timeoutSeconds,omitempty"`
+	QueryCacheTTLSeconds  int               `json:"queryCacheTtlSeconds,omitempty"`
+	QueryCacheMax         int               `json:"queryCacheMax,omitempty"`
+	Timezone              string            `json:"timezone,omitempty"`
+	LogOrder              string            `json:"logOrder,omitempty"`
+	TimelineBucketSeconds int               `json:"timelineBucketSeconds,omitempty"`
+	Colors                map[string]string `json:"colors,omitempty"`
+}
+
+// configMigrations upgrade a raw config document from version i+1 to i+2.
+var configMigrations = []func(raw map[string]json.RawMessage){
+	migrateConfigV1,
+	migrateConfigV2,
+}
+
+// migrateConfigV1 drops zero-valued numeric settings. Version 1 loaders did not
+// apply defaults, so a zero usually meant "missing" rather than a deliberate value.
+func migrateConfigV1(raw map[string]json.RawMessage) {
+	for _, key := range []string{"initialBatchSize", "loadChunkSize", "streamRefreshMs", "maxHistoryEntries", "timeoutSeconds"} {
+		if value, ok := raw[key]; ok && strings.TrimSpace(string(value)) == "0" {
+			delete(raw, key)
+		}
+	}
+}
+
+// migrateConfigV2 drops a timelineBucketSeconds of 300. Version 2 wrote the
+// old five minute default into every file, which would now pin the timeline
+// to fixed buckets instead of sizing them to the time range.
+func migrateConfigV2(raw map[string]json.RawMessage) {
+	if value, ok := raw["timelineBucketSeconds"]; ok && strings.TrimSpace(string(value)) == "300" {
+		delete(raw, "timelineBucketSeconds")
+	}
+}
+
+// decodeConfig decodes config.json on top of the defaults, migrating older
+// schemas. It returns the schema version the data was written with.
+func decodeConfig(data []byte) (Config, int, error) {
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return DefaultConfig(), 0, err
+	}
+
+	version := 1
+	if value, ok := raw["schemaVersion"]; ok {
+		if err := json.Unmarshal(value, &version); err != nil {
+			return DefaultConfig(), 0, fmt.Errorf("schemaVersion: %w", err)
+		}
+	}
+	if version < 1 || version > CurrentSchemaVersion {
+		return DefaultConfig(), version, fmt.Errorf("config.json schema version %d is not supported (expected 1-%d)", version, CurrentSchemaVersion)
+	}
+	for v := version; v < CurrentSchemaVersion; v++ {
+		configMigrations[v-1](raw)
+	}
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return DefaultConfig(), version, err
+	}
+	cfg := DefaultConfig()
+	if err := json.Unmarshal(migrated, &cfg); err != nil {
+		return DefaultConfig(), version, err
+	}
+	cfg.SchemaVersion = CurrentSchemaVersion
+	return cfg, version, nil
+}
+
+// backupConfigFile keeps a copy of a config file before it is migrated.
+func backupConfigFile(path string, data []byte, version int) error {
+	return os.WriteFile(fmt.Sprintf("%s.v%d.bak", path, version), data, 0600)
+}
+
+// ApplyEnv overlays LOG_EXPLORER_* environment variables onto cfg.
+// lookup is usually os.LookupEnv.
+func ApplyEnv(cfg Config, lookup func(string) (string, bool)) (Config, error) {
+	ints := map[string]*int{
+		"INITIAL_BATCH_SIZE":      &cfg.InitialBatchSize,
+		"LOAD_CHUNK_SIZE":         &cfg.LoadChunkSize,
+		"STREAM_REFRESH_MS":       &cfg.StreamRefreshMs,
+		"MAX_HISTORY_ENTRIES":     &cfg.MaxHistoryEntries,
+		"TIMEOUT_SECONDS":         &cfg.TimeoutSeconds,
+		"PAGE_SIZE":               &cfg.PageSize,
+		"QUERY_CACHE_TTL_SECONDS": &cfg.QueryCacheTTLSeconds,
+		"QUERY_CACHE_MAX":         &cfg.QueryCacheMax,
+		"TIMELINE_BUCKET_SECONDS": &cfg.TimelineBucketSeconds,
+	}
+	strs := map[string]*string{
+		"DEFAULT_PROJECT": &cfg.DefaultProject,
+		"TIMEZONE":        &cfg.Timezone,
+		"LOG_ORDER":       &cfg.LogOrder,
+	}
+
+	var errs []error
+	for _, name := range sortedKeys(ints) {
+		value, ok := lookup(EnvPrefix + name)
+		if !ok || strings.TrimSpace(value) == "" {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: expected an integer, got %q", EnvPrefix, name, value))
+			continue
+		}
+		*ints[name] = n
+	}
+	for _, name := range sortedKeys(strs) {
+		if value, ok := lookup(EnvPrefix + name); ok && strings.TrimSpace(value) != "" {
+			*strs[name] = strings.TrimSpace(value)
+		}
+	}
+	bools := map[string]*bool{
+		"VIM_MODE":       &cfg.VimMode,
+		"INFER_SEVERITY": &cfg.InferSeverity,
+	}
+	for _, name := range sortedKeys(bools) {
+		value, ok := lookup(EnvPrefix + name)
+		if !ok || strings.TrimSpace(value) == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: expected true or false, got %q", EnvPrefix, name, value))
+			continue
+		}
+		*bools[name] = b
+	}
+	return cfg, errors.Join(errs...)
+}
+
+// Resolve layers the settings for project: the config.json values in file
+// with the override block of project, then prefs, then the environment read
+// through lookup. Command-line flags go on top of the result. The returned
+// config is usable even when it fails validation.
+func Resolve(file Config, prefs Preferences, project string, lookup func(string) (string, bool)) (Config, error) {
+	cfg, err := ApplyEnv(prefs.Apply(file.ForProject(project)), lookup)
+	if err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// ForProject returns cfg with the override block for project applied.
+func (c Config) ForProject(project string) Config {
+	override, ok := c.Projects[project]
+	if !ok {
+		return c
+	}
+	if override.PageSize > 0 {
+		c.PageSize = override.PageSize
+	}
+	if override.TimeoutSeconds > 0 {
+		c.TimeoutSeconds = override.TimeoutSeconds
+	}
+	if override.QueryCacheTTLSeconds > 0 {
+		c.QueryCacheTTLSeconds = override.QueryCacheTTLSeconds
+	}
+	if override.QueryCacheMax > 0 {
+		c.QueryCacheMax = override.QueryCacheMax
+	}
+	if override.Timezone != "" {
+		c.Timezone = override.Timezone
+	}
+	if override.LogOrder != "" {
+		c.LogOrder = override.LogOrder
+	}
+	if override.TimelineBucketSeconds > 0 {
+		c.TimelineBucketSeconds = override.TimelineBucketSeconds
+	}
+	if len(override.Colors) > 0 {
+		colors := make(map[string]string, len(c.Colors)+len(override.Colors))
+		for k, v := range c.Colors {
+			colors[k] = v
+		}
+		for k, v := range override.Colors {
+			colors[k] = v
+		}
+		c.Colors = colors
+	}
+	return c
+}
+
+var hexColorRegex = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Validate reports every invalid setting, naming the offending key.
+func (c Config) Validate() error {
+	var errs []error
+	positive := func(name string, value int) {
+		if value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be greater than 0 (got %d)", name, value))
+		}
+	}
+	positive("initialBatchSize", c.InitialBatchSize)
+	positive("loadChunkSize", c.LoadChunkSize)
+	positive("streamRefreshMs", c.StreamRefreshMs)
+	positive("maxHistoryEntries", c.MaxHistoryEntries)
+	positive("timeoutSeconds", c.TimeoutSeconds)
+	if c.PageSize < 1 || c.PageSize > 1000 {
+		errs = append(errs, fmt.Errorf("pageSize must be between 1 and 1000 (got %d)", c.PageSize))
+	}
+	if c.QueryCacheTTLSeconds < 0 {
+		errs = append(errs, fmt.Errorf("queryCacheTtlSeconds must not be negative (got %d)", c.QueryCacheTTLSeconds))
+	}
+	if c.QueryCacheMax < 0 {
+		errs = append(errs, fmt.Errorf("queryCacheMax must not be negative (got %d)", c.QueryCacheMax))
+	}
+	if c.TimelineBucketSeconds < 0 {
+		errs = append(errs, fmt.Errorf("timelineBucketSeconds must not be negative (got %d)", c.TimelineBucketSeconds))
+	}
+	errs = append(errs, validateChoices("", c.Timezone, c.LogOrder, c.Colors)...)
+	for i, field := range c.SeverityFields {
+		if strings.TrimSpace(field) == "" {
+			errs = append(errs, fmt.Errorf("severityFields[%d] must be a jsonPayload field like level", i))
+		}
+	}
+	for i, rule := range c.MessageRules {
+		if len(models.SplitFieldPath(rule.Field)) == 0 {
+			errs = append(errs, fmt.Errorf("messageRules[%d].field must be a field path like jsonPayload.msg (got %q)", i, rule.Field))
+		}
+	}
+	seen := make(map[string]bool, len(c.RowTemplates))
+	for i, tmpl := range c.RowTemplates {
+		switch {
+		case strings.TrimSpace(tmpl.Name) == "":
+			errs = append(errs, fmt.Errorf("rowTemplates[%d].name must not be empty", i))
+		case seen[tmpl.Name]:
+			errs = append(errs, fmt.Errorf("rowTemplates[%d].name %q is used twice", i, tmpl.Name))
+		}
+		seen[tmpl.Name] = true
+		if strings.TrimSpace(tmpl.Format) == "" {
+			errs = append(errs, fmt.Errorf("rowTemplates[%d].format must not be empty", i))
+		}
+	}
+
+	for _, project := range sortedKeys(c.Projects) {
+		override := c.Projects[project]
+		prefix := fmt.Sprintf("projects[%q].", project)
+		if override.PageSize < 0 || override.PageSize > 1000 {
+			errs = append(errs, fmt.Errorf("%spageSize must be between 1 and 1000 (got %d)", prefix, override.PageSize))
+		}
+		for name, value := range map[string]int{
+			"timeoutSeconds":        override.TimeoutSeconds,
+			"queryCacheTtlSeconds":  override.QueryCacheTTLSeconds,
+			"queryCacheMax":         override.QueryCacheMax,
+			"timelineBucketSeconds": override.TimelineBucketSeconds,
+		} {
+			if value < 0 {
+				errs = append(errs, fmt.Errorf("%s%s must not be negative (got %d)", prefix, name, value))
+			}
+		}
+		errs = append(errs, validateChoices(prefix, override.Timezone, override.LogOrder, override.Colors)...)
+	}
+	return errors.Join(errs...)
+}
+
+func validateChoices(prefix, timezone, logOrder string, colors map[string]string) []error {
+	var errs []error
+	switch timezone {
+	case "", "utc", "local":
+	default:
+		errs = append(errs, fmt.Errorf("%stimezone must be \"utc\" or \"local\" (got %q)", prefix, timezone))
+	}
+	switch logOrder {
+	case "", "latest_top", "latest_bottom":
+	default:
+		errs = append(errs, fmt.Errorf("%slogOrder must be \"latest_top\" or \"latest_bottom\" (got %q)", prefix, logOrder))
+	}
+	for _, name := range sortedKeys(colors) {
+		if !isValidColor(colors[name]) {
+			errs = append(errs, fmt.Errorf("%scolors.%s must be a hex color like #1a73e8 or an ANSI code 0-255 (got %q)", prefix, name, colors[name]))
+		}
+	}
+	return errs
+}
+
+func isValidColor(value string) bool {
+	if hexColorRegex.MatchString(value) {
+		return true
+	}
+	n, err := strconv.Atoi(value)
+	return err == nil && n >= 0 && n <= 255
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
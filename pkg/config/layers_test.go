@@ -0,0 +1,227 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func TestLoadConfigMissingKeysUseDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldXDG := os.Getenv("XDG_CONFIG_HOME")
+	defer os.Setenv("XDG_CONFIG_HOME", oldXDG)
+	os.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	dir, _ := GetConfigDir()
+	data := `{"schemaVersion": 3, "vimMode": false, "pageSize": 250}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(data), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.VimMode || cfg.PageSize != 250 {
+		t.Errorf("expected file values to be kept, got %+v", cfg)
+	}
+	if cfg.TimeoutSeconds != 30 || cfg.QueryCacheMax != 40 || cfg.Timezone != "utc" {
+		t.Errorf("expected defaults for missing keys, got %+v", cfg)
+	}
+}
+
+func TestLoadConfigMigratesV1(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldXDG := os.Getenv("XDG_CONFIG_HOME")
+	defer os.Setenv("XDG_CONFIG_HOME", oldXDG)
+	os.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	dir, _ := GetConfigDir()
+	path := filepath.Join(dir, "config.json")
+	v1 := `{"initialBatchSize": 200, "loadChunkSize": 0, "streamRefreshMs": 2000, "maxHistoryEntries": 0, "timeoutSeconds": 10, "vimMode": false}`
+	if err := os.WriteFile(path, []byte(v1), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("expected schema version %d, got %d", CurrentSchemaVersion, cfg.SchemaVersion)
+	}
+	if cfg.InitialBatchSize != 200 || cfg.TimeoutSeconds != 10 || cfg.VimMode {
+		t.Errorf("expected v1 values to be kept, got %+v", cfg)
+	}
+	if cfg.LoadChunkSize != 50 || cfg.MaxHistoryEntries != 50 {
+		t.Errorf("expected zero values to migrate to defaults, got %+v", cfg)
+	}
+
+	backup, err := os.ReadFile(path + ".v1.bak")
+	if err != nil || string(backup) != v1 {
+		t.Errorf("expected backup of the v1 file, got %q (%v)", backup, err)
+	}
+	rewritten, _ := os.ReadFile(path)
+	if !strings.Contains(string(rewritten), `"schemaVersion": 3`) {
+		t.Errorf("expected migrated file to be rewritten, got %s", rewritten)
+	}
+}
+
+func TestDecodeConfigMigratesV2TimelineBucket(t *testing.T) {
+	cfg, version, err := decodeConfig([]byte(`{"schemaVersion": 2, "timelineBucketSeconds": 300}`))
+	if err != nil || version != 2 {
+		t.Fatalf("decodeConfig failed: %v (version %d)", err, version)
+	}
+	if cfg.TimelineBucketSeconds != 0 {
+		t.Errorf("expected the old default to migrate to adaptive buckets, got %d", cfg.TimelineBucketSeconds)
+	}
+
+	cfg, _, err = decodeConfig([]byte(`{"schemaVersion": 2, "timelineBucketSeconds": 60}`))
+	if err != nil || cfg.TimelineBucketSeconds != 60 {
+		t.Errorf("expected a chosen bucket size to be kept, got %d (%v)", cfg.TimelineBucketSeconds, err)
+	}
+	cfg, _, err = decodeConfig([]byte(`{"schemaVersion": 3, "timelineBucketSeconds": 300}`))
+	if err != nil || cfg.TimelineBucketSeconds != 300 {
+		t.Errorf("expected a version 3 bucket size to be kept, got %d (%v)", cfg.TimelineBucketSeconds, err)
+	}
+}
+
+func TestLoadConfigRejectsNewerSchema(t *testing.T) {
+	if _, _, err := decodeConfig([]byte(`{"schemaVersion": 99}`)); err == nil {
+		t.Error("expected error for unsupported schema version")
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"LOG_EXPLORER_PAGE_SIZE":      "500",
+		"LOG_EXPLORER_TIMEZONE":       "local",
+		"LOG_EXPLORER_VIM_MODE":       "false",
+		"LOG_EXPLORER_INFER_SEVERITY": "true",
+	}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	cfg, err := ApplyEnv(DefaultConfig(), lookup)
+	if err != nil {
+		t.Fatalf("ApplyEnv failed: %v", err)
+	}
+	if cfg.PageSize != 500 || cfg.Timezone != "local" || cfg.VimMode || !cfg.InferSeverity {
+		t.Errorf("expected env overrides, got %+v", cfg)
+	}
+
+	env["LOG_EXPLORER_QUERY_CACHE_MAX"] = "lots"
+	if _, err := ApplyEnv(DefaultConfig(), lookup); err == nil || !strings.Contains(err.Error(), "LOG_EXPLORER_QUERY_CACHE_MAX") {
+		t.Errorf("expected error naming the variable, got %v", err)
+	}
+}
+
+func TestForProject(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Colors = map[string]string{"primary": "33", "error": "203"}
+	cfg.Projects = map[string]ProjectConfig{
+		"prod": {PageSize: 50, Timezone: "local", Colors: map[string]string{"primary": "#ff0000"}},
+	}
+
+	prod := cfg.ForProject("prod")
+	if prod.PageSize != 50 || prod.Timezone != "local" || prod.QueryCacheMax != 40 {
+		t.Errorf("unexpected project config %+v", prod)
+	}
+	if prod.Colors["primary"] != "#ff0000" || prod.Colors["error"] != "203" {
+		t.Errorf("expected merged colors, got %v", prod.Colors)
+	}
+	if cfg.Colors["primary"] != "33" {
+		t.Error("ForProject must not mutate the global colors")
+	}
+	if other := cfg.ForProject("dev"); other.PageSize != 100 {
+		t.Errorf("expected global page size for unknown project, got %d", other.PageSize)
+	}
+}
+
+func TestResolveLayersProjectUnderPreferencesAndEnv(t *testing.T) {
+	file := DefaultConfig()
+	file.Projects = map[string]ProjectConfig{
+		"prod": {PageSize: 50, Timezone: "local", LogOrder: "latest_top"},
+	}
+	env := map[string]string{EnvPrefix + "PAGE_SIZE": "500"}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	cfg, err := Resolve(file, Preferences{LogOrder: "latest_bottom"}, "prod", lookup)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if cfg.Timezone != "local" {
+		t.Errorf("expected the project timezone over config.json, got %q", cfg.Timezone)
+	}
+	if cfg.LogOrder != "latest_bottom" {
+		t.Errorf("expected the saved preference over the project log order, got %q", cfg.LogOrder)
+	}
+	if cfg.PageSize != 500 {
+		t.Errorf("expected the environment over the project page size, got %d", cfg.PageSize)
+	}
+
+	env[EnvPrefix+"TIMEZONE"] = "utc"
+	if cfg, _ := Resolve(file, Preferences{}, "prod", lookup); cfg.Timezone != "utc" {
+		t.Errorf("expected the environment over the project timezone, got %q", cfg.Timezone)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Fatalf("default config should be valid: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.PageSize = 0
+	cfg.Timezone = "mars"
+	cfg.Colors = map[string]string{"primary": "blue"}
+	cfg.Projects = map[string]ProjectConfig{"prod": {LogOrder: "sideways"}}
+	cfg.MessageRules = []models.MessageRule{{Field: "jsonPayload.msg"}, {Field: "jsonPayload..x"}}
+	cfg.RowTemplates = []RowTemplate{{Name: "svc", Format: "{{.Message}}"}, {Name: "svc", Format: ""}}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{"pageSize", "timezone", "colors.primary", `projects["prod"].logOrder`, `rowTemplates[1].name "svc"`, "rowTemplates[1].format", "messageRules[1].field"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %s, got: %v", want, err)
+		}
+	}
+}
+
+func TestLoadSavePreferences(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldXDG := os.Getenv("XDG_CONFIG_HOME")
+	defer os.Setenv("XDG_CONFIG_HOME", oldXDG)
+	os.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	prefs, err := LoadPreferences()
+	if err != nil {
+		t.Fatalf("LoadPreferences failed: %v", err)
+	}
+	if cfg := prefs.Apply(DefaultConfig()); cfg.Timezone != "utc" || !cfg.VimMode {
+		t.Errorf("empty preferences should not change config, got %+v", cfg)
+	}
+
+	vim := false
+	if err := SavePreferences(Preferences{VimMode: &vim, Timezone: "local", LogOrder: "latest_top", FacetFields: []string{"jsonPayload.user"}, RowTemplate: "svc"}); err != nil {
+		t.Fatalf("SavePreferences failed: %v", err)
+	}
+	loaded, err := LoadPreferences()
+	if err != nil {
+		t.Fatalf("LoadPreferences after save failed: %v", err)
+	}
+	cfg := loaded.Apply(DefaultConfig())
+	if cfg.VimMode || cfg.Timezone != "local" || cfg.LogOrder != "latest_top" || len(cfg.FacetFields) != 1 || cfg.RowTemplate != "svc" {
+		t.Errorf("unexpected config after preferences %+v", cfg)
+	}
+}
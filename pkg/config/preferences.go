@@ -0,0 +1,74 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Preferences stores UI toggles changed at runtime (F6/F7/F8) so they survive
+// restarts. Unset fields fall back to config.json.
+type Preferences struct {
+	VimMode  *bool  `json:"vimMode,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+	LogOrder string `json:"logOrder,omitempty"`
+	// FacetFields are the jsonPayload fields pinned in the facet sidebar
+	FacetFields []string `json:"facetFields,omitempty"`
+	// RowTemplate is the row template last selected with v
+	RowTemplate string `json:"rowTemplate,omitempty"`
+}
+
+// Apply overlays the stored preferences onto cfg.
+func (p Preferences) Apply(cfg Config) Config {
+	if p.VimMode != nil {
+		cfg.VimMode = *p.VimMode
+	}
+	if p.Timezone != "" {
+		cfg.Timezone = p.Timezone
+	}
+	if p.LogOrder != "" {
+		cfg.LogOrder = p.LogOrder
+	}
+	if len(p.FacetFields) > 0 {
+		cfg.FacetFields = p.FacetFields
+	}
+	if p.RowTemplate != "" {
+		cfg.RowTemplate = p.RowTemplate
+	}
+	return cfg
+}
+
+// LoadPreferences loads UI preferences from disk.
+func LoadPreferences() (Preferences, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return Preferences{}, err
+	}
+	path := filepath.Join(configDir, "preferences.json")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return Preferences{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Preferences{}, err
+	}
+	var prefs Preferences
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		return Preferences{}, err
+	}
+	return prefs, nil
+}
+
+// SavePreferences saves UI preferences to disk.
+func SavePreferences(prefs Preferences) error {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(configDir, "preferences.json")
+	data, err := json.MarshalIndent(prefs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
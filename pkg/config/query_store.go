@@ -18,6 +18,8 @@ type SavedQueryRecord struct {
 	Project   string    `json:"project,omitempty"`
 	UpdatedAt time.Time `json:"updatedAt"`
 	UseCount  int       `json:"useCount"`
+	// Columns is the log list column set saved with the query, if any
+	Columns []models.Column `json:"columns,omitempty"`
 }
 
 // QueryLibrary stores saved queries.
@@ -77,7 +79,8 @@ func SaveQueryLibrary(lib QueryLibrary) error {
 	return os.WriteFile(path, data, 0o600)
 }
 
-// UpsertSavedQuery inserts or updates a saved query.
+// UpsertSavedQuery inserts or updates a saved query. An update without
+// columns keeps the columns of the record it replaces.
 func UpsertSavedQuery(lib QueryLibrary, record SavedQueryRecord, maxEntries int) QueryLibrary {
 	record.Name = strings.TrimSpace(record.Name)
 	record.Filter = strings.TrimSpace(record.Filter)
@@ -96,6 +99,9 @@ func UpsertSavedQuery(lib QueryLibrary, record SavedQueryRecord, maxEntries int)
 		q := lib.Queries[i]
 		if q.Name == record.Name || q.Filter == record.Filter {
 			record.UseCount = q.UseCount + 1
+			if len(record.Columns) == 0 {
+				record.Columns = q.Columns
+			}
 			lib.Queries[i] = record
 			sort.SliceStable(lib.Queries, func(a, b int) bool {
 				return lib.Queries[a].UpdatedAt.After(lib.Queries[b].UpdatedAt)
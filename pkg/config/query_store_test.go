@@ -36,6 +36,23 @@ func TestLoadSaveQueryLibrary(t *testing.T) {
 	}
 }
 
+func TestUpsertSavedQueryKeepsColumns(t *testing.T) {
+	columns := []models.Column{{Field: "httpRequest.status", Width: 6, Align: "right"}}
+	lib := UpsertSavedQuery(QueryLibrary{}, SavedQueryRecord{Name: "Errors", Filter: "severity=ERROR", Columns: columns}, 50)
+	lib = UpsertSavedQuery(lib, SavedQueryRecord{Name: "Errors", Filter: "severity>=ERROR"}, 50)
+	if len(lib.Queries) != 1 || lib.Queries[0].Filter != "severity>=ERROR" || lib.Queries[0].UseCount != 2 {
+		t.Fatalf("unexpected library after re-save: %+v", lib.Queries)
+	}
+	if len(lib.Queries[0].Columns) != 1 || lib.Queries[0].Columns[0].Field != "httpRequest.status" {
+		t.Errorf("expected re-save to keep the columns, got %+v", lib.Queries[0].Columns)
+	}
+
+	lib = UpsertSavedQuery(lib, SavedQueryRecord{Name: "Errors", Filter: "severity>=ERROR", Columns: []models.Column{{Field: "severity"}}}, 50)
+	if len(lib.Queries[0].Columns) != 1 || lib.Queries[0].Columns[0].Field != "severity" {
+		t.Errorf("expected new columns to replace the old ones, got %+v", lib.Queries[0].Columns)
+	}
+}
+
 func TestLoadSaveQueryResultCache(t *testing.T) {
 	tmpDir := t.TempDir()
 	oldXDG := os.Getenv("XDG_CONFIG_HOME")
@@ -0,0 +1,199 @@
+package models
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldValue resolves a Cloud Logging field path such as
+// jsonPayload.user.id, labels."k8s-pod/app" or resource.labels.cluster_name
+// against the entry. Non-string values are returned in their JSON form.
+func (e LogEntry) FieldValue(path string) (string, bool) {
+	parts := SplitFieldPath(path)
+	if len(parts) == 0 {
+		return "", false
+	}
+	rest := parts[1:]
+	switch parts[0] {
+	case "severity":
+		return e.Severity, len(rest) == 0 && e.Severity != ""
+	case "timestamp":
+		return e.Timestamp.UTC().Format(time.RFC3339Nano), len(rest) == 0 && !e.Timestamp.IsZero()
+	case "logName":
+		return e.LogName, len(rest) == 0 && e.LogName != ""
+	case "insertId":
+		return e.ID, len(rest) == 0 && e.ID != ""
+	case "textPayload":
+		return e.TextPayload, len(rest) == 0 && e.TextPayload != ""
+	case "trace":
+		return e.Trace, len(rest) == 0 && e.Trace != ""
+	case "spanId":
+		return e.SpanID, len(rest) == 0 && e.SpanID != ""
+	case "labels":
+		return mapValue(e.Labels, rest)
+	case "resource":
+		if len(rest) == 1 && rest[0] == "type" {
+			return e.Resource.Type, e.Resource.Type != ""
+		}
+		if len(rest) > 0 && rest[0] == "labels" {
+			return mapValue(e.Resource.Labels, rest[1:])
+		}
+	case "sourceLocation":
+		if e.SourceLocation == nil || len(rest) != 1 {
+			return "", false
+		}
+		switch rest[0] {
+		case "file":
+			return e.SourceLocation.File, e.SourceLocation.File != ""
+		case "line":
+			return strconv.FormatInt(e.SourceLocation.Line, 10), e.SourceLocation.Line != 0
+		case "function":
+			return e.SourceLocation.Function, e.SourceLocation.Function != ""
+		}
+	case "httpRequest":
+		if e.HTTPRequest == nil || len(rest) != 1 {
+			return "", false
+		}
+		return e.HTTPRequest.fieldValue(rest[0])
+	case "jsonPayload":
+		if e.JSONPayload == nil {
+			return "", false
+		}
+		return jsonValue(e.JSONPayload, rest)
+	case "protoPayload":
+		if e.ProtoPayload == nil {
+			return "", false
+		}
+		return jsonValue(e.ProtoPayload, rest)
+	}
+	return "", false
+}
+
+func (r *HTTPRequest) fieldValue(name string) (string, bool) {
+	var value string
+	switch name {
+	case "requestMethod":
+		value = r.RequestMethod
+	case "requestUrl":
+		value = r.RequestURL
+	case "status":
+		if r.Status != 0 {
+			value = strconv.Itoa(r.Status)
+		}
+	case "requestSize":
+		if r.RequestSize != 0 {
+			value = strconv.FormatInt(r.RequestSize, 10)
+		}
+	case "responseSize":
+		if r.ResponseSize != 0 {
+			value = strconv.FormatInt(r.ResponseSize, 10)
+		}
+	case "userAgent":
+		value = r.UserAgent
+	case "remoteIp":
+		value = r.RemoteIP
+	case "serverIp":
+		value = r.ServerIP
+	case "referer":
+		value = r.Referer
+	case "latency":
+		value = r.Latency
+	case "protocol":
+		value = r.Protocol
+	}
+	return value, value != ""
+}
+
+func mapValue(m map[string]string, rest []string) (string, bool) {
+	if len(rest) != 1 {
+		return "", false
+	}
+	v, ok := m[rest[0]]
+	return v, ok
+}
+
+func jsonValue(value interface{}, path []string) (string, bool) {
+	for _, key := range path {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if value, ok = obj[key]; !ok {
+			return "", false
+		}
+	}
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	}
+}
+
+// LogID returns the log ID of a logName such as
+// projects/p/logs/cloudaudit.googleapis.com%2Factivity, unescaped
+func LogID(logName string) string {
+	idx := strings.LastIndex(logName, "/logs/")
+	if idx < 0 {
+		return logName
+	}
+	id := logName[idx+len("/logs/"):]
+	if unescaped, err := url.PathUnescape(id); err == nil {
+		return unescaped
+	}
+	return id
+}
+
+// SplitFieldPath splits a dotted field path. Quoted segments may contain
+// dots and slashes, as in labels."k8s-pod/app".
+func SplitFieldPath(path string) []string {
+	var parts []string
+	var sb strings.Builder
+	quoted := false
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == '\\' && quoted && i+1 < len(path):
+			i++
+			sb.WriteByte(path[i])
+		case c == '.' && !quoted:
+			parts = append(parts, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	parts = append(parts, sb.String())
+	for _, p := range parts {
+		if p == "" {
+			return nil
+		}
+	}
+	return parts
+}
+
+// SeverityRank returns the position of a level in SeverityLevels, or -1
+func SeverityRank(level string) int {
+	level = strings.ToUpper(strings.TrimSpace(level))
+	for i, l := range SeverityLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
@@ -9,11 +9,16 @@ type LogEntry struct {
 	ID        string                 `json:"id"`
 	Timestamp time.Time              `json:"timestamp"`
 	Severity  string                 `json:"severity"`
+	SeverityField string             `json:"severityField,omitempty"` // Payload field Severity was inferred from; empty when logged
 	Message   string                 `json:"message"`
 	JSONPayload map[string]interface{} `json:"jsonPayload,omitempty"`
 	TextPayload string                `json:"textPayload,omitempty"`
+	ProtoPayload map[string]interface{} `json:"protoPayload,omitempty"`
+	MessageField string                `json:"messageField,omitempty"` // Field path Message was taken from; empty for a summary
 	Labels    map[string]string      `json:"labels,omitempty"`
 	Resource  Resource               `json:"resource,omitempty"`
+	LogName   string                 `json:"logName,omitempty"`
+	HTTPRequest *HTTPRequest         `json:"httpRequest,omitempty"`
 	SourceLocation *SourceLocation    `json:"sourceLocation,omitempty"`
 	Trace     string                 `json:"trace,omitempty"`
 	SpanID    string                 `json:"spanId,omitempty"`
@@ -26,6 +31,22 @@ type Resource struct {
 	Labels map[string]string `json:"labels,omitempty"`
 }
 
+// HTTPRequest is the httpRequest field of request logs written by load
+// balancers, Cloud Run, App Engine and instrumented services
+type HTTPRequest struct {
+	RequestMethod string `json:"requestMethod,omitempty"`
+	RequestURL    string `json:"requestUrl,omitempty"`
+	Status        int    `json:"status,omitempty"`
+	RequestSize   int64  `json:"requestSize,omitempty"`
+	ResponseSize  int64  `json:"responseSize,omitempty"`
+	UserAgent     string `json:"userAgent,omitempty"`
+	RemoteIP      string `json:"remoteIp,omitempty"`
+	ServerIP      string `json:"serverIp,omitempty"`
+	Referer       string `json:"referer,omitempty"`
+	Latency       string `json:"latency,omitempty"` // Duration such as "0.153s"
+	Protocol      string `json:"protocol,omitempty"`
+}
+
 // SourceLocation represents where the log originated
 type SourceLocation struct {
 	File     string `json:"file,omitempty"`
@@ -65,8 +86,18 @@ type LogGraphPoint struct {
 type FilterState struct {
 	TimeRange      TimeRange       `json:"timeRange"`
 	Severity       SeverityFilter  `json:"severity"`
-	CustomFilters  map[string]string `json:"customFilters"`
+	CustomFilters  map[string]string `json:"customFilters"` // Field path, optionally with the value (query.CustomFilterKey), to value; a "-" prefix excludes it
 	SearchTerm     string          `json:"searchTerm"`
+	SearchMatch    string          `json:"searchMatch,omitempty"`  // "plain", "insensitive" or "regex"
+	SearchFilter   bool            `json:"searchFilter,omitempty"` // Hide non-matching entries instead of jumping
+	LocalFilters   []LocalFilter   `json:"localFilters,omitempty"` // Applied in order to the loaded logs
+}
+
+// LocalFilter is a client-side refinement of the loaded logs
+type LocalFilter struct {
+	Kind    string `json:"kind"` // "query" (Logging query syntax) or "grep"
+	Expr    string `json:"expr"`
+	Enabled bool   `json:"enabled"`
 }
 
 // PaginationState tracks pagination cursors
@@ -85,6 +116,24 @@ type LogListState struct {
 	IsLoading             bool
 	ErrorMessage          string
 	PaginationState       PaginationState
+	Columns               []Column // Shown between SEV and MESSAGE
+	SortField             string   // Column field the list is sorted by; empty keeps time order
+	SortDesc              bool
+}
+
+// MessageRule takes the list message of an entry from a field path. A rule
+// with ResourceType or LogName only applies to entries that match them.
+type MessageRule struct {
+	Field        string `json:"field"`
+	ResourceType string `json:"resourceType,omitempty"`
+	LogName      string `json:"logName,omitempty"` // Full logName or the log ID after /logs/
+}
+
+// Column is a log list column showing the value of a field path
+type Column struct {
+	Field string `json:"field"`
+	Width int    `json:"width,omitempty"` // Display cells; 0 uses the default
+	Align string `json:"align,omitempty"` // "left" (default) or "right"
 }
 
 // StreamState represents streaming mode state
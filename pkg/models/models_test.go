@@ -240,3 +240,50 @@ func TestSeverityFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestLogEntryFieldValue(t *testing.T) {
+	entry := LogEntry{
+		Severity:    SeverityError,
+		ID:          "id-1",
+		Labels:      map[string]string{"k8s-pod/app": "api"},
+		Resource:    Resource{Type: "k8s_container", Labels: map[string]string{"pod_name": "api-1"}},
+		JSONPayload: map[string]interface{}{"user": map[string]interface{}{"id": "u1", "admin": true}, "count": float64(3)},
+		HTTPRequest: &HTTPRequest{RequestMethod: "GET", Status: 503, Latency: "0.25s"},
+	}
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"severity", "ERROR", true},
+		{"insertId", "id-1", true},
+		{`labels."k8s-pod/app"`, "api", true},
+		{"resource.type", "k8s_container", true},
+		{"resource.labels.pod_name", "api-1", true},
+		{"jsonPayload.user.id", "u1", true},
+		{"jsonPayload.user.admin", "true", true},
+		{"jsonPayload.count", "3", true},
+		{"jsonPayload.user", `{"admin":true,"id":"u1"}`, true},
+		{"jsonPayload.missing", "", false},
+		{"httpRequest.status", "503", true},
+		{"httpRequest.latency", "0.25s", true},
+		{"httpRequest.userAgent", "", false},
+		{"trace", "", false},
+		{"labels..x", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := entry.FieldValue(tt.path)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("FieldValue(%q) = %q, %v; want %q, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSeverityRank(t *testing.T) {
+	if SeverityRank("warning") <= SeverityRank(SeverityInfo) || SeverityRank(SeverityError) <= SeverityRank(SeverityWarning) {
+		t.Error("Expected severities to rank in order")
+	}
+	if SeverityRank("LOUD") != -1 {
+		t.Error("Expected -1 for an unknown severity")
+	}
+}
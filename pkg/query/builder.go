@@ -2,6 +2,9 @@ package query
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -38,6 +41,18 @@ func (qb *Builder) AddSeverity(severityFilter models.SeverityFilter) *Builder {
 	return qb
 }
 
+// AddSeverityOrDefault is AddSeverity that also keeps DEFAULT entries, whose
+// severity is inferred from the payload after loading
+func (qb *Builder) AddSeverityOrDefault(severityFilter models.SeverityFilter) *Builder {
+	before := len(qb.filters)
+	qb.AddSeverity(severityFilter)
+	if len(qb.filters) > before {
+		clause := qb.filters[before]
+		qb.filters[before] = "(" + clause + " OR severity=DEFAULT)"
+	}
+	return qb
+}
+
 // AddTimeRange adds a time range filter
 func (qb *Builder) AddTimeRange(timeRange models.TimeRange) *Builder {
 	if !timeRange.Start.IsZero() && !timeRange.End.IsZero() {
@@ -49,6 +64,16 @@ func (qb *Builder) AddTimeRange(timeRange models.TimeRange) *Builder {
 	return qb
 }
 
+// AddTimeWindow adds the half-open time window [Start, End) to the nanosecond,
+// so windows that meet at an instant don't both match an entry written at it
+func (qb *Builder) AddTimeWindow(timeRange models.TimeRange) *Builder {
+	if !timeRange.Start.IsZero() && !timeRange.End.IsZero() {
+		qb.filters = append(qb.filters, fmt.Sprintf("timestamp>=%q", timeRange.Start.Format(time.RFC3339Nano)))
+		qb.filters = append(qb.filters, fmt.Sprintf("timestamp<%q", timeRange.End.Format(time.RFC3339Nano)))
+	}
+	return qb
+}
+
 // AddCustomFilter adds a custom filter clause
 func (qb *Builder) AddCustomFilter(filter string) *Builder {
 	if filter != "" {
@@ -68,11 +93,147 @@ func (qb *Builder) AddResourceFilter(resourceType string) *Builder {
 // AddLabelFilter filters by label key-value pair
 func (qb *Builder) AddLabelFilter(key, value string) *Builder {
 	if key != "" && value != "" {
-		qb.filters = append(qb.filters, fmt.Sprintf("labels.%s=%q", key, value))
+		qb.filters = append(qb.filters, FieldPath("labels", key)+"="+strconv.Quote(value))
+	}
+	return qb
+}
+
+// AddResourceLabelFilter filters by resource label key-value pair
+func (qb *Builder) AddResourceLabelFilter(key, value string) *Builder {
+	if key != "" && value != "" {
+		qb.filters = append(qb.filters, FieldPath("resource", "labels", key)+"="+strconv.Quote(value))
+	}
+	return qb
+}
+
+// AddResourceLabelMatch filters by a regular expression on a resource label
+func (qb *Builder) AddResourceLabelMatch(key, pattern string) *Builder {
+	if key != "" && pattern != "" {
+		qb.filters = append(qb.filters, FieldPath("resource", "labels", key)+"=~"+strconv.Quote(pattern))
 	}
 	return qb
 }
 
+var bareFieldKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// QuoteFieldKey quotes a field path segment when it is not a plain
+// identifier, as in labels."k8s-pod/app"
+func QuoteFieldKey(key string) string {
+	if bareFieldKey.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// FieldPath joins field path segments, quoting those that need it
+func FieldPath(keys ...string) string {
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = QuoteFieldKey(key)
+	}
+	return strings.Join(quoted, ".")
+}
+
+// FormatFilterValue renders a scalar JSON value for a comparison. Strings
+// are always quoted; numbers and booleans are written as is.
+func FormatFilterValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	}
+	return "", false
+}
+
+// customFilterValueSep separates the path of a CustomFilters key from the
+// value that keeps several values of one field apart
+const customFilterValueSep = "\x00"
+
+// CustomFilterKey returns the CustomFilters key of a value of path. The key
+// carries the value, so a field can hold several included and excluded
+// values at once.
+func CustomFilterKey(path, value string, exclude bool) string {
+	key := path + customFilterValueSep + value
+	if exclude {
+		key = "-" + key
+	}
+	return key
+}
+
+// CustomFilterPath returns the field path of a CustomFilters key and whether
+// the key excludes its value
+func CustomFilterPath(key string) (string, bool) {
+	path, _, _ := strings.Cut(key, customFilterValueSep)
+	return strings.TrimPrefix(path, "-"), strings.HasPrefix(path, "-")
+}
+
+// SortCustomFilterKeys orders CustomFilters keys by field path, includes
+// before excludes, then by value
+func SortCustomFilterKeys(keys []string, filters map[string]string) {
+	sort.Slice(keys, func(i, j int) bool {
+		pi, ei := CustomFilterPath(keys[i])
+		pj, ej := CustomFilterPath(keys[j])
+		if pi != pj {
+			return pi < pj
+		}
+		if ei != ej {
+			return !ei
+		}
+		return filters[keys[i]] < filters[keys[j]]
+	})
+}
+
+// CustomFilterClauses renders FilterState.CustomFilters as query clauses in
+// a stable order. Keys are field paths, optionally with the value as made
+// by CustomFilterKey; a leading "-" excludes the value. The included values
+// of a field are ORed in one clause, and every excluded value gets its own
+// NOT clause.
+func CustomFilterClauses(filters map[string]string) []string {
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	SortCustomFilterKeys(keys, filters)
+	var clauses, included []string
+	includedPath := ""
+	flush := func() {
+		switch len(included) {
+		case 0:
+		case 1:
+			clauses = append(clauses, included[0])
+		default:
+			clauses = append(clauses, "("+strings.Join(included, " OR ")+")")
+		}
+		included = nil
+	}
+	for _, key := range keys {
+		path, exclude := CustomFilterPath(key)
+		if path == "" {
+			continue
+		}
+		clause := path + "=" + strconv.Quote(filters[key])
+		if exclude {
+			flush()
+			clauses = append(clauses, "NOT "+clause)
+			continue
+		}
+		if path != includedPath {
+			flush()
+			includedPath = path
+		}
+		included = append(included, clause)
+	}
+	flush()
+	return clauses
+}
+
 // Build constructs the final filter string
 func (qb *Builder) Build() string {
 	if qb.baseFilter == "" && len(qb.filters) == 0 {
@@ -53,6 +53,16 @@ func TestBuilderAddSeverityRange(t *testing.T) {
 	}
 }
 
+func TestBuilderAddSeverityOrDefault(t *testing.T) {
+	result := NewBuilder("").AddSeverityOrDefault(models.SeverityFilter{MinLevel: models.SeverityError, Mode: "range"}).Build()
+	if result != "(severity>=ERROR OR severity=DEFAULT)" {
+		t.Errorf("Expected DEFAULT entries to be kept, got %s", result)
+	}
+	if result := NewBuilder("").AddSeverityOrDefault(models.SeverityFilter{}).Build(); result != "" {
+		t.Errorf("Expected no clause without a severity filter, got %s", result)
+	}
+}
+
 func TestBuilderAddTimeRange(t *testing.T) {
 	builder := NewBuilder("")
 	now := time.Now()
@@ -75,6 +85,15 @@ func TestBuilderAddTimeRange(t *testing.T) {
 	}
 }
 
+func TestBuilderAddTimeWindow(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 0, 0, 250000000, time.UTC)
+	result := NewBuilder("").AddTimeWindow(models.TimeRange{Start: at.Add(-time.Hour), End: at}).Build()
+	expected := `timestamp>="2024-05-01T09:00:00.25Z" AND timestamp<"2024-05-01T10:00:00.25Z"`
+	if result != expected {
+		t.Errorf("Expected %s, got %s", expected, result)
+	}
+}
+
 func TestBuilderAddCustomFilter(t *testing.T) {
 	builder := NewBuilder("")
 	builder.AddCustomFilter("resource.type=gae_app")
@@ -105,6 +124,18 @@ func TestBuilderAddLabelFilter(t *testing.T) {
 	}
 }
 
+func TestBuilderAddResourceLabelFilter(t *testing.T) {
+	result := NewBuilder("").
+		AddResourceLabelFilter("namespace_name", "prod").
+		AddResourceLabelMatch("pod_name", `^api-\d+$`).
+		Build()
+
+	want := `resource.labels.namespace_name="prod" AND resource.labels.pod_name=~"^api-\\d+$"`
+	if result != want {
+		t.Errorf("Expected %s, got %s", want, result)
+	}
+}
+
 func TestBuilderChaining(t *testing.T) {
 	builder := NewBuilder("").
 		AddCustomFilter("severity=ERROR").
@@ -265,3 +296,72 @@ func TestBuilderComplexQuery(t *testing.T) {
 		t.Error("Result should contain severity filters")
 	}
 }
+
+func TestFieldPathQuoting(t *testing.T) {
+	if got := FieldPath("labels", "k8s-pod/app"); got != `labels."k8s-pod/app"` {
+		t.Errorf("Expected quoted segment, got %s", got)
+	}
+	if got := FieldPath("jsonPayload", "user_id"); got != "jsonPayload.user_id" {
+		t.Errorf("Expected plain path, got %s", got)
+	}
+	builder := NewBuilder("")
+	builder.AddLabelFilter("app.kubernetes.io/name", "api")
+	if got := builder.Build(); got != `labels."app.kubernetes.io/name"="api"` {
+		t.Errorf("Expected quoted label key, got %s", got)
+	}
+	for value, want := range map[interface{}]string{"a\"b": `"a\"b"`, float64(1.5): "1.5", true: "true"} {
+		if got, ok := FormatFilterValue(value); !ok || got != want {
+			t.Errorf("FormatFilterValue(%v) = %q, want %q", value, got, want)
+		}
+	}
+	if _, ok := FormatFilterValue(nil); ok {
+		t.Error("Expected nil to have no filter value")
+	}
+}
+
+func TestCustomFilterClauses(t *testing.T) {
+	clauses := CustomFilterClauses(map[string]string{
+		"severity":                      "ERROR",
+		"-resource.labels.service_name": "billing",
+		"resource.labels.service_name":  "api",
+	})
+	want := []string{
+		`resource.labels.service_name="api"`,
+		`NOT resource.labels.service_name="billing"`,
+		`severity="ERROR"`,
+	}
+	if strings.Join(clauses, "|") != strings.Join(want, "|") {
+		t.Errorf("Unexpected clauses %v", clauses)
+	}
+}
+
+func TestCustomFilterClausesSeveralValues(t *testing.T) {
+	filters := map[string]string{}
+	for _, value := range []string{"debug", "info"} {
+		filters[CustomFilterKey("jsonPayload.level", value, true)] = value
+	}
+	for _, value := range []string{"warn", "error"} {
+		filters[CustomFilterKey("jsonPayload.level", value, false)] = value
+	}
+	filters[CustomFilterKey("severity", "ERROR", false)] = "ERROR"
+	clauses := CustomFilterClauses(filters)
+	want := []string{
+		`(jsonPayload.level="error" OR jsonPayload.level="warn")`,
+		`NOT jsonPayload.level="debug"`,
+		`NOT jsonPayload.level="info"`,
+		`severity="ERROR"`,
+	}
+	if strings.Join(clauses, "|") != strings.Join(want, "|") {
+		t.Errorf("Unexpected clauses %v", clauses)
+	}
+	builder := NewBuilder("")
+	for _, clause := range clauses {
+		builder.AddCustomFilter(clause)
+	}
+	if got := builder.Build(); !strings.HasPrefix(got, `(jsonPayload.level="error" OR jsonPayload.level="warn") AND NOT`) {
+		t.Errorf("Expected the included values ORed in the built filter, got %q", got)
+	}
+	if path, exclude := CustomFilterPath(CustomFilterKey("severity", "ERROR", true)); path != "severity" || !exclude {
+		t.Errorf("CustomFilterPath = %q, %v", path, exclude)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"cloud.google.com/go/logging"
 	"cloud.google.com/go/logging/logadmin"
 	"github.com/user/log-explorer-tui/pkg/models"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // Executor handles query execution against GCP Logging
@@ -18,6 +20,8 @@ type Executor struct {
 	projectID   string
 	timeout     time.Duration
 	validator   *Validator
+	messages    *MessageExtractor
+	severities  *SeverityInferrer
 }
 
 // NewExecutor creates a new query executor
@@ -28,9 +32,25 @@ func NewExecutor(client *logging.Client, projectID string, timeout time.Duration
 		projectID:   projectID,
 		timeout:     timeout,
 		validator:   NewValidator(),
+		messages:    defaultMessageExtractor,
 	}
 }
 
+// SetSeverityInference infers the severity of DEFAULT entries from the given
+// jsonPayload fields; enabled false turns it off
+func (e *Executor) SetSeverityInference(enabled bool, fields []string) {
+	e.severities = nil
+	if enabled {
+		e.severities = NewSeverityInferrer(fields)
+	}
+}
+
+// SetMessageRules sets the rules that pick the list message, tried before
+// DefaultMessageRules
+func (e *Executor) SetMessageRules(rules []models.MessageRule) {
+	e.messages = NewMessageExtractor(rules)
+}
+
 // ExecuteRequest represents parameters for query execution
 type ExecuteRequest struct {
 	Filter      string
@@ -139,7 +159,9 @@ func (e *Executor) Execute(ctx context.Context, req ExecuteRequest) (ExecuteResp
 		}
 
 		fmt.Fprintf(os.Stderr, "[DEBUG] Got log entry: %s - %s\n", entry.Severity, entry.Timestamp)
-		entries = append(entries, ConvertLoggingEntry(entry))
+		converted := convertLoggingEntry(entry, e.messages)
+		e.severities.Apply(&converted)
+		entries = append(entries, converted)
 		count++
 
 		// Respect page size limit
@@ -161,11 +183,16 @@ func (e *Executor) Execute(ctx context.Context, req ExecuteRequest) (ExecuteResp
 
 // ConvertLoggingEntry converts cloud.google.com/go/logging.Entry to models.LogEntry
 func ConvertLoggingEntry(entry *logging.Entry) models.LogEntry {
+	return convertLoggingEntry(entry, defaultMessageExtractor)
+}
+
+func convertLoggingEntry(entry *logging.Entry, messages *MessageExtractor) models.LogEntry {
 	modelEntry := models.LogEntry{
 		Timestamp: entry.Timestamp,
 		Severity:  entry.Severity.String(),
-		Message:   extractMessage(entry),
 		Labels:    entry.Labels,
+		ID:        entry.InsertID,
+		LogName:   entry.LogName,
 	}
 
 	// Convert resource
@@ -180,20 +207,30 @@ func ConvertLoggingEntry(entry *logging.Entry) models.LogEntry {
 	modelEntry.Trace = entry.Trace
 	modelEntry.SpanID = entry.SpanID
 
+	setLoggingPayload(&modelEntry, entry.Payload)
+	messages.Apply(&modelEntry)
+
 	// Store raw entry for detailed view
 	modelEntry.Raw = entry
 
 	return modelEntry
 }
 
-// extractMessage extracts the best message representation from an entry
-func extractMessage(entry *logging.Entry) string {
-	// Priority: Payload (which could be string or interface{})
-	if payload, ok := entry.Payload.(string); ok {
-		return payload
+// setLoggingPayload stores a client library payload, which is a string for
+// textPayload, a *structpb.Struct for jsonPayload and an *anypb.Any for
+// protoPayload
+func setLoggingPayload(entry *models.LogEntry, payload interface{}) {
+	switch p := payload.(type) {
+	case nil:
+	case string:
+		entry.TextPayload = p
+	case *structpb.Struct:
+		entry.JSONPayload = p.AsMap()
+	case *anypb.Any:
+		entry.ProtoPayload = map[string]interface{}{"@type": p.GetTypeUrl()}
+	default:
+		entry.TextPayload = fmt.Sprintf("%v", p)
 	}
-
-	return fmt.Sprintf("%v", entry.Payload)
 }
 
 // ValidateAndBuild validates a filter and returns the final filter string
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"strconv"
 	"time"
 
 	"github.com/user/log-explorer-tui/pkg/models"
@@ -53,40 +54,11 @@ func (e *Executor) ExecuteUsingGcloud(ctx context.Context, req ExecuteRequest) (
 	}
 
 	// Convert gcloud entries to our model
-	entries := []models.LogEntry{}
+	entries := make([]models.LogEntry, 0, len(gcloudEntries))
 	for _, entry := range gcloudEntries {
-		modelEntry := models.LogEntry{}
-
-		if ts, ok := entry["timestamp"].(string); ok {
-			if t, err := time.Parse(time.RFC3339, ts); err == nil {
-				modelEntry.Timestamp = t
-			}
-		}
-
-		if sev, ok := entry["severity"].(string); ok {
-			modelEntry.Severity = sev
-		}
-
-		// Try to get message from various fields
-		if text, ok := entry["textPayload"].(string); ok && text != "" {
-			modelEntry.Message = text
-		} else if jsonPayload, ok := entry["jsonPayload"].(map[string]interface{}); ok {
-			if msg, ok := jsonPayload["message"].(string); ok {
-				modelEntry.Message = msg
-			}
-		}
-
-		// Get labels if they exist
-		if labels, ok := entry["labels"].(map[string]interface{}); ok {
-			modelEntry.Labels = make(map[string]string)
-			for k, v := range labels {
-				if str, ok := v.(string); ok {
-					modelEntry.Labels[k] = str
-				}
-			}
-		}
-
-		entries = append(entries, modelEntry)
+		converted := convertGcloudEntry(entry, e.messages)
+		e.severities.Apply(&converted)
+		entries = append(entries, converted)
 	}
 
 	return ExecuteResponse{
@@ -96,3 +68,97 @@ func (e *Executor) ExecuteUsingGcloud(ctx context.Context, req ExecuteRequest) (
 		Duration:   time.Since(startTime),
 	}, nil
 }
+
+// ConvertGcloudEntry converts one entry of `gcloud logging read --format=json`
+// output to our model
+func ConvertGcloudEntry(entry map[string]interface{}) models.LogEntry {
+	return convertGcloudEntry(entry, defaultMessageExtractor)
+}
+
+func convertGcloudEntry(entry map[string]interface{}, messages *MessageExtractor) models.LogEntry {
+	modelEntry := models.LogEntry{Raw: entry}
+
+	if ts, ok := entry["timestamp"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			modelEntry.Timestamp = t
+		}
+	}
+
+	if sev, ok := entry["severity"].(string); ok {
+		modelEntry.Severity = sev
+	}
+	modelEntry.ID, _ = entry["insertId"].(string)
+	modelEntry.LogName, _ = entry["logName"].(string)
+	modelEntry.Trace, _ = entry["trace"].(string)
+	modelEntry.SpanID, _ = entry["spanId"].(string)
+	modelEntry.TextPayload, _ = entry["textPayload"].(string)
+	modelEntry.JSONPayload, _ = entry["jsonPayload"].(map[string]interface{})
+	modelEntry.ProtoPayload, _ = entry["protoPayload"].(map[string]interface{})
+
+	modelEntry.Labels = stringMap(entry["labels"])
+	if resource, ok := entry["resource"].(map[string]interface{}); ok {
+		modelEntry.Resource.Type, _ = resource["type"].(string)
+		modelEntry.Resource.Labels = stringMap(resource["labels"])
+	}
+
+	if req, ok := entry["httpRequest"].(map[string]interface{}); ok {
+		modelEntry.HTTPRequest = convertHTTPRequest(req)
+	}
+
+	if loc, ok := entry["sourceLocation"].(map[string]interface{}); ok {
+		modelEntry.SourceLocation = &models.SourceLocation{}
+		modelEntry.SourceLocation.File, _ = loc["file"].(string)
+		modelEntry.SourceLocation.Function, _ = loc["function"].(string)
+		modelEntry.SourceLocation.Line = jsonInt(loc["line"])
+	}
+
+	// Resource and logName must be set first; rules can be scoped by them
+	messages.Apply(&modelEntry)
+
+	return modelEntry
+}
+
+// convertHTTPRequest reads an httpRequest object. Int64 fields such as
+// responseSize arrive as strings, status as a number.
+func convertHTTPRequest(req map[string]interface{}) *models.HTTPRequest {
+	out := &models.HTTPRequest{}
+	out.RequestMethod, _ = req["requestMethod"].(string)
+	out.RequestURL, _ = req["requestUrl"].(string)
+	out.Status = int(jsonInt(req["status"]))
+	out.RequestSize = jsonInt(req["requestSize"])
+	out.ResponseSize = jsonInt(req["responseSize"])
+	out.UserAgent, _ = req["userAgent"].(string)
+	out.RemoteIP, _ = req["remoteIp"].(string)
+	out.ServerIP, _ = req["serverIp"].(string)
+	out.Referer, _ = req["referer"].(string)
+	out.Latency, _ = req["latency"].(string)
+	out.Protocol, _ = req["protocol"].(string)
+	return out
+}
+
+// jsonInt reads an integer that JSON output carries as a number or a string
+func jsonInt(value interface{}) int64 {
+	switch v := value.(type) {
+	case float64:
+		return int64(v)
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	}
+	return 0
+}
+
+// stringMap converts a decoded JSON object of strings, dropping other values
+func stringMap(value interface{}) map[string]string {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	out := make(map[string]string, len(obj))
+	for k, v := range obj {
+		if str, ok := v.(string); ok {
+			out[k] = str
+		}
+	}
+	return out
+}
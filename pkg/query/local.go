@@ -0,0 +1,459 @@
+package query
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// Local filter kinds
+const (
+	LocalKindQuery = "query" // Logging query syntax
+	LocalKindGrep  = "grep"  // Regular expression over the whole entry
+)
+
+// LocalFilter matches entries that are already loaded, without a round trip
+// to Cloud Logging. It supports the commonly used part of the Logging query
+// language: comparisons (=, !=, :, <, <=, >, >=, =~, !~), field:* existence,
+// AND/OR/NOT, a leading "-" for NOT, parentheses and bare search terms.
+type LocalFilter struct {
+	root localNode
+}
+
+// Matches reports whether the entry passes the filter
+func (f *LocalFilter) Matches(entry models.LogEntry) bool {
+	return f.root.matches(entry)
+}
+
+// CompileLocalFilter compiles an expression of the given kind. A grep
+// expression is a regular expression; "-v " in front inverts it and "-i "
+// makes it case-insensitive.
+func CompileLocalFilter(kind, expr string) (*LocalFilter, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, ErrEmptyFilter
+	}
+	if kind == LocalKindGrep {
+		return compileGrep(expr)
+	}
+	tokens, err := tokenizeLocal(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &localParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return &LocalFilter{root: root}, nil
+}
+
+// grepFlags strips the -v and -i flags from a grep expression
+func grepFlags(expr string) (pattern string, invert, insensitive bool) {
+	for {
+		switch {
+		case strings.HasPrefix(expr, "-v "):
+			invert = true
+		case strings.HasPrefix(expr, "-i "):
+			insensitive = true
+		default:
+			return expr, invert, insensitive
+		}
+		expr = strings.TrimSpace(expr[3:])
+	}
+}
+
+func compileGrep(expr string) (*LocalFilter, error) {
+	expr, invert, insensitive := grepFlags(expr)
+	if insensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	var node localNode = grepNode{re: re}
+	if invert {
+		node = notNode{inner: node}
+	}
+	return &LocalFilter{root: node}, nil
+}
+
+// ToServerFilter converts a grep expression into a Logging query clause so
+// it can be promoted to the server-side filter. Query expressions are
+// returned unchanged. A plain text search is case-insensitive on the server
+// already; -i makes a regular expression case-insensitive with (?i).
+func ToServerFilter(kind, expr string) string {
+	expr = strings.TrimSpace(expr)
+	if kind != LocalKindGrep {
+		return expr
+	}
+	expr, invert, insensitive := grepFlags(expr)
+	clause := strconv.Quote(expr)
+	if regexp.QuoteMeta(expr) != expr {
+		if insensitive {
+			expr = "(?i)" + expr
+		}
+		// Approximate a regular expression with a regex match on the payload
+		clause = fmt.Sprintf("(textPayload=~%s OR jsonPayload.message=~%s)", strconv.Quote(expr), strconv.Quote(expr))
+	}
+	if invert {
+		return "NOT " + clause
+	}
+	return clause
+}
+
+type localNode interface {
+	matches(entry models.LogEntry) bool
+}
+
+type andNode struct{ left, right localNode }
+
+func (n andNode) matches(e models.LogEntry) bool { return n.left.matches(e) && n.right.matches(e) }
+
+type orNode struct{ left, right localNode }
+
+func (n orNode) matches(e models.LogEntry) bool { return n.left.matches(e) || n.right.matches(e) }
+
+type notNode struct{ inner localNode }
+
+func (n notNode) matches(e models.LogEntry) bool { return !n.inner.matches(e) }
+
+type grepNode struct{ re *regexp.Regexp }
+
+func (n grepNode) matches(e models.LogEntry) bool {
+	for _, text := range EntryTexts(e) {
+		if n.re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// termNode is a bare search term; it matches any field case-insensitively
+type termNode struct{ term string }
+
+func (n termNode) matches(e models.LogEntry) bool {
+	term := strings.ToLower(n.term)
+	for _, text := range EntryTexts(e) {
+		if strings.Contains(strings.ToLower(text), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareNode compares a field with a value
+type compareNode struct {
+	field string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (n compareNode) matches(e models.LogEntry) bool {
+	actual, ok := e.FieldValue(n.field)
+	if n.op == ":" && n.value == "*" {
+		return ok
+	}
+	if !ok {
+		// A missing field never equals a value, so != matches it
+		return n.op == "!=" || n.op == "!~"
+	}
+	switch n.op {
+	case "=":
+		return CompareFieldValues(n.field, actual, n.value) == 0
+	case "!=":
+		return CompareFieldValues(n.field, actual, n.value) != 0
+	case ":":
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(n.value))
+	case "=~":
+		return n.re.MatchString(actual)
+	case "!~":
+		return !n.re.MatchString(actual)
+	case "<":
+		return CompareFieldValues(n.field, actual, n.value) < 0
+	case "<=":
+		return CompareFieldValues(n.field, actual, n.value) <= 0
+	case ">":
+		return CompareFieldValues(n.field, actual, n.value) > 0
+	case ">=":
+		return CompareFieldValues(n.field, actual, n.value) >= 0
+	}
+	return false
+}
+
+// CompareFieldValues orders two values of field: severities by level,
+// timestamps by time, and numbers and durations such as "0.25s"
+// numerically; anything else compares as text.
+func CompareFieldValues(field, actual, want string) int {
+	if field == "severity" {
+		a, b := models.SeverityRank(actual), models.SeverityRank(want)
+		if a >= 0 && b >= 0 {
+			return cmp.Compare(a, b)
+		}
+	}
+	if field == "timestamp" {
+		a, errA := time.Parse(time.RFC3339Nano, actual)
+		b, errB := time.Parse(time.RFC3339Nano, want)
+		if errA == nil && errB == nil {
+			return a.Compare(b)
+		}
+	}
+	if a, err := time.ParseDuration(actual); err == nil {
+		if b, err := time.ParseDuration(want); err == nil {
+			return cmp.Compare(a, b)
+		}
+	}
+	if a, err := strconv.ParseFloat(actual, 64); err == nil {
+		if b, err := strconv.ParseFloat(want, 64); err == nil {
+			return cmp.Compare(a, b)
+		}
+	}
+	return strings.Compare(actual, want)
+}
+
+// EntryTexts lists the entry fields that bare terms and grep look at:
+// message, text payload, labels as key=value, resource type, the HTTP
+// request line and the JSON payload.
+func EntryTexts(e models.LogEntry) []string {
+	texts := []string{e.Message}
+	if e.TextPayload != "" && e.TextPayload != e.Message {
+		texts = append(texts, e.TextPayload)
+	}
+	for _, labels := range []map[string]string{e.Labels, e.Resource.Labels} {
+		keys := make([]string, 0, len(labels))
+		for k := range labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			texts = append(texts, k+"="+labels[k])
+		}
+	}
+	if e.Resource.Type != "" {
+		texts = append(texts, e.Resource.Type)
+	}
+	if r := e.HTTPRequest; r != nil {
+		texts = append(texts, strings.TrimSpace(fmt.Sprintf("%s %s %d", r.RequestMethod, r.RequestURL, r.Status)))
+	}
+	if len(e.JSONPayload) > 0 {
+		if data, err := json.Marshal(e.JSONPayload); err == nil {
+			texts = append(texts, string(data))
+		}
+	}
+	return texts
+}
+
+type localToken struct {
+	kind string // word, string, op, lparen, rparen
+	text string
+}
+
+var localOps = []string{">=", "<=", "!=", "=~", "!~", "=", "<", ">", ":"}
+
+func tokenizeLocal(expr string) ([]localToken, error) {
+	var tokens []localToken
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, localToken{kind: "lparen", text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, localToken{kind: "rparen", text: ")"})
+			i++
+		case c == '"':
+			s, n, err := readQuoted(expr[i:])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, localToken{kind: "string", text: s})
+			i += n
+		default:
+			if op := matchOp(expr[i:]); op != "" {
+				tokens = append(tokens, localToken{kind: "op", text: op})
+				i += len(op)
+				continue
+			}
+			start := i
+			for i < len(expr) {
+				c := expr[i]
+				if c == '"' && i > start && expr[i-1] == '.' {
+					// Quoted path segment, e.g. labels."k8s-pod/app"
+					_, n, err := readQuoted(expr[i:])
+					if err != nil {
+						return nil, err
+					}
+					i += n
+					continue
+				}
+				if strings.ContainsRune(" \t\n\r()\"", rune(c)) || matchOp(expr[i:]) != "" {
+					break
+				}
+				i++
+			}
+			tokens = append(tokens, localToken{kind: "word", text: expr[start:i]})
+		}
+	}
+	return tokens, nil
+}
+
+func matchOp(s string) string {
+	for _, op := range localOps {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// readQuoted reads a double-quoted string at the start of s and returns its
+// unescaped value and the number of bytes consumed.
+func readQuoted(s string) (string, int, error) {
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				sb.WriteByte(s[i])
+			}
+		case '"':
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+type localParser struct {
+	tokens []localToken
+	pos    int
+}
+
+func (p *localParser) peek() (localToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return localToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *localParser) parseOr() (localNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind != "word" || tok.text != "OR" {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+}
+
+// parseAnd handles explicit AND as well as adjacent terms, which Cloud
+// Logging also treats as AND.
+func (p *localParser) parseAnd() (localNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind == "rparen" || (tok.kind == "word" && tok.text == "OR") {
+			return left, nil
+		}
+		if tok.kind == "word" && tok.text == "AND" {
+			p.pos++
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+}
+
+func (p *localParser) parseUnary() (localNode, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of filter")
+	}
+	if tok.kind == "word" && tok.text == "NOT" {
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner: inner}, nil
+	}
+	if tok.kind == "word" && strings.HasPrefix(tok.text, "-") && len(tok.text) > 1 {
+		p.tokens[p.pos].text = tok.text[1:]
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner: inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *localParser) parsePrimary() (localNode, error) {
+	tok, _ := p.peek()
+	p.pos++
+	switch tok.kind {
+	case "lparen":
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next, ok := p.peek(); !ok || next.kind != "rparen" {
+			return nil, ErrUnbalancedParens
+		}
+		p.pos++
+		return inner, nil
+	case "string":
+		return termNode{term: tok.text}, nil
+	case "word":
+		next, ok := p.peek()
+		if !ok || next.kind != "op" {
+			return termNode{term: tok.text}, nil
+		}
+		p.pos++
+		value, ok := p.peek()
+		if !ok || (value.kind != "word" && value.kind != "string") {
+			return nil, fmt.Errorf("missing value after %s%s", tok.text, next.text)
+		}
+		p.pos++
+		node := compareNode{field: tok.text, op: next.text, value: value.text}
+		if node.op == "=~" || node.op == "!~" {
+			re, err := regexp.Compile(value.text)
+			if err != nil {
+				return nil, err
+			}
+			node.re = re
+		}
+		return node, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", tok.text)
+}
@@ -0,0 +1,171 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func localTestEntries() []models.LogEntry {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	return []models.LogEntry{
+		{
+			Timestamp: base, Severity: "INFO", Message: "GET /healthz",
+			JSONPayload: map[string]interface{}{"httpRequest": map[string]interface{}{"requestUrl": "/healthz", "status": float64(200)}},
+			Labels:      map[string]string{"k8s-pod/app": "api"},
+		},
+		{
+			Timestamp: base.Add(time.Minute), Severity: "ERROR", Message: "Timeout calling billing",
+			JSONPayload: map[string]interface{}{"httpRequest": map[string]interface{}{"requestUrl": "/charge", "status": float64(504)}},
+			Resource:    models.Resource{Type: "k8s_container", Labels: map[string]string{"namespace_name": "payments"}},
+		},
+		{
+			Timestamp: base.Add(2 * time.Minute), Severity: "WARNING", Message: "retrying billing",
+			TextPayload: "retrying billing",
+		},
+	}
+}
+
+func matchingIndexes(t *testing.T, kind, expr string) []int {
+	t.Helper()
+	filter, err := CompileLocalFilter(kind, expr)
+	if err != nil {
+		t.Fatalf("CompileLocalFilter(%q): %v", expr, err)
+	}
+	var out []int
+	for i, entry := range localTestEntries() {
+		if filter.Matches(entry) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func TestLocalFilterQuerySyntax(t *testing.T) {
+	cases := []struct {
+		expr string
+		want []int
+	}{
+		{`severity>=WARNING`, []int{1, 2}},
+		{`severity<ERROR`, []int{0, 2}},
+		{`NOT jsonPayload.httpRequest.requestUrl:"/healthz"`, []int{1, 2}},
+		{`-jsonPayload.httpRequest.requestUrl="/healthz"`, []int{1, 2}},
+		{`jsonPayload.httpRequest.status>=500`, []int{1}},
+		{`jsonPayload.httpRequest.status!=200`, []int{1, 2}},
+		{`jsonPayload.httpRequest:*`, []int{0, 1}},
+		{`labels."k8s-pod/app"=api`, []int{0}},
+		{`resource.labels.namespace_name=payments OR textPayload=~"^retry"`, []int{1, 2}},
+		{`billing AND (severity=ERROR OR severity=INFO)`, []int{1}},
+		{`billing severity=WARNING`, []int{2}},
+		{`timestamp>"2024-05-01T10:00:30Z"`, []int{1, 2}},
+		{`textPayload!~"retry"`, []int{0, 1}},
+	}
+	for _, tc := range cases {
+		got := matchingIndexes(t, LocalKindQuery, tc.expr)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.expr, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: got %v, want %v", tc.expr, got, tc.want)
+				break
+			}
+		}
+	}
+}
+
+func TestLocalFilterErrors(t *testing.T) {
+	for _, expr := range []string{"", "(severity=ERROR", `message="unterminated`, "severity=ERROR OR", `textPayload=~"["`} {
+		if _, err := CompileLocalFilter(LocalKindQuery, expr); err == nil {
+			t.Errorf("Expected error for %q", expr)
+		}
+	}
+	if _, err := CompileLocalFilter(LocalKindGrep, "(["); err == nil {
+		t.Error("Expected error for an invalid grep pattern")
+	}
+}
+
+func TestLocalFilterGrep(t *testing.T) {
+	if got := matchingIndexes(t, LocalKindGrep, "Billing"); len(got) != 0 {
+		t.Errorf("grep should be case-sensitive by default, got %v", got)
+	}
+	if got := matchingIndexes(t, LocalKindGrep, "-i Billing"); len(got) != 2 {
+		t.Errorf("-i: expected 2 matches, got %v", got)
+	}
+	if got := matchingIndexes(t, LocalKindGrep, "-v -i billing"); len(got) != 1 || got[0] != 0 {
+		t.Errorf("-v -i: expected only entry 0, got %v", got)
+	}
+	if got := matchingIndexes(t, LocalKindGrep, `"status":50\d`); len(got) != 1 || got[0] != 1 {
+		t.Errorf("Expected grep to look at the JSON payload, got %v", got)
+	}
+}
+
+func TestToServerFilter(t *testing.T) {
+	cases := []struct {
+		kind, expr, want string
+	}{
+		{LocalKindQuery, " severity>=ERROR ", "severity>=ERROR"},
+		{LocalKindGrep, "billing", `"billing"`},
+		{LocalKindGrep, "-v healthz", `NOT "healthz"`},
+		{LocalKindGrep, "time.*out", `(textPayload=~"time.*out" OR jsonPayload.message=~"time.*out")`},
+		{LocalKindGrep, "-v -i time.*out", `NOT (textPayload=~"(?i)time.*out" OR jsonPayload.message=~"(?i)time.*out")`},
+		{LocalKindGrep, "-i Billing", `"Billing"`},
+	}
+	for _, tc := range cases {
+		if got := ToServerFilter(tc.kind, tc.expr); got != tc.want {
+			t.Errorf("ToServerFilter(%s, %q) = %q, want %q", tc.kind, tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestConvertGcloudEntry(t *testing.T) {
+	entry := ConvertGcloudEntry(map[string]interface{}{
+		"timestamp":   "2024-05-01T10:00:00Z",
+		"severity":    "ERROR",
+		"insertId":    "abc",
+		"trace":       "projects/p/traces/t1",
+		"jsonPayload": map[string]interface{}{"message": "boom", "code": float64(7)},
+		"labels":      map[string]interface{}{"env": "prod"},
+		"resource": map[string]interface{}{
+			"type":   "k8s_container",
+			"labels": map[string]interface{}{"pod_name": "api-1"},
+		},
+		"sourceLocation": map[string]interface{}{"file": "main.go", "line": "42"},
+		"httpRequest":    map[string]interface{}{"requestMethod": "GET", "status": float64(503), "responseSize": "1024", "latency": "0.5s"},
+	})
+	if entry.Message != "boom" || entry.ID != "abc" || entry.Trace != "projects/p/traces/t1" {
+		t.Errorf("Unexpected entry %+v", entry)
+	}
+	if entry.Resource.Type != "k8s_container" || entry.Resource.Labels["pod_name"] != "api-1" || entry.Labels["env"] != "prod" {
+		t.Errorf("Unexpected labels or resource %+v", entry)
+	}
+	if entry.SourceLocation == nil || entry.SourceLocation.Line != 42 {
+		t.Errorf("Expected source line 42, got %+v", entry.SourceLocation)
+	}
+	if v, ok := entry.FieldValue("jsonPayload.code"); !ok || v != "7" {
+		t.Errorf("Expected jsonPayload.code=7, got %q", v)
+	}
+	if req := entry.HTTPRequest; req == nil || req.Status != 503 || req.ResponseSize != 1024 || req.Latency != "0.5s" {
+		t.Errorf("Unexpected httpRequest %+v", entry.HTTPRequest)
+	}
+}
+
+func TestCompareFieldValues(t *testing.T) {
+	tests := []struct {
+		field, a, b string
+		want        int
+	}{
+		{"httpRequest.status", "99", "500", -1},
+		{"httpRequest.latency", "0.9s", "12ms", 1},
+		{"severity", "ERROR", "WARNING", 1},
+		{"jsonPayload.user", "bob", "alice", 1},
+		{"jsonPayload.user", "x", "x", 0},
+	}
+	for _, tt := range tests {
+		if got := CompareFieldValues(tt.field, tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareFieldValues(%s, %q, %q) = %d, want %d", tt.field, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package query
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// DefaultMessageRules are tried after the configured rules
+var DefaultMessageRules = []models.MessageRule{
+	{Field: "textPayload"},
+	{Field: "jsonPayload.message"},
+	{Field: "jsonPayload.msg"},
+	{Field: "jsonPayload.event"},
+	{Field: "jsonPayload.error.message"},
+	{Field: "protoPayload.methodName"},
+}
+
+// maxSummaryLength caps the key=value summary used when no rule matches, in
+// runes
+const maxSummaryLength = 300
+
+// MessageExtractor picks the message shown in the log list from an entry
+type MessageExtractor struct {
+	rules []models.MessageRule
+}
+
+// NewMessageExtractor creates an extractor that tries rules in order, then
+// DefaultMessageRules
+func NewMessageExtractor(rules []models.MessageRule) *MessageExtractor {
+	all := make([]models.MessageRule, 0, len(rules)+len(DefaultMessageRules))
+	all = append(all, rules...)
+	all = append(all, DefaultMessageRules...)
+	return &MessageExtractor{rules: all}
+}
+
+var defaultMessageExtractor = NewMessageExtractor(nil)
+
+// Apply sets Message and MessageField on entry from the first matching rule
+// with a non-empty value. Without one, Message is a compact key=value
+// summary of the payload.
+func (m *MessageExtractor) Apply(entry *models.LogEntry) {
+	for _, rule := range m.rules {
+		if !messageRuleApplies(rule, *entry) {
+			continue
+		}
+		if value, ok := entry.FieldValue(rule.Field); ok && strings.TrimSpace(value) != "" {
+			entry.Message = value
+			entry.MessageField = rule.Field
+			return
+		}
+	}
+	entry.MessageField = ""
+	switch {
+	case entry.JSONPayload != nil:
+		entry.Message = PayloadSummary(entry.JSONPayload)
+	case entry.ProtoPayload != nil:
+		entry.Message = PayloadSummary(entry.ProtoPayload)
+	default:
+		entry.Message = ""
+	}
+}
+
+func messageRuleApplies(rule models.MessageRule, entry models.LogEntry) bool {
+	if rule.ResourceType != "" && rule.ResourceType != entry.Resource.Type {
+		return false
+	}
+	if rule.LogName != "" && rule.LogName != entry.LogName && rule.LogName != models.LogID(entry.LogName) {
+		return false
+	}
+	return true
+}
+
+// PayloadSummary renders a payload as sorted key=value pairs. Nested objects
+// are flattened with dotted keys, and values with spaces are quoted.
+func PayloadSummary(payload map[string]interface{}) string {
+	var pairs []string
+	flattenPayload("", payload, &pairs)
+	sort.Strings(pairs)
+	summary := strings.Join(pairs, " ")
+	if runes := []rune(summary); len(runes) > maxSummaryLength {
+		summary = string(runes[:maxSummaryLength-3]) + "..."
+	}
+	return summary
+}
+
+func flattenPayload(prefix string, obj map[string]interface{}, pairs *[]string) {
+	for key, value := range obj {
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenPayload(prefix+key+".", nested, pairs)
+			continue
+		}
+		*pairs = append(*pairs, prefix+key+"="+summaryValue(value))
+	}
+}
+
+func summaryValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+			return strconv.Quote(v)
+		}
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "?"
+	}
+	return string(data)
+}
@@ -0,0 +1,68 @@
+package query
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func TestMessageExtractorRules(t *testing.T) {
+	m := NewMessageExtractor([]models.MessageRule{
+		{Field: "jsonPayload.summary", ResourceType: "k8s_container"},
+		{Field: "jsonPayload.detail", LogName: "worker"},
+	})
+	tests := []struct {
+		name      string
+		entry     models.LogEntry
+		want      string
+		wantField string
+	}{
+		{"text payload", models.LogEntry{TextPayload: "plain"}, "plain", "textPayload"},
+		{"msg", models.LogEntry{JSONPayload: map[string]interface{}{"msg": "started"}}, "started", "jsonPayload.msg"},
+		{"error message", models.LogEntry{JSONPayload: map[string]interface{}{"error": map[string]interface{}{"message": "boom"}}}, "boom", "jsonPayload.error.message"},
+		{"audit method", models.LogEntry{ProtoPayload: map[string]interface{}{"methodName": "storage.buckets.delete"}}, "storage.buckets.delete", "protoPayload.methodName"},
+		{"scoped by resource", models.LogEntry{
+			Resource:    models.Resource{Type: "k8s_container"},
+			JSONPayload: map[string]interface{}{"summary": "s", "message": "m"},
+		}, "s", "jsonPayload.summary"},
+		{"other resource", models.LogEntry{
+			Resource:    models.Resource{Type: "gce_instance"},
+			JSONPayload: map[string]interface{}{"summary": "s", "message": "m"},
+		}, "m", "jsonPayload.message"},
+		{"scoped by log id", models.LogEntry{
+			LogName:     "projects/p/logs/worker",
+			JSONPayload: map[string]interface{}{"detail": "d", "message": "m"},
+		}, "d", "jsonPayload.detail"},
+		{"empty value skipped", models.LogEntry{JSONPayload: map[string]interface{}{"message": " ", "event": "login"}}, "login", "jsonPayload.event"},
+		{"summary", models.LogEntry{JSONPayload: map[string]interface{}{"user": map[string]interface{}{"id": "u1"}, "ok": true, "note": "two words"}}, `note="two words" ok=true user.id=u1`, ""},
+	}
+	for _, tt := range tests {
+		entry := tt.entry
+		m.Apply(&entry)
+		if entry.Message != tt.want || entry.MessageField != tt.wantField {
+			t.Errorf("%s: got %q from %q, want %q from %q", tt.name, entry.Message, entry.MessageField, tt.want, tt.wantField)
+		}
+	}
+}
+
+func TestPayloadSummaryTruncatesRunes(t *testing.T) {
+	summary := PayloadSummary(map[string]interface{}{"msg": strings.Repeat("é", 400)})
+	if !utf8.ValidString(summary) || utf8.RuneCountInString(summary) != maxSummaryLength || !strings.HasSuffix(summary, "é...") {
+		t.Errorf("Expected a valid summary of %d runes, got %d runes (valid %v)", maxSummaryLength, utf8.RuneCountInString(summary), utf8.ValidString(summary))
+	}
+}
+
+func TestConvertGcloudEntryProtoPayload(t *testing.T) {
+	entry := ConvertGcloudEntry(map[string]interface{}{
+		"logName":      "projects/p/logs/cloudaudit.googleapis.com%2Factivity",
+		"protoPayload": map[string]interface{}{"methodName": "v1.compute.instances.insert"},
+	})
+	if entry.Message != "v1.compute.instances.insert" {
+		t.Errorf("Expected the audit method as message, got %q", entry.Message)
+	}
+	if id := models.LogID(entry.LogName); id != "cloudaudit.googleapis.com/activity" {
+		t.Errorf("Unexpected log ID %q", id)
+	}
+}
@@ -0,0 +1,116 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// DefaultSeverityFields are the jsonPayload fields read when inferring the
+// severity of DEFAULT entries
+var DefaultSeverityFields = []string{"level", "lvl", "severity", "log.level"}
+
+// vendorLevels maps level names used by common logging libraries onto
+// models.SeverityLevels
+var vendorLevels = map[string]string{
+	"trace":       models.SeverityDebug,
+	"debug":       models.SeverityDebug,
+	"dbg":         models.SeverityDebug,
+	"info":        models.SeverityInfo,
+	"information": models.SeverityInfo,
+	"notice":      models.SeverityNotice,
+	"warn":        models.SeverityWarning,
+	"warning":     models.SeverityWarning,
+	"err":         models.SeverityError,
+	"error":       models.SeverityError,
+	"crit":        models.SeverityCritical,
+	"critical":    models.SeverityCritical,
+	"fatal":       models.SeverityCritical,
+	"dpanic":      models.SeverityCritical,
+	"panic":       models.SeverityAlert,
+	"alert":       models.SeverityAlert,
+	"emerg":       models.SeverityEmergency,
+	"emergency":   models.SeverityEmergency,
+}
+
+// NormalizeSeverity maps a vendor level such as "warn", "fatal" or the
+// numeric pino/bunyan levels onto models.SeverityLevels
+func NormalizeSeverity(level string) (string, bool) {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if mapped, ok := vendorLevels[level]; ok {
+		return mapped, true
+	}
+	if n, err := strconv.Atoi(level); err == nil {
+		switch {
+		case n >= 60:
+			return models.SeverityCritical, true
+		case n >= 50:
+			return models.SeverityError, true
+		case n >= 40:
+			return models.SeverityWarning, true
+		case n >= 30:
+			return models.SeverityInfo, true
+		case n >= 10:
+			return models.SeverityDebug, true
+		}
+	}
+	return "", false
+}
+
+// SeverityInferrer replaces the DEFAULT severity of entries whose payload
+// names a level
+type SeverityInferrer struct {
+	fields []string
+}
+
+// NewSeverityInferrer creates an inferrer reading the given jsonPayload
+// fields in order, or DefaultSeverityFields when none are given
+func NewSeverityInferrer(fields []string) *SeverityInferrer {
+	if len(fields) == 0 {
+		fields = DefaultSeverityFields
+	}
+	return &SeverityInferrer{fields: fields}
+}
+
+// Apply infers the severity of entry when it has none or DEFAULT, and
+// records the field it was read from in SeverityField. A nil inferrer does
+// nothing.
+func (s *SeverityInferrer) Apply(entry *models.LogEntry) {
+	if s == nil || entry.JSONPayload == nil {
+		return
+	}
+	if entry.Severity != "" && entry.Severity != models.SeverityDefault {
+		return
+	}
+	for _, field := range s.fields {
+		path, value, ok := payloadLevel(entry.JSONPayload, field)
+		if !ok {
+			continue
+		}
+		if level, ok := NormalizeSeverity(value); ok {
+			entry.Severity = level
+			entry.SeverityField = path
+			return
+		}
+	}
+}
+
+// payloadLevel reads field from the payload as a nested path, then as a
+// literal key, since "log.level" is written both ways
+func payloadLevel(payload map[string]interface{}, field string) (string, string, bool) {
+	entry := models.LogEntry{JSONPayload: payload}
+	path := "jsonPayload." + field
+	if value, ok := entry.FieldValue(path); ok {
+		return path, value, true
+	}
+	if value, ok := payload[field]; ok {
+		if s, ok := value.(string); ok {
+			return FieldPath("jsonPayload", field), s, true
+		}
+		if n, ok := value.(float64); ok {
+			return FieldPath("jsonPayload", field), strconv.FormatFloat(n, 'f', -1, 64), true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,62 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func TestNormalizeSeverity(t *testing.T) {
+	tests := map[string]string{
+		"warn":    models.SeverityWarning,
+		"FATAL":   models.SeverityCritical,
+		"panic":   models.SeverityAlert,
+		"trace":   models.SeverityDebug,
+		" Error ": models.SeverityError,
+		"NOTICE":  models.SeverityNotice,
+		"30":      models.SeverityInfo,
+		"50":      models.SeverityError,
+	}
+	for level, want := range tests {
+		if got, ok := NormalizeSeverity(level); !ok || got != want {
+			t.Errorf("NormalizeSeverity(%q) = %q, %v; want %q", level, got, ok, want)
+		}
+	}
+	for _, level := range []string{"", "loud", "DEFAULT", "5"} {
+		if _, ok := NormalizeSeverity(level); ok {
+			t.Errorf("Expected %q not to map to a severity", level)
+		}
+	}
+}
+
+func TestSeverityInferrer(t *testing.T) {
+	s := NewSeverityInferrer(nil)
+	tests := []struct {
+		name      string
+		entry     models.LogEntry
+		want      string
+		wantField string
+	}{
+		{"level", models.LogEntry{Severity: "DEFAULT", JSONPayload: map[string]interface{}{"level": "error"}}, "ERROR", "jsonPayload.level"},
+		{"no severity", models.LogEntry{JSONPayload: map[string]interface{}{"lvl": "warn"}}, "WARNING", "jsonPayload.lvl"},
+		{"nested log.level", models.LogEntry{Severity: "DEFAULT", JSONPayload: map[string]interface{}{"log": map[string]interface{}{"level": "debug"}}}, "DEBUG", "jsonPayload.log.level"},
+		{"flat log.level", models.LogEntry{Severity: "DEFAULT", JSONPayload: map[string]interface{}{"log.level": "fatal"}}, "CRITICAL", `jsonPayload."log.level"`},
+		{"numeric", models.LogEntry{Severity: "DEFAULT", JSONPayload: map[string]interface{}{"level": float64(40)}}, "WARNING", "jsonPayload.level"},
+		{"logged severity kept", models.LogEntry{Severity: "INFO", JSONPayload: map[string]interface{}{"level": "error"}}, "INFO", ""},
+		{"unknown level", models.LogEntry{Severity: "DEFAULT", JSONPayload: map[string]interface{}{"level": "chatty"}}, "DEFAULT", ""},
+	}
+	for _, tt := range tests {
+		entry := tt.entry
+		s.Apply(&entry)
+		if entry.Severity != tt.want || entry.SeverityField != tt.wantField {
+			t.Errorf("%s: got %q from %q, want %q from %q", tt.name, entry.Severity, entry.SeverityField, tt.want, tt.wantField)
+		}
+	}
+
+	var off *SeverityInferrer
+	entry := models.LogEntry{Severity: "DEFAULT", JSONPayload: map[string]interface{}{"level": "error"}}
+	off.Apply(&entry)
+	if entry.Severity != "DEFAULT" {
+		t.Error("Expected a nil inferrer to leave the entry alone")
+	}
+}
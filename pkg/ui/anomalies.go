@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// anomalyBuckets maps the start of every anomalous bucket to its anomaly
+func (a *App) anomalyBuckets() map[int64]TimelineAnomaly {
+	byBucket := map[int64]TimelineAnomaly{}
+	for _, anomaly := range a.timelineBuilder.DetectAnomalies(a.timelinePoints()) {
+		byBucket[anomaly.Timestamp.Unix()] = anomaly
+	}
+	return byBucket
+}
+
+// anomalySummary renders the anomaly line of the graph panel, e.g.
+// "2 anomalies: spike 10:05, errors 10:20 (a next, A zoom)"
+func (a *App) anomalySummary(anomalies []TimelineAnomaly) string {
+	layout := "15:04"
+	if a.timelineBuilder.GetBucketSize() < time.Minute {
+		layout = "15:04:05"
+	}
+	var parts []string
+	for i, anomaly := range anomalies {
+		if i == 3 {
+			parts = append(parts, "...")
+			break
+		}
+		parts = append(parts, anomaly.Kind+" "+a.displayTime(anomaly.Timestamp).Format(layout))
+	}
+	noun := "anomalies"
+	if len(anomalies) == 1 {
+		noun = "anomaly"
+	}
+	return fmt.Sprintf("%d %s: %s (a next, A zoom)", len(anomalies), noun, strings.Join(parts, ", "))
+}
+
+// jumpToNextAnomaly selects the next entry down the list that falls in
+// another anomalous bucket than the selected one
+func (a *App) jumpToNextAnomaly() bool {
+	byBucket := a.anomalyBuckets()
+	if len(byBucket) == 0 {
+		a.lastErr = "No anomalies on the timeline"
+		return false
+	}
+	logs := a.viewLogs()
+	if len(logs) == 0 {
+		return false
+	}
+	bucket := a.timelineBuilder.GetBucketSize()
+	cursor := clampInt(a.panes.LogList.scrollOffset, 0, len(logs)-1)
+	current := logs[cursor].Timestamp.Truncate(bucket).Unix()
+	for step := 1; step <= len(logs); step++ {
+		i := (cursor + step) % len(logs)
+		key := logs[i].Timestamp.Truncate(bucket).Unix()
+		anomaly, ok := byBucket[key]
+		if !ok || key == current {
+			continue
+		}
+		a.panes.LogList.scrollOffset = i
+		a.lastErr = fmt.Sprintf("Anomaly: %s at %s (score %.1f)", anomaly.Kind, a.displayTime(anomaly.Timestamp).Format("15:04:05"), anomaly.Score)
+		return true
+	}
+	a.lastErr = "No other anomaly in the list"
+	return false
+}
+
+// zoomToAnomaly sets the time range to the anomalous bucket of the selected
+// entry, or of the next anomaly, with a bucket of context on each side
+func (a *App) zoomToAnomaly() tea.Cmd {
+	entry := a.getSelectedLog()
+	if entry == nil {
+		return nil
+	}
+	bucket := a.timelineBuilder.GetBucketSize()
+	anomaly, ok := a.anomalyBuckets()[entry.Timestamp.Truncate(bucket).Unix()]
+	if !ok {
+		if !a.jumpToNextAnomaly() {
+			return nil
+		}
+		entry = a.getSelectedLog()
+		anomaly = a.anomalyBuckets()[entry.Timestamp.Truncate(bucket).Unix()]
+	}
+	cmd := a.zoomTimeRange(models.TimeRange{
+		Start:  anomaly.Timestamp.Add(-bucket),
+		End:    anomaly.Timestamp.Add(2 * bucket),
+		Preset: "custom",
+	})
+	a.lastErr = fmt.Sprintf("Zoomed to the %s at %s", anomaly.Kind, a.displayTime(anomaly.Timestamp).Format("15:04:05"))
+	return cmd
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// anomalyTestLogs has 4 entries a minute, newest first, with a burst of
+// errors at 10:06
+func anomalyTestLogs() []models.LogEntry {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var logs []models.LogEntry
+	for minute := 9; minute >= 0; minute-- {
+		n, severity := 4, "INFO"
+		if minute == 6 {
+			n, severity = 30, "ERROR"
+		}
+		for i := 0; i < n; i++ {
+			logs = append(logs, models.LogEntry{
+				ID:        fmt.Sprintf("%d-%d", minute, i),
+				Timestamp: base.Add(time.Duration(minute)*time.Minute + time.Duration(i)*time.Second),
+				Severity:  severity,
+				Message:   "tick",
+			})
+		}
+	}
+	return logs
+}
+
+func TestAnomalyJumpAndZoom(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.SetTimelineBucket(time.Minute)
+	app.state.LogListState.Logs = anomalyTestLogs()
+	var ran string
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		ran = filter
+		return nil, nil
+	})
+
+	view := ansiEscapeRegex.ReplaceAllString(app.View(), "")
+	if !strings.Contains(view, "1 anomaly: errors 10:06 (a next, A zoom)") {
+		t.Errorf("Expected the anomaly in the graph panel, got:\n%s", view)
+	}
+
+	app = pressKeys(app, runeKey('a'))
+	selected := app.getSelectedLog()
+	if selected == nil || !strings.HasPrefix(selected.ID, "6-") {
+		t.Fatalf("Expected a jump into the 10:06 bucket, got %+v", selected)
+	}
+	if !strings.Contains(app.lastErr, "errors at") {
+		t.Errorf("Unexpected status %q", app.lastErr)
+	}
+
+	_, cmd := app.handleKeyPress(runeKey('A'))
+	if cmd == nil {
+		t.Fatal("Expected A to rerun the query")
+	}
+	cmd()
+	tr := app.state.FilterState.TimeRange
+	if tr.Start.Format("15:04") != "10:05" || tr.End.Format("15:04") != "10:08" {
+		t.Errorf("Expected the range around the bucket, got %v - %v", tr.Start, tr.End)
+	}
+	if !strings.Contains(ran, `timestamp>="2024-05-01T10:05:00Z"`) {
+		t.Errorf("Expected the zoomed query, got %q", ran)
+	}
+}
+
+func TestNoAnomalies(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.state.LogListState.Logs = []models.LogEntry{{ID: "1", Timestamp: time.Now(), Message: "one"}}
+	if app.jumpToNextAnomaly() || app.lastErr != "No anomalies on the timeline" {
+		t.Errorf("Expected no anomalies, got %q", app.lastErr)
+	}
+}
@@ -23,21 +23,6 @@ var (
 	ansiEscapeRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
 )
 
-const (
-	colorGCPBlue       = "33"
-	colorGCPBlueDark   = "24"
-	colorGCPBlueLight  = "117"
-	colorGCPGreen      = "42"
-	colorGCPWarn       = "220"
-	colorGCPError      = "203"
-	colorNeutralText   = "252"
-	colorNeutralSubtle = "244"
-	colorSelectionBG   = "25"
-	colorSelectionFG   = "230"
-	colorBadgeTextDark = "16"
-	colorBadgeTextLite = "230"
-)
-
 // App represents the main TUI application
 type App struct {
 	state                   *models.AppState
@@ -87,6 +72,50 @@ type App struct {
 	persistHistoryFn        func(filter, project string) error
 	persistLibraryFn        func([]config.SavedQueryRecord) error
 	persistCacheFn          func([]config.CachedQueryRecord) error
+	persistPrefsFn          func(config.Preferences) error
+	prefs                   config.Preferences
+	projectSettingsFn       func(string, config.Preferences) (config.Config, error)
+	pendingSelectKey        string
+	pendingScroll           int
+	pendingDetailMode       string
+	pendingDetailOpen       bool
+	prompt                  *Prompt
+	projectQueryExec        func(project, filter string) ([]models.LogEntry, error)
+	tabs                    []*workspace
+	activeTab               int
+	loadedTabID             int // tab whose workspace withWorkspace swapped in, if any
+	nextTabID               int
+	cacheHits               int
+	streamPending           bool
+	streamTicking           map[int]bool
+	splitMode               string
+	splitPartnerID          int
+	viewCache               *logView
+	searchRestore           *searchSnapshot
+	searchOriginKey         string
+	filterStackCursor       int
+	facetsOpen              bool
+	facetCursor             int
+	facetFields             []string
+	inferSeverity           bool
+	facetCache              *facetCache
+	columnCursor            int
+	columnSets              map[string][]models.Column
+	persistColumnsFn        func(config.ColumnSets) error
+	httpMode                bool
+	k8s                     k8sNavigator
+	trace                   traceView
+	correlation             correlationView
+	entryContext            entryContextView
+	patterns                patternView
+	compare                 compareView
+	timelineAuto            bool // bucket size follows the time range
+	timelineFocused         bool
+	timelineCursor          int // bucket under the cursor
+	timelineBrush           int // bucket where a brushed span starts, or -1
+	timelineZoomStack       []models.TimeRange
+	metricFields            []string // numeric fields plotted on the timeline
+	metricStat              string   // statistic the plotted fields show per bucket
 }
 
 type queryResultMsg struct {
@@ -97,6 +126,8 @@ type queryResultMsg struct {
 	anchorOffset   int
 	preserveAnchor bool
 	fromCache      bool
+	tabID          int  // workspace that issued the query; 0 means the active tab
+	stream         bool // incremental fetch issued by a stream tick
 }
 
 type editorResultMsg struct {
@@ -120,7 +151,7 @@ func NewApp(appState *models.AppState) *App {
 	formatter := NewLogFormatter(120, false)
 	timelineBuilder := NewTimelineBuilder(5 * time.Minute)
 	availableProjects := collectProjects(appState.CurrentProject)
-	return &App{
+	app := &App{
 		state:                   appState,
 		width:                   120,
 		height:                  40,
@@ -168,6 +199,47 @@ func NewApp(appState *models.AppState) *App {
 		persistHistoryFn:        nil,
 		persistLibraryFn:        nil,
 		persistCacheFn:          nil,
+		persistPrefsFn:          nil,
+		pendingScroll:           -1,
+		prompt:                  NewPrompt(),
+		tabs:                    []*workspace{{id: 1, name: "tab1"}},
+		activeTab:               0,
+		nextTabID:               2,
+		streamTicking:           map[int]bool{},
+		timelineAuto:            true,
+		timelineBrush:           -1,
+		metricStat:              MetricAvg,
+	}
+	app.syncFilterControls()
+	return app
+}
+
+// syncFilterControls aligns the time picker and severity panel with a filter
+// state seeded before the app was created (e.g. from command-line flags).
+func (a *App) syncFilterControls() {
+	tr := a.state.FilterState.TimeRange
+	if !tr.Start.IsZero() && !tr.End.IsZero() {
+		for idx, preset := range a.timePicker.GetPresets() {
+			if preset.Key != tr.Preset {
+				continue
+			}
+			_ = a.timePicker.SelectPreset(idx)
+			if preset.Key == "custom" {
+				_ = a.timePicker.SetCustomRange(tr.Start, tr.End)
+			}
+			break
+		}
+	}
+
+	sf := a.state.FilterState.Severity
+	switch {
+	case sf.Mode == "range" && sf.MinLevel != "":
+		_ = a.severityFilter.SetMode("range")
+		_ = a.severityFilter.SetMinimumLevel(sf.MinLevel)
+	case sf.Mode == "individual" && len(sf.Levels) > 0:
+		for _, level := range sf.Levels {
+			_ = a.severityFilter.SetLevel(level, true)
+		}
 	}
 }
 
@@ -259,6 +331,15 @@ func (a *App) SetQueryExecutor(fn func(string) ([]models.LogEntry, error)) {
 	a.queryExec = fn
 }
 
+// SetProjectQueryExecutor sets a query execution function that receives the
+// project explicitly, so queries issued from background tabs hit the right project.
+func (a *App) SetProjectQueryExecutor(fn func(project, filter string) ([]models.LogEntry, error)) {
+	a.projectQueryExec = fn
+	a.queryExec = func(filter string) ([]models.LogEntry, error) {
+		return fn(a.state.CurrentProject, filter)
+	}
+}
+
 // SetStartupFilter configures a filter to execute automatically during Init.
 func (a *App) SetStartupFilter(filter string) {
 	a.startupFilter = filter
@@ -269,6 +350,17 @@ func (a *App) SetVimMode(enabled bool) {
 	a.vimMode = enabled
 }
 
+// SetTimezoneMode sets the timestamp display mode ("utc" or "local").
+func (a *App) SetTimezoneMode(mode string) {
+	if strings.EqualFold(strings.TrimSpace(mode), "local") {
+		a.timezoneMode = "local"
+		a.formatter.SetUseLocalTime(true)
+		return
+	}
+	a.timezoneMode = "utc"
+	a.formatter.SetUseLocalTime(false)
+}
+
 func (a *App) toggleTimezoneMode() {
 	if a.timezoneMode == "local" {
 		a.timezoneMode = "utc"
@@ -282,10 +374,7 @@ func (a *App) toggleTimezoneMode() {
 }
 
 func (a *App) toggleLogOrder() {
-	selectedKey := ""
-	if len(a.state.LogListState.Logs) > 0 {
-		selectedKey = logEntryKey(a.state.LogListState.Logs[a.currentSelectedIndex()])
-	}
+	selectedKey := a.selectedLogKey()
 	if a.logOrder == "latest_bottom" {
 		a.logOrder = "latest_top"
 	} else {
@@ -307,6 +396,14 @@ func (a *App) displayTime(t time.Time) time.Time {
 	return t.UTC()
 }
 
+// displayLocation is the location displayTime converts to
+func (a *App) displayLocation() *time.Location {
+	if a.timezoneMode == "local" {
+		return time.Local
+	}
+	return time.UTC
+}
+
 func (a *App) sortLogsForDisplay(logs []models.LogEntry) []models.LogEntry {
 	out := append([]models.LogEntry{}, logs...)
 	if len(out) < 2 {
@@ -325,7 +422,7 @@ func (a *App) sortLogsForDisplay(logs []models.LogEntry) []models.LogEntry {
 }
 
 func (a *App) findLogIndexByKey(key string) (int, bool) {
-	for i, entry := range a.state.LogListState.Logs {
+	for i, entry := range a.viewLogs() {
 		if logEntryKey(entry) == key {
 			return i, true
 		}
@@ -381,6 +478,82 @@ func (a *App) SetProjectLister(fn func() ([]string, error)) {
 	a.projectListFn = fn
 }
 
+// SetQueryCacheLimits configures result cache expiry and size. Call it before
+// SetQueryCacheEntries so expired entries are dropped on load. Zero disables
+// the corresponding limit.
+func (a *App) SetQueryCacheLimits(ttl time.Duration, maxEntries int) {
+	a.queryCacheTTL = ttl
+	a.queryCacheMax = maxEntries
+}
+
+// SetLogOrder sets the list order ("latest_top" or "latest_bottom").
+func (a *App) SetLogOrder(order string) {
+	if order != "latest_top" {
+		order = "latest_bottom"
+	}
+	a.logOrder = order
+	a.state.LogListState.Logs = a.sortLogsForDisplay(a.state.LogListState.Logs)
+}
+
+// SetTimelineBucket sets the timeline bucket width. Zero sizes buckets to
+// the time range.
+func (a *App) SetTimelineBucket(size time.Duration) {
+	a.timelineAuto = size <= 0
+	if !a.timelineAuto {
+		a.timelineBuilder.SetBucketSize(size)
+	}
+}
+
+// SetPreferencesPersistFn sets persistence callback for runtime UI toggles.
+func (a *App) SetPreferencesPersistFn(fn func(config.Preferences) error) {
+	a.persistPrefsFn = fn
+}
+
+// SetPreferences sets the stored preferences that runtime toggles are
+// merged into, usually those loaded from preferences.json
+func (a *App) SetPreferences(prefs config.Preferences) {
+	a.prefs = prefs
+}
+
+// SetProjectSettingsFn sets the function that resolves every config layer
+// for a project, given the current preferences. It runs when the project is
+// switched, so the project override block follows the switch.
+func (a *App) SetProjectSettingsFn(fn func(project string, prefs config.Preferences) (config.Config, error)) {
+	a.projectSettingsFn = fn
+}
+
+// applyProjectSettings applies the settings resolved for the current project
+func (a *App) applyProjectSettings() {
+	if a.projectSettingsFn == nil {
+		return
+	}
+	cfg, err := a.projectSettingsFn(a.state.CurrentProject, a.prefs)
+	if err != nil {
+		a.lastErr = "Project settings: " + err.Error()
+	}
+	a.SetTimezoneMode(cfg.Timezone)
+	a.SetLogOrder(cfg.LogOrder)
+	a.SetTimelineBucket(time.Duration(cfg.TimelineBucketSeconds) * time.Second)
+	a.SetQueryCacheLimits(time.Duration(cfg.QueryCacheTTLSeconds)*time.Second, cfg.QueryCacheMax)
+	resetThemeColors()
+	if err := ApplyColorOverrides(cfg.Colors); err != nil {
+		a.lastErr = "Project colors: " + err.Error()
+	}
+}
+
+// persistPreferences saves the stored preferences after update sets the
+// setting that changed. Values from flags, the environment or project
+// overrides are not written unless they are changed at runtime.
+func (a *App) persistPreferences(update func(*config.Preferences)) {
+	if a.persistPrefsFn == nil {
+		return
+	}
+	update(&a.prefs)
+	if err := a.persistPrefsFn(a.prefs); err != nil {
+		a.lastErr = "Save preferences failed: " + err.Error()
+	}
+}
+
 // Init initializes the app (required by Bubble Tea)
 func (a *App) Init() tea.Cmd {
 	if a.queryExec == nil {
@@ -389,14 +562,23 @@ func (a *App) Init() tea.Cmd {
 	if len(a.state.LogListState.Logs) > 0 {
 		return nil
 	}
-	if strings.TrimSpace(a.startupFilter) == "" {
+	// A seeded time range or severity is enough to run a startup query even
+	// without an explicit filter.
+	filter := a.buildEffectiveFilter(a.startupFilter)
+	if strings.TrimSpace(filter) == "" {
 		return nil
 	}
-	return a.executePrimaryQueryCmd(a.buildEffectiveFilter(a.startupFilter))
+	queryCmd := a.executePrimaryQueryCmd(filter)
+	if a.state.StreamState.Enabled {
+		return tea.Batch(queryCmd, a.startStreamCmd(a.activeTabID()))
+	}
+	return queryCmd
 }
 
 // Update handles events and state mutations
 func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Settle the timeline buckets here so View only reads them
+	defer a.syncTimelineBucket()
 	switch msg := msg.(type) {
 	// Handle window resize
 	case tea.WindowSizeMsg:
@@ -405,10 +587,23 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, nil
 
 	case queryResultMsg:
+		if msg.tabID != 0 && msg.tabID != a.activeTabID() {
+			return a, a.applyBackgroundResult(msg)
+		}
+		if msg.stream {
+			a.applyStreamResult(msg)
+			return a, nil
+		}
 		if msg.err != nil {
 			a.lastErr = fmt.Sprintf("Query error: %v", msg.err)
 		} else {
 			orderedLogs := a.sortLogsForDisplay(msg.logs)
+			// With a filtered view, offsets into the loaded list do not map to
+			// rows, so paging keeps the selected entry instead.
+			filteredKey := ""
+			if a.viewFiltered() {
+				filteredKey = a.selectedLogKey()
+			}
 			switch msg.mode {
 			case "append":
 				before := len(a.state.LogListState.Logs)
@@ -425,18 +620,26 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				a.lastErr = fmt.Sprintf("Loaded logs: +%d", len(a.state.LogListState.Logs)-before)
 			default:
 				a.state.LogListState.Logs = orderedLogs
+				filteredKey = ""
 				if a.logOrder == "latest_bottom" {
-					a.panes.LogList.scrollOffset = maxInt(0, len(a.state.LogListState.Logs)-1)
+					a.panes.LogList.scrollOffset = maxInt(0, len(a.viewLogs())-1)
 				} else {
 					a.panes.LogList.scrollOffset = 0
 				}
+				a.applyPendingSelection()
 				if msg.fromCache {
+					a.cacheHits++
 					a.lastErr = fmt.Sprintf("Query cache hit: %d logs", len(orderedLogs))
 				} else {
 					a.lastErr = fmt.Sprintf("Query complete: %d logs", len(orderedLogs))
 					a.storeQueryResultCache(msg.filter, orderedLogs)
 				}
 			}
+			if filteredKey != "" {
+				if idx, ok := a.findLogIndexByKey(filteredKey); ok {
+					a.panes.LogList.scrollOffset = idx
+				}
+			}
 		}
 		if msg.mode == "append" {
 			a.loadingOlder = false
@@ -451,6 +654,9 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return a, nil
 
+	case streamTickMsg:
+		return a, a.handleStreamTick(msg)
+
 	case editorResultMsg:
 		if msg.err != nil {
 			a.lastErr = fmt.Sprintf("Open editor failed: %v", msg.err)
@@ -459,6 +665,25 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return a, nil
 
+	case k8sDiscoveryMsg:
+		a.applyK8sDiscovery(msg)
+		return a, nil
+
+	case traceResultMsg:
+		a.applyTraceResult(msg)
+		return a, nil
+
+	case correlationResultMsg:
+		a.applyCorrelationResult(msg)
+		return a, nil
+
+	case compareResultMsg:
+		a.applyCompareResult(msg)
+		return a, nil
+
+	case entryContextResultMsg:
+		return a, a.applyEntryContextResult(msg)
+
 	case projectListMsg:
 		a.loadingProjects = false
 		if msg.err != nil {
@@ -481,7 +706,15 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if keyStr != "" {
 			// Uncomment to debug: fmt.Fprintf(os.Stderr, "Key pressed: %s\n", keyStr)
 		}
-		return a.handleKeyPress(msg)
+		if a.splitMode == splitOff || a.activeModalName != "none" {
+			return a.handleKeyPress(msg)
+		}
+		before := a.currentSelectedIndex()
+		model, cmd := a.handleKeyPress(msg)
+		if a.currentSelectedIndex() != before || keyStr == "w" || keyStr == "\\" {
+			a.syncSplitPartner()
+		}
+		return model, cmd
 	}
 
 	return a, nil
@@ -511,7 +744,7 @@ func (a *App) View() string {
 
 	headerLines := strings.Count(header, "\n")
 	footerLines := 3
-	if a.lastErr != "" {
+	if a.lastErr != "" || a.searchPromptActive() {
 		footerLines = 4
 	}
 	topBarLines := strings.Count(topBar, "\n")
@@ -523,7 +756,17 @@ func (a *App) View() string {
 	if logsHeight < 6 {
 		logsHeight = 6
 	}
-	logsPanel, windowStart, windowEnd := a.renderLogsPanel(logsHeight)
+	var logsPanel string
+	var windowStart, windowEnd int
+	renderLogs := a.renderLogsPanel
+	if a.splitPartnerIndex() >= 0 {
+		renderLogs = a.renderSplitLogs
+	}
+	if a.facetsOpen {
+		logsPanel, windowStart, windowEnd = a.renderFacetLayout(logsHeight, renderLogs)
+	} else {
+		logsPanel, windowStart, windowEnd = renderLogs(logsHeight)
+	}
 	footer := a.renderStatusPanel(windowStart, windowEnd)
 
 	var result strings.Builder
@@ -561,6 +804,27 @@ func (a *App) View() string {
 		output = a.renderCenteredPopup(output, a.renderKeyModePopup())
 	case "timezonePopup":
 		output = a.renderCenteredPopup(output, a.renderTimezonePopup())
+	case "prompt":
+		// The search prompt is drawn in the status panel so the list stays visible.
+		if !a.searchPromptActive() {
+			output = a.renderCenteredPopup(output, a.renderPromptPopup())
+		}
+	case "filterStack":
+		output = a.renderCenteredPopup(output, a.renderFilterStackPopup())
+	case "columns":
+		output = a.renderCenteredPopup(output, a.renderColumnsPopup())
+	case "k8s":
+		output = a.renderCenteredPopup(output, a.renderK8sPopup())
+	case "trace":
+		output = a.renderCenteredPopup(output, a.renderTracePopup())
+	case "correlation":
+		output = a.renderCenteredPopup(output, a.renderCorrelationPopup())
+	case "entryContext":
+		output = a.renderCenteredPopup(output, a.renderEntryContextPopup())
+	case "patterns":
+		output = a.renderCenteredPopup(output, a.renderPatternPopup())
+	case "compare":
+		output = a.renderCenteredPopup(output, a.renderComparePopup())
 	}
 
 	return a.fitToViewport(output)
@@ -578,6 +842,26 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return a.handleSeverityFilterInput(msg)
 	case "export":
 		return a.handleExportInput(msg)
+	case "prompt":
+		return a.handlePromptInput(msg)
+	case "filterStack":
+		return a.handleFilterStackInput(msg)
+	case "facets":
+		return a.handleFacetInput(msg)
+	case "columns":
+		return a.handleColumnsInput(msg)
+	case "k8s":
+		return a.handleK8sInput(msg)
+	case "trace":
+		return a.handleTraceInput(msg)
+	case "correlation":
+		return a.handleCorrelationInput(msg)
+	case "entryContext":
+		return a.handleEntryContextInput(msg)
+	case "patterns":
+		return a.handlePatternInput(msg)
+	case "compare":
+		return a.handleCompareInput(msg)
 	case "details":
 		switch msg.String() {
 		case "esc", "enter", "ctrl+d":
@@ -609,6 +893,20 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			a.cycleDetailViewMode()
 		case "y":
 			a.copySelectedDetailNode()
+		case "=":
+			return a, a.filterBySelectedNode(treeFilterInclude)
+		case "!":
+			return a, a.filterBySelectedNode(treeFilterExclude)
+		case "*":
+			return a, a.filterBySelectedNode(treeFilterExists)
+		case "p":
+			return a, a.pivotAuditField(auditPrincipalField)
+		case "r":
+			return a, a.pivotAuditField(auditResourceField)
+		case "F":
+			return a, a.followSelectedValue()
+		case "#":
+			a.plotSelectedNode()
 		case "Y":
 			a.copyDetailPayload()
 		case "ctrl+o":
@@ -705,6 +1003,8 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				a.lastErr = "Key mode: standard"
 			}
 			a.activeModalName = "none"
+			vimMode := a.vimMode
+			a.persistPreferences(func(p *config.Preferences) { p.VimMode = &vimMode })
 		}
 		return a, nil
 	case "timezonePopup":
@@ -732,6 +1032,7 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				a.lastErr = "Timezone: local"
 			}
 			a.activeModalName = "none"
+			a.persistPreferences(func(p *config.Preferences) { p.Timezone = a.timezoneMode })
 		}
 		return a, nil
 	case "help":
@@ -746,6 +1047,12 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return a, nil
 	}
 
+	if a.timelineFocused {
+		if cmd, ok := a.handleTimelineInput(msg); ok {
+			return a, cmd
+		}
+	}
+
 	// Normal app keyboard handling when no modal is active
 	switch msg.String() {
 	// Quit
@@ -847,6 +1154,31 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return a, nil
 	case "m":
 		a.state.StreamState.Enabled = !a.state.StreamState.Enabled
+		if !a.state.StreamState.Enabled {
+			a.lastErr = "Streaming: OFF"
+			return a, nil
+		}
+		a.lastErr = fmt.Sprintf("Streaming: ON (every %s)", a.streamInterval())
+		return a, a.startStreamCmd(a.activeTabID())
+	case "ctrl+t":
+		return a, a.openNewTab()
+	case "ctrl+w":
+		a.closeActiveTab()
+		return a, nil
+	case "]":
+		a.switchTab(a.activeTab + 1)
+		return a, nil
+	case "[":
+		a.switchTab(a.activeTab - 1)
+		return a, nil
+	case "\\":
+		return a, a.cycleSplitMode()
+	case "w":
+		a.swapSplitFocus()
+		return a, nil
+	case "f2":
+		a.prompt.Open("renameTab", "RENAME TAB", a.tabs[a.activeTab].name)
+		a.activeModalName = "prompt"
 		return a, nil
 	case "f6":
 		if a.vimMode {
@@ -866,6 +1198,7 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return a, nil
 	case "f8":
 		a.toggleLogOrder()
+		a.persistPreferences(func(p *config.Preferences) { p.LogOrder = a.logOrder })
 		return a, nil
 	case "ctrl+a":
 		a.autoLoadAll = !a.autoLoadAll
@@ -886,17 +1219,74 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		a.helpModal.SetVisible(true)
 		return a, nil
 	case "esc":
+		if a.activeModalName == "none" && a.currentSearch().active() {
+			a.clearSearch()
+		}
 		a.activeModalName = "none"
 		a.state.UIState.ActiveModal = "none"
 		return a, nil
+	case "/":
+		a.openSearchPrompt()
+		return a, nil
+	case "F":
+		a.openFilterStack()
+		return a, nil
+	case "b":
+		a.toggleFacets()
+		return a, nil
+	case "C":
+		a.openColumnEditor()
+		return a, nil
+	case "v":
+		a.cycleRowTemplate()
+		return a, nil
+	case "H":
+		a.toggleHTTPMode()
+		return a, nil
+	case "O":
+		a.toggleLatencySort()
+		return a, nil
+	case "K":
+		a.openK8sNavigator()
+		return a, nil
+	case "T":
+		return a, a.openTraceView()
+	case "x":
+		return a, a.openEntryContext()
+	case "M":
+		a.openPatternView()
+		return a, nil
+	case "D":
+		a.openCompare()
+		return a, nil
+	case "a":
+		a.jumpToNextAnomaly()
+		return a, nil
+	case "V":
+		a.toggleTimelineChart()
+		return a, nil
+	case "#":
+		a.openMetricPrompt()
+		return a, nil
+	case "tab":
+		a.focusTimeline()
+		return a, nil
+	case "A":
+		return a, a.zoomToAnomaly()
+	case "n":
+		a.jumpToMatch(1)
+		return a, nil
+	case "N":
+		a.jumpToMatch(-1)
+		return a, nil
 	case "enter":
-		if len(a.state.LogListState.Logs) > 0 {
+		if len(a.viewLogs()) > 0 {
 			a.activeModalName = "details"
 			a.detailScroll = 0
 		}
 		return a, nil
 	case "ctrl+d":
-		if len(a.state.LogListState.Logs) > 0 {
+		if len(a.viewLogs()) > 0 {
 			if a.activeModalName == "details" {
 				a.activeModalName = "none"
 			} else {
@@ -906,13 +1296,13 @@ func (a *App) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return a, nil
 	case "ctrl+p":
-		if len(a.state.LogListState.Logs) > 0 {
+		if len(a.viewLogs()) > 0 {
 			a.activeModalName = "detailPopup"
 			a.resetDetailPopupState()
 		}
 		return a, nil
 	case "ctrl+o":
-		if len(a.state.LogListState.Logs) > 0 {
+		if len(a.viewLogs()) > 0 {
 			return a, a.openSelectedLogInEditorCmd()
 		}
 		return a, nil
@@ -1098,12 +1488,13 @@ func (a *App) handleScrollUp() (tea.Model, tea.Cmd) {
 }
 
 func (a *App) jumpToLastLogEntry() {
-	if len(a.state.LogListState.Logs) == 0 {
+	logs := a.viewLogs()
+	if len(logs) == 0 {
 		a.panes.LogList.scrollOffset = 0
 		return
 	}
 	// Keep selected row on the true last item, not at window start.
-	a.panes.LogList.scrollOffset = len(a.state.LogListState.Logs) - 1
+	a.panes.LogList.scrollOffset = len(logs) - 1
 }
 
 // handleTimePickerInput handles input when time picker modal is active
@@ -1465,18 +1856,13 @@ func (a *App) renderExportModal() string {
 }
 
 func (a *App) renderDetailsModal() string {
-	if len(a.state.LogListState.Logs) == 0 {
+	logs := a.viewLogs()
+	if len(logs) == 0 {
 		return ""
 	}
 
-	idx := a.panes.LogList.scrollOffset
-	if idx < 0 {
-		idx = 0
-	}
-	if idx >= len(a.state.LogListState.Logs) {
-		idx = len(a.state.LogListState.Logs) - 1
-	}
-	entry := a.state.LogListState.Logs[idx]
+	idx := a.currentSelectedIndex()
+	entry := logs[idx]
 	details := a.formatter.FormatCompact(entry)
 
 	lines := strings.Split(details, "\n")
@@ -1487,7 +1873,7 @@ func (a *App) renderDetailsModal() string {
 
 	var sb strings.Builder
 	sb.WriteString("┏━━ LOG DETAILS " + strings.Repeat("━", a.width-17) + "\n")
-	sb.WriteString(fmt.Sprintf("┃ Entry %d/%d\n", idx+1, len(a.state.LogListState.Logs)))
+	sb.WriteString(fmt.Sprintf("┃ Entry %d/%d\n", idx+1, len(logs)))
 	sb.WriteString("┣" + strings.Repeat("━", a.width-1) + "\n")
 	for _, line := range lines {
 		if len(line) > a.width-4 {
@@ -1502,10 +1888,13 @@ func (a *App) renderDetailsModal() string {
 }
 
 func (a *App) renderGraphPanel() string {
-	points := a.timelineBuilder.BuildTimeline(a.state.LogListState.Logs)
-	spark := a.timelineBuilder.RenderSparkline(points, a.width-24)
+	points := a.timelinePoints()
+	spark := a.timelineBuilder.RenderSparkline(points, a.timelineColumns())
+	anomalies := a.timelineBuilder.DetectAnomalies(points)
 	if spark == "" {
 		spark = "No timeline data"
+	} else {
+		spark = a.decorateSparkline(spark, sparklineStep(len(points), a.timelineColumns()), anomalies)
 	}
 	dist := a.timelineBuilder.BuildSeverityDistribution(a.state.LogListState.Logs)
 	crit := dist["ERROR"] + dist["CRITICAL"] + dist["ALERT"] + dist["EMERGENCY"]
@@ -1515,53 +1904,109 @@ func (a *App) renderGraphPanel() string {
 	if len(a.state.LogListState.Logs) > 0 {
 		oldest := a.oldestLoadedTimestamp()
 		newest := a.newestLoadedTimestamp()
-		rangeText = fmt.Sprintf("Range: %s -> %s", a.displayTime(oldest).Format("2006-01-02 15:04:05"), a.displayTime(newest).Format("2006-01-02 15:04:05"))
+		rangeText = fmt.Sprintf("Range: %s -> %s  (%s buckets)", a.displayTime(oldest).Format("2006-01-02 15:04:05"), a.displayTime(newest).Format("2006-01-02 15:04:05"), formatBucketSize(a.timelineBuilder.GetBucketSize()))
 	}
 
 	var sb strings.Builder
 	sb.WriteString(a.panelSeparator('─'))
-	sb.WriteString(a.panelLine(fmt.Sprintf("%s %s", lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorGCPBlueLight)).Render("Timeline"), spark)))
-	sb.WriteString(a.panelLine(fmt.Sprintf("%s Critical:%d  Warning:%d  Info/Other:%d",
-		lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorGCPBlue)).Render("Severity Mix"),
-		crit, warn, info)))
-	sb.WriteString(a.panelLine(lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle)).Render(rangeText)))
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorGCPBlueLight))
+	if a.timelineFocused {
+		title = a.selectedRowStyle()
+	}
+	if a.timelineBuilder.GetChartMode() != ChartSparkline && len(points) > 0 {
+		var chart TimelineChart
+		var series []MetricSeries
+		if a.timelineBuilder.GetChartMode() == ChartMetrics {
+			series = a.metricSeries()
+			chart = a.timelineBuilder.RenderMetricChart(points, series, a.panelInnerWidth(), a.timelineChartRows(), a.displayLocation())
+		} else {
+			chart = a.timelineBuilder.RenderStackedChart(points, a.panelInnerWidth(), a.timelineChartRows(), a.displayLocation())
+		}
+		sb.WriteString(a.panelLine(fmt.Sprintf("%s %s", title.Render("Timeline"), chart.Legend(a.panelInnerWidth()-len("Timeline ")))))
+		for _, row := range chart.Rows {
+			sb.WriteString(a.panelLine(row))
+		}
+		if marks := a.chartMarks(chart, len(points), anomalies); marks != "" {
+			sb.WriteString(a.panelLine(marks))
+		}
+		sb.WriteString(a.panelLine(chart.Axis))
+		if a.timelineFocused {
+			sb.WriteString(a.panelLine(truncate(a.timelineCursorLine(points), a.panelInnerWidth())))
+			for _, line := range a.metricTooltips(points, series) {
+				sb.WriteString(a.panelLine(line))
+			}
+		}
+	} else {
+		sb.WriteString(a.panelLine(fmt.Sprintf("%s %s", title.Render("Timeline"), spark)))
+		if a.timelineFocused {
+			sb.WriteString(a.panelLine(truncate(a.timelineCursorLine(points), a.panelInnerWidth())))
+		}
+		sb.WriteString(a.panelLine(fmt.Sprintf("%s Critical:%d  Warning:%d  Info/Other:%d",
+			lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorGCPBlue)).Render("Severity Mix"),
+			crit, warn, info)))
+	}
+	if a.httpMode {
+		sb.WriteString(a.panelLine(a.renderHTTPStats()))
+	}
+	if len(anomalies) > 0 {
+		sb.WriteString(a.panelLine(lipgloss.NewStyle().Foreground(lipgloss.Color(colorGCPError)).Render(truncate(a.anomalySummary(anomalies), a.panelInnerWidth()))))
+	}
+	sb.WriteString(a.panelLine(lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle)).Render(truncate(rangeText, a.panelInnerWidth()))))
 	return sb.String()
 }
 
 func (a *App) renderLogsPanel(height int) (string, int, int) {
-	var sb strings.Builder
 	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorGCPBlueLight)).Render("LOG STREAM")
+	return a.renderLogsPanelTitled(height, title)
+}
+
+func (a *App) renderLogsPanelTitled(height int, title string) (string, int, int) {
+	var sb strings.Builder
 	sb.WriteString(a.panelTop())
-	sb.WriteString(a.panelLine(fmt.Sprintf("%s (%d)", title, len(a.state.LogListState.Logs))))
-	sb.WriteString(a.panelLine(lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle)).Render("IDX   TIMESTAMP           SEV      MESSAGE")))
+	logs := a.viewLogs()
+	count := fmt.Sprintf("(%d)", len(logs))
+	if a.viewFiltered() {
+		count = "(" + a.shownCountLabel() + ")"
+	}
+	sb.WriteString(a.panelLine(fmt.Sprintf("%s %s", title, count)))
+	header := a.logListHeader()
+	if name := a.formatter.RowTemplateName(); name != "" && !a.httpMode {
+		header = "IDX   " + strings.ToUpper(name)
+	}
+	sb.WriteString(a.panelLine(lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle)).Render(header)))
 
 	visibleRows := maxInt(1, height-1)
 	start := a.currentWindowStart()
-	maxStart := maxInt(0, len(a.state.LogListState.Logs)-visibleRows)
+	maxStart := maxInt(0, len(logs)-visibleRows)
 	if start > maxStart {
 		start = maxStart
 	}
 	end := start + visibleRows
-	if end > len(a.state.LogListState.Logs) {
-		end = len(a.state.LogListState.Logs)
+	if end > len(logs) {
+		end = len(logs)
 	}
 
 	for i := start; i < end; i++ {
-		log := a.state.LogListState.Logs[i]
+		log := logs[i]
+		if a.formatter.RowTemplateName() != "" && !a.httpMode {
+			sb.WriteString(a.panelLine(a.renderTemplateRow(log, i)))
+			continue
+		}
 		timePart := a.displayTime(log.Timestamp).Format("2006-01-02 15:04:05")
-		sevBadge := a.styleSeverityBadge(log.Severity)
-		msgMax := maxInt(12, a.width-47)
-		msg := log.Message
-		if len(msg) > msgMax {
-			msg = msg[:msgMax-3] + "..."
+		sevBadge := a.entrySeverityBadge(log)
+		msgMax := maxInt(12, a.width-logRowPrefixWidth-a.columnsWidth())
+		msg := listMessage(log)
+		rowStyle := a.severityRowStyle(log.Severity)
+		if a.httpMode {
+			msg = httpMessage(log)
+			rowStyle = httpStatusStyle(log)
 		}
+		msg = truncate(msg, msgMax)
 
-		row := fmt.Sprintf("%-4d  %s  %s  %s", i+1, timePart, sevBadge, msg)
 		if i == a.currentSelectedIndex() {
-			row = a.styleSelectedRow(row)
-		} else {
-			row = a.styleSeverityRow(log.Severity, row)
+			rowStyle = a.selectedRowStyle()
 		}
+		row := rowStyle.Render(fmt.Sprintf("%-4d  %s  %s  %s", i+1, timePart, sevBadge, a.columnCells(log))) + a.highlightSearch(msg, rowStyle)
 		sb.WriteString(a.panelLine(row))
 	}
 
@@ -1571,6 +2016,18 @@ func (a *App) renderLogsPanel(height int) (string, int, int) {
 	return sb.String(), start + 1, end
 }
 
+// renderTemplateRow renders row i of the log list with the selected row
+// template. The selected row drops the template colors so the highlight
+// stays readable.
+func (a *App) renderTemplateRow(log models.LogEntry, i int) string {
+	selected := i == a.currentSelectedIndex()
+	line := a.formatter.formatRowTemplate(log, maxInt(12, a.width-10), !selected)
+	if selected {
+		return a.selectedRowStyle().Render(fmt.Sprintf("%-4d  %s", i+1, line))
+	}
+	return a.severityRowStyle(log.Severity).Render(fmt.Sprintf("%-4d  ", i+1)) + line
+}
+
 func (a *App) renderQueryPanel(query string, editing bool) string {
 	var sb strings.Builder
 	title := "QUERY EDITOR"
@@ -1601,7 +2058,7 @@ func (a *App) renderQueryPanel(query string, editing bool) string {
 func (a *App) renderStatusPanel(windowStart, windowEnd int) string {
 	var sb strings.Builder
 	sb.WriteString(a.panelSeparator('━'))
-	total := len(a.state.LogListState.Logs)
+	total := len(a.viewLogs())
 	if total == 0 {
 		windowStart = 0
 		windowEnd = 0
@@ -1619,9 +2076,15 @@ func (a *App) renderStatusPanel(windowStart, windowEnd int) string {
 		keyMode = "vim"
 	}
 	tzMode := strings.ToUpper(a.timezoneMode)
-	sb.WriteString(a.panelLine(fmt.Sprintf("%d-%d/%d  %s  sev:%s  load:%s  stream:%s  keys:%s  tz:%s  order:%s  cache:%d  ?",
-		windowStart, windowEnd, total, a.getTimeRangeLabel(), a.getSeveritySummary(), loadMode, streamMode, keyMode, tzMode, a.logOrderLabel(), len(a.cachedQueryRecords()))))
-	if a.lastErr != "" {
+	search := ""
+	if status := a.searchStatus(); status != "" {
+		search = status + "  "
+	}
+	sb.WriteString(a.panelLine(fmt.Sprintf("%d-%d/%d  %s%s  sev:%s  load:%s  stream:%s  keys:%s  tz:%s  order:%s  cache:%d hits:%d  ?",
+		windowStart, windowEnd, total, search, a.getTimeRangeLabel(), a.getSeveritySummary(), loadMode, streamMode, keyMode, tzMode, a.logOrderLabel(), len(a.cachedQueryRecords()), a.cacheHits)))
+	if a.searchPromptActive() {
+		sb.WriteString(a.panelLine(a.renderSearchBar()))
+	} else if a.lastErr != "" {
 		errLine := a.lastErr
 		if len(errLine) > a.width-4 {
 			errLine = errLine[:a.width-7] + "..."
@@ -1722,6 +2185,9 @@ func (a *App) renderTopBar() string {
 	if lipgloss.Width(line) < a.width {
 		line += strings.Repeat(" ", a.width-lipgloss.Width(line))
 	}
+	if len(a.tabs) > 1 {
+		return line + "\n" + a.renderTabStrip() + "\n"
+	}
 	return line + "\n"
 }
 
@@ -1863,12 +2329,13 @@ func sanitizeFilterForExecution(filter string) string {
 }
 
 func (a *App) renderDetailsPanel() string {
-	if len(a.state.LogListState.Logs) == 0 {
+	logs := a.viewLogs()
+	if len(logs) == 0 {
 		return ""
 	}
 
 	idx := a.currentSelectedIndex()
-	entry := a.state.LogListState.Logs[idx]
+	entry := logs[idx]
 	details := a.formatter.FormatLogDetails(entry)
 	lines := strings.Split(details, "\n")
 	maxLines := 10
@@ -1878,7 +2345,7 @@ func (a *App) renderDetailsPanel() string {
 
 	var sb strings.Builder
 	sb.WriteString(a.panelSeparator('─'))
-	sb.WriteString(a.panelLine(fmt.Sprintf("%s %d/%d", lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("213")).Render("DETAILS"), idx+1, len(a.state.LogListState.Logs))))
+	sb.WriteString(a.panelLine(fmt.Sprintf("%s %d/%d", lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("213")).Render("DETAILS"), idx+1, len(logs))))
 	for _, line := range lines {
 		if len(line) > a.width-4 {
 			line = line[:a.width-7] + "..."
@@ -1912,7 +2379,7 @@ func (a *App) renderDetailPopup() string {
 
 	var sb strings.Builder
 	sb.WriteString(a.popupTop(popupWidth, "FULL LOG POPUP"))
-	sb.WriteString(a.popupLine(popupWidth, fmt.Sprintf("Entry %d/%d  Mode:%s  Scroll %d/%d", a.currentSelectedIndex()+1, len(a.state.LogListState.Logs), a.detailViewMode, start+1, maxInt(1, len(lines)))))
+	sb.WriteString(a.popupLine(popupWidth, fmt.Sprintf("Entry %d/%d  Mode:%s  Scroll %d/%d", a.currentSelectedIndex()+1, len(a.viewLogs()), a.detailViewMode, start+1, maxInt(1, len(lines)))))
 	sb.WriteString(a.popupSeparator(popupWidth, '━'))
 	for i := start; i < end; i++ {
 		line := lines[i]
@@ -1920,7 +2387,7 @@ func (a *App) renderDetailPopup() string {
 		if i == selectedIndex {
 			prefix = "▶ "
 		}
-		sb.WriteString(a.popupLine(popupWidth, prefix+line))
+		sb.WriteString(a.popupLine(popupWidth, prefix+a.highlightSearch(line, lipgloss.NewStyle())))
 	}
 	for i := end; i < start+visibleHeight; i++ {
 		sb.WriteString(a.popupLine(popupWidth, ""))
@@ -1930,8 +2397,11 @@ func (a *App) renderDetailPopup() string {
 		meta := fmt.Sprintf("selected:%s (%s)", selectedPath, selectedType)
 		sb.WriteString(a.popupLine(popupWidth, meta))
 	}
-	sb.WriteString(a.popupLine(popupWidth, "j/k:move  h/l:collapse/expand  z/Z:collapse/expand all  v/tab:mode  y/Y:copy  Ctrl+E:open payload"))
-	sb.WriteString(a.popupLine(popupWidth, "Ctrl+O:open entry  Ctrl+L:open list(JSON)  Ctrl+Shift+L/Alt+L:open list(CSV)  Esc/Ctrl+P:close"))
+	if _, ok := parseAuditLog(*entry); ok {
+		sb.WriteString(a.popupLine(popupWidth, "Audit log  p:query by principal  r:query by resource"))
+	}
+	sb.WriteString(a.popupLine(popupWidth, "j/k:move  h/l:collapse/expand  z/Z:collapse/expand all  v/tab:mode  y/Y:copy  =/!/*:filter in/out/exists  F:follow value"))
+	sb.WriteString(a.popupLine(popupWidth, "Ctrl+E:open payload  Ctrl+O:open entry  Ctrl+L:open list(JSON)  Ctrl+Shift+L/Alt+L:open list(CSV)  Esc/Ctrl+P:close"))
 	sb.WriteString(a.popupBottom(popupWidth, '━'))
 	return sb.String()
 }
@@ -2148,6 +2618,9 @@ func (a *App) applySelectedQueryLibraryEntry() (tea.Model, tea.Cmd) {
 	}
 	selected := a.queryLibrary[a.queryLibraryCursor]
 	a.state.CurrentQuery.Filter = selected.Filter
+	if len(selected.Columns) > 0 {
+		a.setColumns(selected.Columns)
+	}
 	if a.previousModalName == "query" {
 		a.activeModalName = "query"
 		a.queryModal.SetInput(selected.Filter)
@@ -2207,7 +2680,7 @@ func (a *App) renderCenteredPopup(base, popup string) string {
 		return base
 	}
 
-	header := ansiEscapeRegex.ReplaceAllString(strings.TrimSuffix(a.renderTopBar(), "\n"), "")
+	header := ansiEscapeRegex.ReplaceAllString(strings.SplitN(a.renderTopBar(), "\n", 2)[0], "")
 	headerWidth := lipgloss.Width(header)
 	if headerWidth < a.width {
 		header += strings.Repeat(" ", a.width-headerWidth)
@@ -2254,8 +2727,10 @@ func (a *App) runQueryCmd(filter, mode string) tea.Cmd {
 }
 
 func (a *App) runQueryCmdWithAnchor(filter, mode string, preserveAnchor bool, anchorOffset int) tea.Cmd {
+	tabID := a.activeTabID()
+	project := a.state.CurrentProject
 	return func() tea.Msg {
-		logs, err := a.queryExec(filter)
+		logs, err := a.execQuery(project, filter)
 		return queryResultMsg{
 			filter:         filter,
 			logs:           logs,
@@ -2263,10 +2738,20 @@ func (a *App) runQueryCmdWithAnchor(filter, mode string, preserveAnchor bool, an
 			mode:           mode,
 			preserveAnchor: preserveAnchor,
 			anchorOffset:   anchorOffset,
+			tabID:          tabID,
 		}
 	}
 }
 
+// execQuery runs filter against project, falling back to the project-less
+// executor when no project-aware one is configured.
+func (a *App) execQuery(project, filter string) ([]models.LogEntry, error) {
+	if a.projectQueryExec != nil {
+		return a.projectQueryExec(project, filter)
+	}
+	return a.queryExec(filter)
+}
+
 func (a *App) runProjectListCmd() tea.Cmd {
 	return func() tea.Msg {
 		if a.projectListFn == nil {
@@ -2282,6 +2767,7 @@ func (a *App) executePrimaryQueryCmd(filter string) tea.Cmd {
 	if !a.bypassNextCache {
 		if logs, ok := a.lookupQueryResultCache(filter); ok {
 			a.state.LogListState.IsLoading = false
+			tabID := a.activeTabID()
 			return func() tea.Msg {
 				return queryResultMsg{
 					filter:    filter,
@@ -2289,6 +2775,7 @@ func (a *App) executePrimaryQueryCmd(filter string) tea.Cmd {
 					err:       nil,
 					mode:      "replace",
 					fromCache: true,
+					tabID:     tabID,
 				}
 			}
 		}
@@ -2301,43 +2788,19 @@ func (a *App) executePrimaryQueryCmd(filter string) tea.Cmd {
 }
 
 func (a *App) runLoadAllCmd(baseFilter string) tea.Cmd {
+	tabID := a.activeTabID()
+	project := a.state.CurrentProject
 	return func() tea.Msg {
 		if a.queryExec == nil {
-			return queryResultMsg{filter: baseFilter, logs: []models.LogEntry{}, err: fmt.Errorf("query executor not configured"), mode: "replace"}
+			return queryResultMsg{filter: baseFilter, logs: []models.LogEntry{}, err: fmt.Errorf("query executor not configured"), mode: "replace", tabID: tabID}
 		}
 
-		firstPage, err := a.queryExec(baseFilter)
+		all, err := a.loadAllPages(project, baseFilter)
 		if err != nil {
-			return queryResultMsg{filter: baseFilter, logs: []models.LogEntry{}, err: err, mode: "replace"}
-		}
-
-		all := mergeUniqueLogs([]models.LogEntry{}, firstPage, false)
-		const maxPages = 200
-		for page := 0; page < maxPages; page++ {
-			if len(all) == 0 {
-				break
-			}
-			oldest := all[len(all)-1].Timestamp
-			timeClause := fmt.Sprintf("timestamp<%q", oldest.Format(time.RFC3339))
-			nextFilter := baseFilter
-			if strings.TrimSpace(nextFilter) == "" {
-				nextFilter = timeClause
-			} else {
-				nextFilter = fmt.Sprintf("(%s) AND %s", nextFilter, timeClause)
-			}
-
-			nextPage, err := a.queryExec(nextFilter)
-			if err != nil {
-				return queryResultMsg{filter: baseFilter, logs: all, err: err, mode: "replace"}
-			}
-			if len(nextPage) == 0 {
-				break
-			}
-			before := len(all)
-			all = mergeUniqueLogs(all, nextPage, false)
-			if len(all) == before {
-				break
+			if all == nil {
+				all = []models.LogEntry{}
 			}
+			return queryResultMsg{filter: baseFilter, logs: all, err: err, mode: "replace", tabID: tabID}
 		}
 
 		return queryResultMsg{
@@ -2345,11 +2808,73 @@ func (a *App) runLoadAllCmd(baseFilter string) tea.Cmd {
 			logs:   all,
 			err:    nil,
 			mode:   "replace",
+			tabID:  tabID,
+		}
+	}
+}
+
+// loadAllPages runs baseFilter against project and keeps querying for
+// entries older than the oldest one loaded until no new entries arrive.
+// On error it returns the entries loaded so far.
+func (a *App) loadAllPages(project, baseFilter string) ([]models.LogEntry, error) {
+	firstPage, err := a.execQuery(project, baseFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	all := mergeUniqueLogs([]models.LogEntry{}, firstPage, false)
+	const maxPages = 200
+	for page := 0; page < maxPages; page++ {
+		if len(all) == 0 {
+			break
+		}
+		oldest := all[len(all)-1].Timestamp
+		// Entries share seconds; a page cut at the second would skip the
+		// rest of the oldest one
+		timeClause := fmt.Sprintf("timestamp<%q", oldest.Format(time.RFC3339Nano))
+		nextFilter := baseFilter
+		if strings.TrimSpace(nextFilter) == "" {
+			nextFilter = timeClause
+		} else {
+			nextFilter = fmt.Sprintf("(%s) AND %s", nextFilter, timeClause)
+		}
+
+		nextPage, err := a.execQuery(project, nextFilter)
+		if err != nil {
+			return all, err
+		}
+		if len(nextPage) == 0 {
+			break
+		}
+		before := len(all)
+		all = mergeUniqueLogs(all, nextPage, false)
+		if len(all) == before {
+			break
 		}
 	}
+	return all, nil
 }
 
 func (a *App) buildEffectiveFilter(baseFilter string) string {
+	return a.buildFilterInRange(baseFilter, a.state.FilterState.TimeRange)
+}
+
+// buildFilterInRange is buildEffectiveFilter over another time range
+func (a *App) buildFilterInRange(baseFilter string, tr models.TimeRange) string {
+	return a.buildFilterWithTime(baseFilter, func(builder *query.Builder) {
+		builder.AddTimeRange(tr)
+	})
+}
+
+// buildFilterInWindow is buildEffectiveFilter over the half-open window
+// [tr.Start, tr.End)
+func (a *App) buildFilterInWindow(baseFilter string, tr models.TimeRange) string {
+	return a.buildFilterWithTime(baseFilter, func(builder *query.Builder) {
+		builder.AddTimeWindow(tr)
+	})
+}
+
+func (a *App) buildFilterWithTime(baseFilter string, addTime func(*query.Builder)) string {
 	base := sanitizeFilterForExecution(baseFilter)
 	if base == "" {
 		base = sanitizeFilterForExecution(a.state.CurrentQuery.Filter)
@@ -2359,18 +2884,13 @@ func (a *App) buildEffectiveFilter(baseFilter string) string {
 	if base != "" {
 		builder.AddCustomFilter(base)
 	}
-
-	if !a.state.FilterState.TimeRange.Start.IsZero() && !a.state.FilterState.TimeRange.End.IsZero() {
-		builder.AddTimeRange(a.state.FilterState.TimeRange)
+	for _, clause := range query.CustomFilterClauses(a.state.FilterState.CustomFilters) {
+		builder.AddCustomFilter(clause)
 	}
 
-	sf := a.state.FilterState.Severity
-	if sf.Mode == "range" && sf.MinLevel != "" {
-		builder.AddSeverity(sf)
-	}
-	if sf.Mode == "individual" && len(sf.Levels) > 0 {
-		builder.AddSeverity(sf)
-	}
+	addTime(builder)
+
+	a.addSeverityFilter(builder)
 
 	return builder.Build()
 }
@@ -2524,60 +3044,79 @@ func (a *App) getSeveritySummary() string {
 }
 
 func (a *App) styleSeverityRow(severity, line string) string {
+	return a.severityRowStyle(severity).Render(line)
+}
+
+func (a *App) severityRowStyle(severity string) lipgloss.Style {
+	return severityStyle(severity)
+}
+
+// severityStyle colors text by severity level
+func severityStyle(severity string) lipgloss.Style {
 	switch severity {
 	case models.SeverityError, models.SeverityCritical, models.SeverityAlert, models.SeverityEmergency:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color(colorGCPError)).Render(line)
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(colorGCPError))
 	case models.SeverityWarning:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color(colorGCPWarn)).Render(line)
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(colorGCPWarn))
 	case models.SeverityInfo, models.SeverityNotice:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color(colorGCPBlueLight)).Render(line)
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(colorGCPBlueLight))
 	case models.SeverityDebug, models.SeverityDefault:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle)).Render(line)
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle))
 	default:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralText)).Render(line)
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralText))
 	}
 }
 
 func (a *App) styleSeverityBadge(severity string) string {
+	style, text := severityBadge(severity)
+	return style.Padding(0, 1).Render(text)
+}
+
+func severityBadge(severity string) (lipgloss.Style, string) {
+	badge := lipgloss.NewStyle().Bold(true)
 	switch severity {
 	case models.SeverityError, models.SeverityCritical, models.SeverityAlert, models.SeverityEmergency:
-		return lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorBadgeTextLite)).Background(lipgloss.Color(colorGCPError)).Padding(0, 1).Render("ERR")
+		return badge.Foreground(lipgloss.Color(colorBadgeTextLite)).Background(lipgloss.Color(colorGCPError)), "ERR"
 	case models.SeverityWarning:
-		return lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorBadgeTextDark)).Background(lipgloss.Color(colorGCPWarn)).Padding(0, 1).Render("WRN")
+		return badge.Foreground(lipgloss.Color(colorBadgeTextDark)).Background(lipgloss.Color(colorGCPWarn)), "WRN"
 	case models.SeverityInfo, models.SeverityNotice:
-		return lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorBadgeTextLite)).Background(lipgloss.Color(colorGCPBlue)).Padding(0, 1).Render("INF")
+		return badge.Foreground(lipgloss.Color(colorBadgeTextLite)).Background(lipgloss.Color(colorGCPBlue)), "INF"
 	case models.SeverityDebug, models.SeverityDefault:
-		return lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorBadgeTextDark)).Background(lipgloss.Color("248")).Padding(0, 1).Render("DBG")
+		return badge.Foreground(lipgloss.Color(colorBadgeTextDark)).Background(lipgloss.Color("248")), "DBG"
 	default:
-		return lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorBadgeTextDark)).Background(lipgloss.Color("250")).Padding(0, 1).Render("LOG")
+		return badge.Foreground(lipgloss.Color(colorBadgeTextDark)).Background(lipgloss.Color("250")), "LOG"
 	}
 }
 
 func (a *App) styleSelectedRow(line string) string {
+	return a.selectedRowStyle().Render(line)
+}
+
+func (a *App) selectedRowStyle() lipgloss.Style {
 	return lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color(colorSelectionFG)).
-		Background(lipgloss.Color(colorSelectionBG)).
-		Render(line)
+		Background(lipgloss.Color(colorSelectionBG))
 }
 
 func (a *App) currentSelectedIndex() int {
-	if len(a.state.LogListState.Logs) == 0 {
+	logs := a.viewLogs()
+	if len(logs) == 0 {
 		return 0
 	}
 	idx := a.panes.LogList.scrollOffset
 	if idx < 0 {
 		return 0
 	}
-	if idx >= len(a.state.LogListState.Logs) {
-		return len(a.state.LogListState.Logs) - 1
+	if idx >= len(logs) {
+		return len(logs) - 1
 	}
 	return idx
 }
 
 func (a *App) maxWindowStart() int {
 	rows := a.logViewportRows()
-	return maxInt(0, len(a.state.LogListState.Logs)-rows)
+	return maxInt(0, len(a.viewLogs())-rows)
 }
 
 func (a *App) currentWindowStart() int {
@@ -2606,7 +3145,7 @@ func (a *App) logViewportRows() int {
 		detailsLines = strings.Count(a.renderDetailsPanel(), "\n")
 	}
 	footerLines := 3
-	if a.lastErr != "" {
+	if a.lastErr != "" || a.searchPromptActive() {
 		footerLines = 4
 	}
 	overhead := strings.Count(topBar, "\n") + strings.Count(header, "\n") + strings.Count(timeline, "\n") + detailsLines + footerLines
@@ -2615,18 +3154,22 @@ func (a *App) logViewportRows() int {
 	if logsHeight < 6 {
 		logsHeight = 6
 	}
+	if a.splitMode == splitStacked && a.splitPartnerIndex() >= 0 {
+		logsHeight = splitPaneHeight(logsHeight)
+	}
 	return maxInt(1, logsHeight-1)
 }
 
 func (a *App) getSelectedLog() *models.LogEntry {
-	if len(a.state.LogListState.Logs) == 0 {
+	logs := a.viewLogs()
+	if len(logs) == 0 {
 		return nil
 	}
 	idx := a.currentSelectedIndex()
-	if idx < 0 || idx >= len(a.state.LogListState.Logs) {
+	if idx < 0 || idx >= len(logs) {
 		return nil
 	}
-	return &a.state.LogListState.Logs[idx]
+	return &logs[idx]
 }
 
 func (a *App) resetDetailPopupState() {
@@ -2639,8 +3182,8 @@ func (a *App) resetDetailPopupState() {
 		return
 	}
 	a.detailViewMode = "json-tree"
-	if a.entryHasAnyPayload(*entry) {
-		return
+	if _, ok := parseAuditLog(*entry); ok {
+		a.detailViewMode = "audit"
 	}
 }
 
@@ -2705,7 +3248,11 @@ func (a *App) cycleDetailViewMode() {
 }
 
 func (a *App) availableDetailModes(entry models.LogEntry) []string {
-	modes := []string{"json-tree", "full"}
+	var modes []string
+	if _, ok := parseAuditLog(entry); ok {
+		modes = append(modes, "audit")
+	}
+	modes = append(modes, "json-tree", "full")
 	if a.entryHasAnyPayload(entry) {
 		modes = append(modes, "payload-raw")
 	}
@@ -2732,6 +3279,8 @@ func (a *App) detailPopupLines(entry models.LogEntry, popupWidth int) ([]string,
 		}
 		a.ensureDetailCursorVisible()
 		return out, a.detailCursor
+	case "audit":
+		return wrapTextByWidth(auditDetailLines(entry), contentWidth), -1
 	case "payload-raw":
 		payloadText, ok := a.getPayloadDisplayText(entry)
 		if !ok {
@@ -2758,6 +3307,9 @@ func (a *App) detailEntryJSONObject(entry models.LogEntry) map[string]interface{
 		"severity":  entry.Severity,
 		"message":   entry.Message,
 	}
+	if entry.SeverityField != "" {
+		root["severityInferredFrom"] = entry.SeverityField
+	}
 	if len(entry.Labels) > 0 {
 		root["labels"] = entry.Labels
 	}
@@ -2774,6 +3326,12 @@ func (a *App) detailEntryJSONObject(entry models.LogEntry) map[string]interface{
 			"function": entry.SourceLocation.Function,
 		}
 	}
+	if entry.LogName != "" {
+		root["logName"] = entry.LogName
+	}
+	if entry.HTTPRequest != nil {
+		root["httpRequest"] = httpRequestObject(*entry.HTTPRequest)
+	}
 	if entry.Trace != "" {
 		root["trace"] = entry.Trace
 	}
@@ -2787,9 +3345,22 @@ func (a *App) detailEntryJSONObject(entry models.LogEntry) map[string]interface{
 	} else if strings.TrimSpace(entry.TextPayload) != "" {
 		root["textPayload"] = entry.TextPayload
 	}
+	if entry.ProtoPayload != nil {
+		root["protoPayload"] = entry.ProtoPayload
+	}
 	return root
 }
 
+// httpRequestObject converts an HTTP request to a JSON object using its
+// Cloud Logging field names
+func httpRequestObject(req models.HTTPRequest) map[string]interface{} {
+	out := map[string]interface{}{}
+	if data, err := json.Marshal(req); err == nil {
+		_ = json.Unmarshal(data, &out)
+	}
+	return out
+}
+
 func (a *App) selectedDetailNodeInfo() (string, string) {
 	if a.detailViewMode != "json-tree" {
 		return "", ""
@@ -2923,7 +3494,7 @@ func (a *App) copyDetailPayload() {
 }
 
 func (a *App) entryHasAnyPayload(entry models.LogEntry) bool {
-	if entry.JSONPayload != nil || strings.TrimSpace(entry.TextPayload) != "" {
+	if entry.JSONPayload != nil || entry.ProtoPayload != nil || strings.TrimSpace(entry.TextPayload) != "" {
 		return true
 	}
 	_, ok := parseStructuredJSONPayload(entry.Message)
@@ -2939,6 +3510,9 @@ func (a *App) getStructuredPayload(entry models.LogEntry) (interface{}, bool) {
 	if entry.JSONPayload != nil {
 		return entry.JSONPayload, true
 	}
+	if entry.ProtoPayload != nil {
+		return entry.ProtoPayload, true
+	}
 	if payload, ok := parseStructuredJSONPayload(entry.TextPayload); ok {
 		return payload, true
 	}
@@ -2956,6 +3530,13 @@ func (a *App) getPayloadDisplayText(entry models.LogEntry) (string, bool) {
 		}
 		return string(data), true
 	}
+	if entry.ProtoPayload != nil {
+		data, err := json.MarshalIndent(entry.ProtoPayload, "", "  ")
+		if err != nil {
+			return fmt.Sprintf("%v", entry.ProtoPayload), true
+		}
+		return string(data), true
+	}
 	textPayload := strings.TrimSpace(entry.TextPayload)
 	if parsed, ok := parseStructuredJSONPayload(textPayload); ok {
 		data, err := json.MarshalIndent(parsed, "", "  ")
@@ -3142,6 +3723,8 @@ func (a *App) selectProject() {
 	}
 	project := a.availableProjects[a.projectCursor]
 	a.state.CurrentProject = project
+	a.applyProjectColumns()
+	a.applyProjectSettings()
 	a.activeModalName = "none"
 }
 
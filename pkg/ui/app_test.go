@@ -551,6 +551,38 @@ func TestProjectPopupRendersCenteredWithRange(t *testing.T) {
 	}
 }
 
+func TestSelectProjectResolvesProjectSettings(t *testing.T) {
+	t.Cleanup(resetThemeColors)
+	state := &models.AppState{IsReady: true, CurrentProject: "dev"}
+	app := NewApp(state)
+	app.SetPreferences(config.Preferences{LogOrder: "latest_top"})
+	var resolved []string
+	app.SetProjectSettingsFn(func(project string, prefs config.Preferences) (config.Config, error) {
+		resolved = append(resolved, project)
+		cfg := config.DefaultConfig()
+		if project == "prod" {
+			cfg.Timezone = "local"
+			cfg.Colors = map[string]string{"primary": "#ff0000"}
+		}
+		return prefs.Apply(cfg), nil
+	})
+	app.availableProjects = []string{"dev", "prod"}
+
+	app.projectCursor = 1
+	app.selectProject()
+	if app.timezoneMode != "local" || app.logOrder != "latest_top" || colorGCPBlue != "#ff0000" {
+		t.Errorf("expected the prod settings under the preferences, got %s %s %s", app.timezoneMode, app.logOrder, colorGCPBlue)
+	}
+	app.projectCursor = 0
+	app.selectProject()
+	if app.timezoneMode != "utc" || colorGCPBlue != themeDefaults["primary"] {
+		t.Errorf("expected the dev settings after switching back, got %s %s", app.timezoneMode, colorGCPBlue)
+	}
+	if len(resolved) != 2 || resolved[0] != "prod" || resolved[1] != "dev" {
+		t.Errorf("expected the settings resolved on every switch, got %v", resolved)
+	}
+}
+
 func TestDetailPopupDefaultsToPayloadTree(t *testing.T) {
 	state := &models.AppState{
 		IsReady: true,
@@ -994,3 +1026,74 @@ func TestDetailPopupDefaultsToTreeForLenientPayload(t *testing.T) {
 		t.Fatalf("expected json-tree for lenient payload, got %s", app.detailViewMode)
 	}
 }
+
+func TestRuntimeTogglesPersistPreferences(t *testing.T) {
+	state := &models.AppState{IsReady: true}
+	app := NewApp(state)
+	var saved []config.Preferences
+	app.SetPreferencesPersistFn(func(prefs config.Preferences) error {
+		saved = append(saved, prefs)
+		return nil
+	})
+
+	newModel, _ := app.Update(tea.KeyMsg{Type: tea.KeyF8})
+	app = newModel.(*App)
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyF7})
+	app = newModel.(*App)
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyDown})
+	app = newModel.(*App)
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	app = newModel.(*App)
+
+	if len(saved) != 2 {
+		t.Fatalf("expected preferences saved after each toggle, got %d saves", len(saved))
+	}
+	last := saved[len(saved)-1]
+	if last.LogOrder != "latest_top" || last.Timezone != "local" || last.VimMode != nil {
+		t.Fatalf("unexpected persisted preferences %+v", last)
+	}
+}
+
+func TestPersistPreferencesWritesOnlyChangedSettings(t *testing.T) {
+	state := &models.AppState{IsReady: true}
+	app := NewApp(state)
+	// A timezone from --tz and a loaded row template preference
+	app.SetTimezoneMode("local")
+	app.SetPreferences(config.Preferences{RowTemplate: "svc"})
+	var saved []config.Preferences
+	app.SetPreferencesPersistFn(func(prefs config.Preferences) error {
+		saved = append(saved, prefs)
+		return nil
+	})
+
+	newModel, _ := app.Update(tea.KeyMsg{Type: tea.KeyF8})
+	app = newModel.(*App)
+	if len(saved) != 1 {
+		t.Fatalf("expected one save, got %d", len(saved))
+	}
+	if saved[0].Timezone != "" || saved[0].VimMode != nil {
+		t.Errorf("expected the flag-only timezone not to be written, got %+v", saved[0])
+	}
+	if saved[0].LogOrder != app.logOrder || saved[0].RowTemplate != "svc" {
+		t.Errorf("expected the new log order merged into the loaded preferences, got %+v", saved[0])
+	}
+}
+
+func TestConfigSettersApplyToApp(t *testing.T) {
+	state := &models.AppState{IsReady: true}
+	app := NewApp(state)
+	app.SetQueryCacheLimits(time.Minute, 5)
+	app.SetLogOrder("latest_top")
+	app.SetTimelineBucket(time.Minute)
+	app.SetTimezoneMode("local")
+
+	if app.queryCacheTTL != time.Minute || app.queryCacheMax != 5 {
+		t.Fatalf("unexpected cache limits %v/%d", app.queryCacheTTL, app.queryCacheMax)
+	}
+	if app.logOrder != "latest_top" || app.timezoneMode != "local" {
+		t.Fatalf("unexpected order/timezone %s/%s", app.logOrder, app.timezoneMode)
+	}
+	if app.timelineBuilder.GetBucketSize() != time.Minute {
+		t.Fatalf("unexpected bucket size %v", app.timelineBuilder.GetBucketSize())
+	}
+}
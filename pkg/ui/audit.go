@@ -0,0 +1,211 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// auditLogType is the protoPayload @type of Cloud Audit Logs entries
+const auditLogType = "type.googleapis.com/google.cloud.audit.AuditLog"
+
+// Field paths of the audit pivots
+const (
+	auditPrincipalField = "protoPayload.authenticationInfo.principalEmail"
+	auditResourceField  = "protoPayload.resourceName"
+)
+
+// auditStatusNames names the google.rpc.Code values seen in audit logs
+var auditStatusNames = map[int]string{
+	1:  "CANCELLED",
+	2:  "UNKNOWN",
+	3:  "INVALID_ARGUMENT",
+	4:  "DEADLINE_EXCEEDED",
+	5:  "NOT_FOUND",
+	6:  "ALREADY_EXISTS",
+	7:  "PERMISSION_DENIED",
+	8:  "RESOURCE_EXHAUSTED",
+	9:  "FAILED_PRECONDITION",
+	10: "ABORTED",
+	13: "INTERNAL",
+	14: "UNAVAILABLE",
+	16: "UNAUTHENTICATED",
+}
+
+type auditAuthorization struct {
+	resource   string
+	permission string
+	granted    bool
+}
+
+// auditRecord is the part of an AuditLog protoPayload worth showing
+type auditRecord struct {
+	principal string
+	method    string
+	service   string
+	resource  string
+	callerIP  string
+	userAgent string
+	code      int
+	message   string
+	authz     []auditAuthorization
+	request   interface{}
+	response  interface{}
+}
+
+// parseAuditLog reads the audit fields of entry, if it is an audit log entry
+func parseAuditLog(entry models.LogEntry) (auditRecord, bool) {
+	p := entry.ProtoPayload
+	if p == nil || p["@type"] != auditLogType {
+		return auditRecord{}, false
+	}
+	var r auditRecord
+	r.principal, _ = entry.FieldValue(auditPrincipalField)
+	r.method, _ = p["methodName"].(string)
+	r.service, _ = p["serviceName"].(string)
+	r.resource, _ = p["resourceName"].(string)
+	r.callerIP, _ = entry.FieldValue("protoPayload.requestMetadata.callerIp")
+	r.userAgent, _ = entry.FieldValue("protoPayload.requestMetadata.callerSuppliedUserAgent")
+	if status, ok := p["status"].(map[string]interface{}); ok {
+		if code, ok := status["code"].(float64); ok {
+			r.code = int(code)
+		}
+		r.message, _ = status["message"].(string)
+	}
+	if list, ok := p["authorizationInfo"].([]interface{}); ok {
+		for _, item := range list {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			var a auditAuthorization
+			a.resource, _ = obj["resource"].(string)
+			a.permission, _ = obj["permission"].(string)
+			a.granted, _ = obj["granted"].(bool)
+			r.authz = append(r.authz, a)
+		}
+	}
+	r.request = p["request"]
+	r.response = p["response"]
+	return r, true
+}
+
+// result is "OK" or the status code name, e.g. "PERMISSION_DENIED"
+func (r auditRecord) result() string {
+	if r.code == 0 {
+		return "OK"
+	}
+	if name, ok := auditStatusNames[r.code]; ok {
+		return name
+	}
+	return "code " + strconv.Itoa(r.code)
+}
+
+// summary is the audit row: who did what to which resource, and the result
+func (r auditRecord) summary() string {
+	who := r.principal
+	if who == "" {
+		who = "unknown principal"
+	}
+	what := r.method
+	if what == "" {
+		what = r.service
+	}
+	line := who + " " + what
+	if r.resource != "" {
+		line += " on " + r.resource
+	}
+	return line + ": " + r.result()
+}
+
+// listMessage is the message shown for entry in the log list
+func listMessage(entry models.LogEntry) string {
+	if r, ok := parseAuditLog(entry); ok {
+		return r.summary()
+	}
+	return entry.Message
+}
+
+// auditDetailLines renders the audit detail view
+func auditDetailLines(entry models.LogEntry) []string {
+	r, ok := parseAuditLog(entry)
+	if !ok {
+		return []string{"Not an audit log entry"}
+	}
+	row := func(label, value string) string {
+		return fmt.Sprintf("%-10s %s", label, value)
+	}
+	who := r.principal
+	if r.callerIP != "" {
+		who += "  from " + r.callerIP
+	}
+	lines := []string{
+		row("Who", who),
+		row("What", strings.TrimSpace(r.method+"  "+r.service)),
+		row("Resource", r.resource),
+	}
+	result := r.result()
+	if r.message != "" {
+		result += ": " + r.message
+	}
+	lines = append(lines, row("Result", result))
+	if r.userAgent != "" {
+		lines = append(lines, row("Agent", r.userAgent))
+	}
+	if entry.LogName != "" {
+		lines = append(lines, row("Log", models.LogID(entry.LogName)))
+	}
+	if len(r.authz) > 0 {
+		lines = append(lines, "", "Authorization")
+		for _, a := range r.authz {
+			mark := "✗ denied "
+			if a.granted {
+				mark = "✓ granted"
+			}
+			lines = append(lines, fmt.Sprintf("  %s %s on %s", mark, a.permission, a.resource))
+		}
+	}
+	for _, section := range []struct {
+		title string
+		value interface{}
+	}{{"Request", r.request}, {"Response", r.response}} {
+		if section.value == nil {
+			continue
+		}
+		data, err := json.MarshalIndent(section.value, "  ", "  ")
+		if err != nil {
+			continue
+		}
+		lines = append(lines, "", section.title)
+		lines = append(lines, strings.Split("  "+string(data), "\n")...)
+	}
+	return lines
+}
+
+// pivotAuditField adds the principal or resource of the selected audit
+// entry to the query and re-runs it
+func (a *App) pivotAuditField(field string) tea.Cmd {
+	entry := a.getSelectedLog()
+	if entry == nil {
+		return nil
+	}
+	if _, ok := parseAuditLog(*entry); !ok {
+		a.lastErr = "Pivots by principal or resource need an audit log entry"
+		return nil
+	}
+	value, ok := entry.FieldValue(field)
+	if !ok {
+		a.lastErr = "The entry has no " + field
+		return nil
+	}
+	clause := field + "=" + strconv.Quote(value)
+	a.activeModalName = "none"
+	a.detailScroll = 0
+	a.detailCursor = 0
+	a.lastErr = "Added to query: " + clause
+	return a.appendToQueryAndRun(clause)
+}
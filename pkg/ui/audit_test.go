@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func auditTestEntry() models.LogEntry {
+	return models.LogEntry{
+		Timestamp: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Severity:  "NOTICE",
+		Message:   "storage.buckets.delete",
+		LogName:   "projects/p/logs/cloudaudit.googleapis.com%2Factivity",
+		ProtoPayload: map[string]interface{}{
+			"@type":              auditLogType,
+			"methodName":         "storage.buckets.delete",
+			"serviceName":        "storage.googleapis.com",
+			"resourceName":       "projects/_/buckets/logs",
+			"authenticationInfo": map[string]interface{}{"principalEmail": "alice@example.com"},
+			"requestMetadata":    map[string]interface{}{"callerIp": "10.0.0.1"},
+			"status":             map[string]interface{}{"code": float64(7), "message": "denied"},
+			"authorizationInfo": []interface{}{
+				map[string]interface{}{"resource": "projects/_/buckets/logs", "permission": "storage.buckets.delete", "granted": false},
+			},
+			"request": map[string]interface{}{"bucket": "logs"},
+		},
+	}
+}
+
+func TestAuditSummaryAndDetail(t *testing.T) {
+	entry := auditTestEntry()
+	if got := listMessage(entry); got != "alice@example.com storage.buckets.delete on projects/_/buckets/logs: PERMISSION_DENIED" {
+		t.Errorf("Unexpected audit row %q", got)
+	}
+	detail := strings.Join(auditDetailLines(entry), "\n")
+	for _, want := range []string{"alice@example.com  from 10.0.0.1", "PERMISSION_DENIED: denied", "✗ denied  storage.buckets.delete", "cloudaudit.googleapis.com/activity", `"bucket": "logs"`} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("Expected %q in the audit detail:\n%s", want, detail)
+		}
+	}
+	plain := models.LogEntry{Message: "hello", ProtoPayload: map[string]interface{}{"@type": "type.googleapis.com/other"}}
+	if listMessage(plain) != "hello" {
+		t.Error("Expected other proto payloads to keep their message")
+	}
+}
+
+func TestAuditDetailModeAndPivot(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.state.LogListState.Logs = []models.LogEntry{auditTestEntry()}
+	var ran string
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		ran = filter
+		return nil, nil
+	})
+
+	app = pressKeys(app, tea.KeyMsg{Type: tea.KeyCtrlP})
+	if app.detailViewMode != "audit" {
+		t.Fatalf("Expected audit entries to open in the audit view, got %q", app.detailViewMode)
+	}
+	if modes := app.availableDetailModes(auditTestEntry()); modes[0] != "audit" || modes[1] != "json-tree" {
+		t.Errorf("Unexpected detail modes %v", modes)
+	}
+	if view := app.View(); !strings.Contains(view, "Mode:audit") || !strings.Contains(view, "p:query by principal") {
+		t.Error("Expected the audit view with its pivot hint")
+	}
+
+	_, cmd := app.Update(runeKey('p'))
+	if !strings.HasSuffix(app.state.CurrentQuery.Filter, `protoPayload.authenticationInfo.principalEmail="alice@example.com"`) {
+		t.Errorf("Expected the principal pivot in the query, got %q", app.state.CurrentQuery.Filter)
+	}
+	if cmd == nil {
+		t.Fatal("Expected the pivot to run the query")
+	}
+	cmd()
+	if !strings.Contains(ran, "principalEmail") {
+		t.Errorf("Expected the executed filter to include the pivot, got %q", ran)
+	}
+}
@@ -0,0 +1,439 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/config"
+	"github.com/user/log-explorer-tui/pkg/models"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+const (
+	defaultColumnWidth = 14
+	minColumnWidth     = 3
+	maxColumnWidth     = 60
+	columnAlignLeft    = "left"
+	columnAlignRight   = "right"
+)
+
+// logListPrefixHeader labels the fixed columns in front of the user columns.
+// Its width matches the index, timestamp and severity badge of a row.
+const logListPrefixHeader = "IDX   TIMESTAMP           SEV     "
+
+func columnWidth(c models.Column) int {
+	if c.Width <= 0 {
+		return defaultColumnWidth
+	}
+	return c.Width
+}
+
+// columnTitles shortens the titles of well-known fields
+var columnTitles = map[string]string{
+	"httpRequest.requestMethod": "METHOD",
+	"httpRequest.requestUrl":    "URL",
+	"httpRequest.requestSize":   "REQ SIZE",
+	"httpRequest.responseSize":  "SIZE",
+	"httpRequest.remoteIp":      "REMOTE IP",
+	"httpRequest.userAgent":     "USER AGENT",
+}
+
+// columnTitle is the upper-cased last segment of the field path
+func columnTitle(c models.Column) string {
+	if title, ok := columnTitles[c.Field]; ok {
+		return title
+	}
+	parts := models.SplitFieldPath(c.Field)
+	if len(parts) == 0 {
+		return strings.ToUpper(c.Field)
+	}
+	return strings.ToUpper(parts[len(parts)-1])
+}
+
+// fitCell pads or cuts text to exactly width display cells
+func fitCell(text string, width int, align string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if lipgloss.Width(text) > width {
+		runes := []rune(text)
+		for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+			runes = runes[:len(runes)-1]
+		}
+		text = string(runes) + "…"
+	}
+	pad := strings.Repeat(" ", maxInt(0, width-lipgloss.Width(text)))
+	if align == columnAlignRight {
+		return pad + text
+	}
+	return text + pad
+}
+
+// parseColumnSpec parses "field [width] [left|right]"
+func parseColumnSpec(spec string) (models.Column, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return models.Column{}, fmt.Errorf("enter a field path")
+	}
+	col := models.Column{Field: normalizeColumnField(fields[0])}
+	if col.Field == "" {
+		return models.Column{}, fmt.Errorf("invalid field path %q", fields[0])
+	}
+	for _, f := range fields[1:] {
+		switch {
+		case f == columnAlignLeft || f == columnAlignRight:
+			col.Align = f
+		default:
+			width, err := strconv.Atoi(f)
+			if err != nil || width < minColumnWidth || width > maxColumnWidth {
+				return models.Column{}, fmt.Errorf("width must be %d-%d, got %q", minColumnWidth, maxColumnWidth, f)
+			}
+			col.Width = width
+		}
+	}
+	return col, nil
+}
+
+func formatColumnSpec(c models.Column) string {
+	spec := c.Field
+	if c.Width > 0 {
+		spec += " " + strconv.Itoa(c.Width)
+	}
+	if c.Align != "" {
+		spec += " " + c.Align
+	}
+	return spec
+}
+
+// normalizeColumnField quotes path segments that need it, so the field can
+// be used in a query as is
+func normalizeColumnField(field string) string {
+	parts := models.SplitFieldPath(strings.TrimSpace(field))
+	if len(parts) == 0 {
+		return ""
+	}
+	return query.FieldPath(parts...)
+}
+
+// logListHeader renders the column header line of the log list
+func (a *App) logListHeader() string {
+	var sb strings.Builder
+	sb.WriteString(logListPrefixHeader)
+	ls := a.state.LogListState
+	for _, col := range a.listColumns() {
+		width := columnWidth(col)
+		title := columnTitle(col)
+		if col.Field == ls.SortField {
+			mark := "▲"
+			if ls.SortDesc {
+				mark = "▼"
+			}
+			title = strings.TrimRight(fitCell(title, width-1, columnAlignLeft), " ") + mark
+		}
+		sb.WriteString(fitCell(title, width, col.Align))
+		sb.WriteString("  ")
+	}
+	if a.httpMode {
+		sb.WriteString("URL")
+	} else {
+		sb.WriteString("MESSAGE")
+	}
+	return sb.String()
+}
+
+// columnCells renders the list columns of one row, each followed by two
+// spaces
+func (a *App) columnCells(entry models.LogEntry) string {
+	var sb strings.Builder
+	for _, col := range a.listColumns() {
+		value, _ := entry.FieldValue(col.Field)
+		sb.WriteString(fitCell(cellValue(col.Field, value), columnWidth(col), col.Align))
+		sb.WriteString("  ")
+	}
+	return sb.String()
+}
+
+// logRowPrefixWidth is the number of cells the panel frame, the index, the
+// timestamp and the severity badge take before the list columns of a row
+const logRowPrefixWidth = 47
+
+// columnsWidth is the number of cells the list columns take in a row
+func (a *App) columnsWidth() int {
+	total := 0
+	for _, col := range a.listColumns() {
+		total += columnWidth(col) + 2
+	}
+	return total
+}
+
+// sortLogsByField returns logs ordered by a field value. Entries without the
+// field go last in both directions.
+func sortLogsByField(logs []models.LogEntry, field string, desc bool) []models.LogEntry {
+	type keyed struct {
+		value string
+		ok    bool
+	}
+	keys := make([]keyed, len(logs))
+	order := make([]int, len(logs))
+	for i, entry := range logs {
+		value, ok := entry.FieldValue(field)
+		keys[i] = keyed{value: value, ok: ok}
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		ki, kj := keys[order[i]], keys[order[j]]
+		if ki.ok != kj.ok {
+			return ki.ok
+		}
+		if !ki.ok {
+			return false
+		}
+		c := query.CompareFieldValues(field, ki.value, kj.value)
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+	sorted := make([]models.LogEntry, len(logs))
+	for i, idx := range order {
+		sorted[i] = logs[idx]
+	}
+	return sorted
+}
+
+// SetColumnSets loads the column sets saved per project and applies the
+// set of the current project
+func (a *App) SetColumnSets(sets config.ColumnSets) {
+	a.columnSets = make(map[string][]models.Column, len(sets.Projects))
+	for project, cols := range sets.Projects {
+		a.columnSets[project] = append([]models.Column(nil), cols...)
+	}
+	a.applyProjectColumns()
+}
+
+// SetColumnSetsPersistFn sets the persistence callback for column sets
+func (a *App) SetColumnSetsPersistFn(fn func(config.ColumnSets) error) {
+	a.persistColumnsFn = fn
+}
+
+// applyProjectColumns switches to the column set saved for the current
+// project, if there is one
+func (a *App) applyProjectColumns() {
+	if cols, ok := a.columnSets[a.state.CurrentProject]; ok {
+		a.setColumns(cols)
+	}
+}
+
+func (a *App) setColumns(cols []models.Column) {
+	ls := &a.state.LogListState
+	ls.Columns = append([]models.Column(nil), cols...)
+	sortKept := false
+	for _, col := range cols {
+		sortKept = sortKept || col.Field == ls.SortField
+	}
+	if !sortKept {
+		a.setLogSort("", false)
+	}
+}
+
+// setLogSort orders the list by a column field, keeping the selected entry
+func (a *App) setLogSort(field string, desc bool) {
+	a.preserveSelection(func() {
+		a.state.LogListState.SortField = field
+		a.state.LogListState.SortDesc = desc
+	})
+}
+
+func (a *App) openColumnEditor() {
+	a.activeModalName = "columns"
+	a.columnCursor = clampInt(a.columnCursor, 0, maxInt(0, len(a.state.LogListState.Columns)-1))
+}
+
+// handleColumnsInput handles keys in the column editor
+func (a *App) handleColumnsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	cols := a.state.LogListState.Columns
+	cursor := a.columnCursor
+	switch msg.String() {
+	case "esc", "C":
+		a.activeModalName = "none"
+		return a, nil
+	case "j", "down":
+		a.columnCursor = minInt(cursor+1, maxInt(0, len(cols)-1))
+		return a, nil
+	case "k", "up":
+		a.columnCursor = maxInt(0, cursor-1)
+		return a, nil
+	case "a":
+		a.prompt.Open("columnAdd", "ADD COLUMN", "")
+		a.prompt.SetHint("field [width] [left|right], e.g. jsonPayload.userId 12 or httpRequest.status 6 right")
+		a.activeModalName = "prompt"
+		return a, nil
+	case "S":
+		a.saveProjectColumns()
+		return a, nil
+	case "Q":
+		a.saveQueryColumns()
+		return a, nil
+	}
+	if cursor >= len(cols) {
+		return a, nil
+	}
+
+	col := cols[cursor]
+	switch msg.String() {
+	case "enter", "e":
+		a.prompt.Open("columnEdit", "EDIT COLUMN", formatColumnSpec(col))
+		a.prompt.SetHint("field [width] [left|right]")
+		a.activeModalName = "prompt"
+	case "d", "x":
+		a.setColumns(append(append([]models.Column(nil), cols[:cursor]...), cols[cursor+1:]...))
+		a.columnCursor = clampInt(cursor, 0, maxInt(0, len(cols)-2))
+	case "J":
+		if cursor+1 < len(cols) {
+			a.swapColumns(cursor, cursor+1)
+			a.columnCursor++
+		}
+	case "K":
+		if cursor > 0 {
+			a.swapColumns(cursor, cursor-1)
+			a.columnCursor--
+		}
+	case "<", "-":
+		col.Width = maxInt(minColumnWidth, columnWidth(col)-2)
+		a.updateColumn(cursor, col)
+	case ">", "+":
+		col.Width = minInt(maxColumnWidth, columnWidth(col)+2)
+		a.updateColumn(cursor, col)
+	case "r":
+		if col.Align == columnAlignRight {
+			col.Align = columnAlignLeft
+		} else {
+			col.Align = columnAlignRight
+		}
+		a.updateColumn(cursor, col)
+	case "s":
+		// Cycle ascending, descending, time order
+		ls := a.state.LogListState
+		switch {
+		case ls.SortField != col.Field:
+			a.setLogSort(col.Field, false)
+		case !ls.SortDesc:
+			a.setLogSort(col.Field, true)
+		default:
+			a.setLogSort("", false)
+		}
+	}
+	return a, nil
+}
+
+// updateColumn replaces column idx in a copy of the column set, so tabs
+// opened from this one keep their own set
+func (a *App) updateColumn(idx int, col models.Column) {
+	cols := append([]models.Column(nil), a.state.LogListState.Columns...)
+	if cols[idx].Field == a.state.LogListState.SortField && col.Field != cols[idx].Field {
+		a.setLogSort("", false)
+	}
+	cols[idx] = col
+	a.state.LogListState.Columns = cols
+}
+
+func (a *App) swapColumns(i, j int) {
+	cols := append([]models.Column(nil), a.state.LogListState.Columns...)
+	cols[i], cols[j] = cols[j], cols[i]
+	a.state.LogListState.Columns = cols
+}
+
+func (a *App) submitColumnSpec(spec string, edit bool) {
+	a.activeModalName = "columns"
+	col, err := parseColumnSpec(spec)
+	if err != nil {
+		a.lastErr = "Invalid column: " + err.Error()
+		return
+	}
+	cols := a.state.LogListState.Columns
+	if edit && a.columnCursor < len(cols) {
+		a.updateColumn(a.columnCursor, col)
+		return
+	}
+	a.state.LogListState.Columns = append(append([]models.Column(nil), cols...), col)
+	a.columnCursor = len(a.state.LogListState.Columns) - 1
+}
+
+// saveProjectColumns stores the column set as the default for the project
+func (a *App) saveProjectColumns() {
+	project := a.state.CurrentProject
+	if a.columnSets == nil {
+		a.columnSets = map[string][]models.Column{}
+	}
+	a.columnSets[project] = append([]models.Column(nil), a.state.LogListState.Columns...)
+	if a.persistColumnsFn != nil {
+		if err := a.persistColumnsFn(config.ColumnSets{Projects: a.columnSets}); err != nil {
+			a.lastErr = "Save columns failed: " + err.Error()
+			return
+		}
+	}
+	a.lastErr = "Saved columns for project " + project
+}
+
+// saveQueryColumns stores the column set with the saved query that matches
+// the current query
+func (a *App) saveQueryColumns() {
+	filter := sanitizeFilterForExecution(a.state.CurrentQuery.Filter)
+	for i := range a.queryLibrary {
+		if sanitizeFilterForExecution(a.queryLibrary[i].Filter) != filter {
+			continue
+		}
+		a.queryLibrary[i].Columns = append([]models.Column(nil), a.state.LogListState.Columns...)
+		if a.persistLibraryFn != nil {
+			if err := a.persistLibraryFn(a.queryLibrary); err != nil {
+				a.lastErr = "Save query library failed: " + err.Error()
+				return
+			}
+		}
+		a.lastErr = "Saved columns with query " + a.queryLibrary[i].Name
+		return
+	}
+	a.lastErr = "Save the query first (Ctrl+S in the editor)"
+}
+
+func (a *App) renderColumnsPopup() string {
+	var sb strings.Builder
+	popupWidth := minInt(maxInt(50, a.width-20), 100)
+	ls := a.state.LogListState
+	sb.WriteString(a.popupTop(popupWidth, "COLUMNS"))
+	sb.WriteString(a.popupLine(popupWidth, lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle)).Render(
+		"Shown between SEV and MESSAGE, in this order")))
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+	if len(ls.Columns) == 0 {
+		sb.WriteString(a.popupLine(popupWidth, "No columns yet (a: add a field such as jsonPayload.userId)"))
+	}
+	for i, col := range ls.Columns {
+		prefix := "  "
+		if i == a.columnCursor {
+			prefix = "▶ "
+		}
+		align := col.Align
+		if align == "" {
+			align = columnAlignLeft
+		}
+		sortMark := ""
+		if col.Field == ls.SortField {
+			sortMark = "  sort:asc"
+			if ls.SortDesc {
+				sortMark = "  sort:desc"
+			}
+		}
+		line := fmt.Sprintf("%s%-12s %-40s w:%-3d %-5s%s", prefix, truncate(columnTitle(col), 12), truncate(col.Field, 40), columnWidth(col), align, sortMark)
+		if i == a.columnCursor {
+			line = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorSelectionFG)).Background(lipgloss.Color(colorSelectionBG)).Render(line)
+		}
+		sb.WriteString(a.popupLine(popupWidth, line))
+	}
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+	sb.WriteString(a.popupLine(popupWidth, "a add | Enter edit | d delete | J/K move | </> width | r align | s sort"))
+	sb.WriteString(a.popupLine(popupWidth, "S save for project | Q save with saved query | Esc close"))
+	sb.WriteString(a.popupBottom(popupWidth, '━'))
+	return sb.String()
+}
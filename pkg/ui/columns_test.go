@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/config"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func columnTestLogs() []models.LogEntry {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	return []models.LogEntry{
+		{ID: "1", Timestamp: base, Severity: "INFO", Message: "one", HTTPRequest: &models.HTTPRequest{Status: 200, Latency: "0.120s"}},
+		{ID: "2", Timestamp: base.Add(time.Minute), Severity: "INFO", Message: "two"},
+		{ID: "3", Timestamp: base.Add(2 * time.Minute), Severity: "ERROR", Message: "three", HTTPRequest: &models.HTTPRequest{Status: 503, Latency: "2.5s"}},
+		{ID: "4", Timestamp: base.Add(3 * time.Minute), Severity: "INFO", Message: "four", HTTPRequest: &models.HTTPRequest{Status: 404, Latency: "0.009s"}},
+	}
+}
+
+func TestParseColumnSpec(t *testing.T) {
+	col, err := parseColumnSpec("labels.k8s-pod/app 12 right")
+	if err != nil {
+		t.Fatalf("parseColumnSpec failed: %v", err)
+	}
+	if col.Field != `labels."k8s-pod/app"` || col.Width != 12 || col.Align != columnAlignRight {
+		t.Errorf("Unexpected column %+v", col)
+	}
+	if spec := formatColumnSpec(col); spec != `labels."k8s-pod/app" 12 right` {
+		t.Errorf("Unexpected spec %q", spec)
+	}
+	for _, bad := range []string{"", "user 1", "user wide"} {
+		if _, err := parseColumnSpec(bad); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestFitCell(t *testing.T) {
+	if got := fitCell("503", 6, columnAlignRight); got != "   503" {
+		t.Errorf("Expected right aligned cell, got %q", got)
+	}
+	if got := fitCell("a\nlong value", 6, columnAlignLeft); got != "a lon…" {
+		t.Errorf("Expected cut cell, got %q", got)
+	}
+}
+
+func TestSortLogsByFieldMissingLast(t *testing.T) {
+	for _, desc := range []bool{false, true} {
+		sorted := sortLogsByField(columnTestLogs(), "httpRequest.latency", desc)
+		var ids []string
+		for _, entry := range sorted {
+			ids = append(ids, entry.ID)
+		}
+		want := "4,1,3,2"
+		if desc {
+			want = "3,1,4,2"
+		}
+		if got := strings.Join(ids, ","); got != want {
+			t.Errorf("desc=%v: expected order %s, got %s", desc, want, got)
+		}
+	}
+}
+
+func TestColumnEditorAddSortAndSave(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.state.LogListState.Logs = columnTestLogs()
+	var saved config.ColumnSets
+	app.SetColumnSetsPersistFn(func(sets config.ColumnSets) error {
+		saved = sets
+		return nil
+	})
+
+	app = pressKeys(app, runeKey('C'), runeKey('a'))
+	if app.activeModalName != "prompt" {
+		t.Fatalf("Expected the add column prompt, got %q", app.activeModalName)
+	}
+	app = pressKeys(app, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("httpRequest.status 6 right")}, tea.KeyMsg{Type: tea.KeyEnter})
+	if app.activeModalName != "columns" || len(app.state.LogListState.Columns) != 1 {
+		t.Fatalf("Expected a column to be added, got %+v", app.state.LogListState.Columns)
+	}
+
+	app = pressKeys(app, runeKey('s'), runeKey('s'))
+	if ls := app.state.LogListState; ls.SortField != "httpRequest.status" || !ls.SortDesc {
+		t.Fatalf("Expected a descending sort on status, got %q desc=%v", ls.SortField, ls.SortDesc)
+	}
+	if first := app.viewLogs()[0]; first.ID != "3" {
+		t.Errorf("Expected the 503 entry first, got %s", first.ID)
+	}
+
+	app = pressKeys(app, runeKey('S'), tea.KeyMsg{Type: tea.KeyEsc})
+	if cols := saved.Projects["api-project"]; len(cols) != 1 || cols[0].Width != 6 {
+		t.Errorf("Expected the column set saved for the project, got %+v", saved.Projects)
+	}
+
+	view := ansiEscapeRegex.ReplaceAllString(app.View(), "")
+	if !strings.Contains(view, "STAT…▼") || !strings.Contains(view, "   503  ") {
+		t.Error("Expected the status column in the log list")
+	}
+}
+
+func TestApplyProjectColumns(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.SetColumnSets(config.ColumnSets{Projects: map[string][]models.Column{
+		"api-project": {{Field: "jsonPayload.userId"}},
+	}})
+	if cols := app.state.LogListState.Columns; len(cols) != 1 || cols[0].Field != "jsonPayload.userId" {
+		t.Errorf("Expected the project column set to apply, got %+v", cols)
+	}
+}
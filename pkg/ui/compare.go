@@ -0,0 +1,340 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// A pattern changed significantly when its count moved by at least
+// compareMinChange entries and by a factor of compareRatio
+const (
+	compareMinChange = 5
+	compareRatio     = 2.0
+)
+
+// compareDefaultWindow is the length of each window around an instant when
+// no time range is set
+const compareDefaultWindow = 30 * time.Minute
+
+// compareSparkBuckets is how many buckets the volume of a window is split into
+const compareSparkBuckets = 40
+
+// compareWindow is one side of a comparison
+type compareWindow struct {
+	name string
+	tr   models.TimeRange
+	logs []models.LogEntry
+}
+
+// patternChange is a pattern whose count differs between the windows
+type patternChange struct {
+	template string
+	before   int
+	after    int
+}
+
+func (c patternChange) status() string {
+	switch {
+	case c.before == 0:
+		return "NEW"
+	case c.after == 0:
+		return "GONE"
+	case c.after > c.before:
+		return fmt.Sprintf("×%.1f", float64(c.after)/float64(c.before))
+	}
+	return fmt.Sprintf("÷%.1f", float64(c.before)/float64(c.after))
+}
+
+// compareView is the state of the compare view
+type compareView struct {
+	choosing bool
+	loading  bool
+	filter   string
+	before   compareWindow
+	after    compareWindow
+	changes  []patternChange
+	scroll   int
+}
+
+type compareResultMsg struct {
+	filter string
+	before []models.LogEntry
+	after  []models.LogEntry
+	err    error
+}
+
+// beforeAfterWindows splits the time around at into two windows, each half
+// as long as the time range. An entry written at at is in the after window.
+func beforeAfterWindows(tr models.TimeRange, at time.Time) (compareWindow, compareWindow) {
+	length := compareDefaultWindow
+	if !tr.Start.IsZero() && !tr.End.IsZero() && tr.End.After(tr.Start) {
+		length = tr.End.Sub(tr.Start) / 2
+	}
+	return compareWindow{name: "Before", tr: models.TimeRange{Start: at.Add(-length), End: at}},
+		compareWindow{name: "After", tr: models.TimeRange{Start: at, End: at.Add(length)}}
+}
+
+// dayBeforeWindows compares the time range with the same range a day earlier
+func dayBeforeWindows(tr models.TimeRange, now time.Time) (compareWindow, compareWindow) {
+	if tr.Start.IsZero() || tr.End.IsZero() {
+		tr = models.TimeRange{Start: now.Add(-time.Hour), End: now}
+	}
+	day := 24 * time.Hour
+	return compareWindow{name: "Day before", tr: models.TimeRange{Start: tr.Start.Add(-day), End: tr.End.Add(-day)}},
+		compareWindow{name: "Time range", tr: tr}
+}
+
+// comparePatterns mines the patterns of both windows together and returns
+// those that are new, gone or changed significantly: new first, then gone,
+// then by the size of the change
+func comparePatterns(before, after []models.LogEntry) []patternChange {
+	inBefore := map[string]bool{}
+	for _, entry := range before {
+		inBefore[logEntryKey(entry)] = true
+	}
+	var changes []patternChange
+	for _, p := range minePatterns(append(slices.Clone(before), after...)) {
+		c := patternChange{template: p.template()}
+		for _, entry := range p.entries {
+			if inBefore[logEntryKey(entry)] {
+				c.before++
+			} else {
+				c.after++
+			}
+		}
+		if significantChange(c.before, c.after) {
+			changes = append(changes, c)
+		}
+	}
+	rank := func(c patternChange) int {
+		switch {
+		case c.before == 0:
+			return 0
+		case c.after == 0:
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if rank(changes[i]) != rank(changes[j]) {
+			return rank(changes[i]) < rank(changes[j])
+		}
+		return absInt(changes[i].after-changes[i].before) > absInt(changes[j].after-changes[j].before)
+	})
+	return changes
+}
+
+// significantChange reports whether a count moved from before to after by
+// both compareMinChange and compareRatio. A pattern that appears or
+// disappears always counts.
+func significantChange(before, after int) bool {
+	if before == 0 || after == 0 {
+		return before != after
+	}
+	if absInt(after-before) < compareMinChange {
+		return false
+	}
+	hi, lo := maxInt(before, after), minInt(before, after)
+	return float64(hi) >= compareRatio*float64(lo)
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// openCompare asks which windows to compare
+func (a *App) openCompare() {
+	a.compare = compareView{choosing: true}
+	a.activeModalName = "compare"
+}
+
+// runCompare queries the current filter over both windows, each from its
+// start up to but not including its end. Each window is paged to the end
+// like load-all, so the counts are not capped by the page size.
+func (a *App) runCompare(before, after compareWindow) tea.Cmd {
+	c := &a.compare
+	c.choosing = false
+	c.loading = true
+	c.before, c.after = before, after
+	c.scroll = 0
+	beforeFilter := a.buildFilterInWindow("", before.tr)
+	afterFilter := a.buildFilterInWindow("", after.tr)
+	c.filter = beforeFilter + "\n" + afterFilter
+	if a.queryExec == nil {
+		// Without an executor, compare what is loaded in each window
+		split := func(tr models.TimeRange) []models.LogEntry {
+			var logs []models.LogEntry
+			for _, entry := range a.state.LogListState.Logs {
+				if !entry.Timestamp.Before(tr.Start) && entry.Timestamp.Before(tr.End) {
+					logs = append(logs, entry)
+				}
+			}
+			return logs
+		}
+		a.applyCompareResult(compareResultMsg{filter: c.filter, before: split(before.tr), after: split(after.tr)})
+		return nil
+	}
+	filter := c.filter
+	project := a.state.CurrentProject
+	return func() tea.Msg {
+		beforeLogs, err := a.loadAllPages(project, beforeFilter)
+		if err != nil {
+			return compareResultMsg{filter: filter, err: err}
+		}
+		afterLogs, err := a.loadAllPages(project, afterFilter)
+		return compareResultMsg{filter: filter, before: beforeLogs, after: afterLogs, err: err}
+	}
+}
+
+func (a *App) applyCompareResult(msg compareResultMsg) {
+	c := &a.compare
+	if msg.filter != c.filter {
+		return
+	}
+	c.loading = false
+	if msg.err != nil {
+		a.lastErr = fmt.Sprintf("Compare query failed: %v", msg.err)
+		return
+	}
+	c.before.logs, c.after.logs = msg.before, msg.after
+	c.changes = comparePatterns(msg.before, msg.after)
+	a.lastErr = fmt.Sprintf("Compared %s entries with %s: %d patterns changed", formatCount(len(msg.before)), formatCount(len(msg.after)), len(c.changes))
+}
+
+// handleCompareInput handles keys in the compare view
+func (a *App) handleCompareInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := &a.compare
+	if c.choosing {
+		switch msg.String() {
+		case "esc", "D":
+			a.activeModalName = "none"
+		case "b":
+			entry := a.getSelectedLog()
+			if entry == nil {
+				a.lastErr = "Select the entry to compare before and after"
+				return a, nil
+			}
+			return a, a.runCompare(beforeAfterWindows(a.state.FilterState.TimeRange, entry.Timestamp))
+		case "y":
+			return a, a.runCompare(dayBeforeWindows(a.state.FilterState.TimeRange, time.Now()))
+		}
+		return a, nil
+	}
+	switch msg.String() {
+	case "esc", "D":
+		a.activeModalName = "none"
+	case "j", "down":
+		c.scroll = minInt(c.scroll+1, maxInt(0, len(c.changes)-1))
+	case "k", "up":
+		c.scroll = maxInt(0, c.scroll-1)
+	case "r":
+		c.choosing = true
+	}
+	return a, nil
+}
+
+// percentChange renders the change from before to after, e.g. "+35%"
+func percentChange(before, after int) string {
+	switch {
+	case before == after:
+		return "="
+	case before == 0:
+		return "new"
+	}
+	return fmt.Sprintf("%+.0f%%", float64(after-before)*100/float64(before))
+}
+
+func (a *App) renderComparePopup() string {
+	var sb strings.Builder
+	popupWidth := minInt(maxInt(70, a.width-10), 140)
+	inner := a.popupInnerWidth(popupWidth)
+	subtle := lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle))
+	heading := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorGCPBlueLight))
+	c := &a.compare
+
+	sb.WriteString(a.popupTop(popupWidth, "COMPARE"))
+	if c.choosing {
+		length := compareDefaultWindow
+		if tr := a.state.FilterState.TimeRange; !tr.Start.IsZero() && !tr.End.IsZero() {
+			length = tr.End.Sub(tr.Start) / 2
+		}
+		sb.WriteString(a.popupLine(popupWidth, "Run the current query over two windows and compare them:"))
+		sb.WriteString(a.popupLine(popupWidth, ""))
+		sb.WriteString(a.popupLine(popupWidth, fmt.Sprintf("  b  Before / after the selected entry (%s each)", length)))
+		sb.WriteString(a.popupLine(popupWidth, "  y  The time range / the same range a day earlier"))
+		sb.WriteString(a.popupSeparator(popupWidth, '━'))
+		sb.WriteString(a.popupLine(popupWidth, "Esc close"))
+		sb.WriteString(a.popupBottom(popupWidth, '━'))
+		return sb.String()
+	}
+
+	windowLine := func(w compareWindow) string {
+		return fmt.Sprintf("%s %s - %s", fitCell(w.name, 11, columnAlignLeft), a.displayTime(w.tr.Start).Format("01-02 15:04:05"), a.displayTime(w.tr.End).Format("01-02 15:04:05"))
+	}
+	sb.WriteString(a.popupLine(popupWidth, windowLine(c.before)))
+	sb.WriteString(a.popupLine(popupWidth, windowLine(c.after)))
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+	if c.loading {
+		sb.WriteString(a.popupLine(popupWidth, subtle.Render("Querying both windows...")))
+		sb.WriteString(a.popupBottom(popupWidth, '━'))
+		return sb.String()
+	}
+
+	// Volume, with one sparkline per window over the same buckets
+	bucket := maxDuration(c.after.tr.End.Sub(c.after.tr.Start)/compareSparkBuckets, time.Second)
+	timeline := NewTimelineBuilder(bucket)
+	sb.WriteString(a.popupLine(popupWidth, heading.Render("Volume")))
+	for _, w := range []compareWindow{c.before, c.after} {
+		spark := timeline.RenderSparkline(timeline.FillTimeline(timeline.BuildTimeline(w.logs), w.tr.Start, w.tr.End), compareSparkBuckets+1)
+		sb.WriteString(a.popupLine(popupWidth, fmt.Sprintf("  %s %s  %s", fitCell(w.name, 11, columnAlignLeft), fitCell(formatCount(len(w.logs)), 8, columnAlignRight), spark)))
+	}
+	change := percentChange(len(c.before.logs), len(c.after.logs))
+	sb.WriteString(a.popupLine(popupWidth, subtle.Render(fmt.Sprintf("  %s %s", fitCell("Change", 11, columnAlignLeft), fitCell(change, 8, columnAlignRight)))))
+
+	// Severity distribution
+	beforeDist := timeline.BuildSeverityDistribution(c.before.logs)
+	afterDist := timeline.BuildSeverityDistribution(c.after.logs)
+	sb.WriteString(a.popupLine(popupWidth, heading.Render("Severity")))
+	for i := len(models.SeverityLevels) - 1; i >= 0; i-- {
+		level := models.SeverityLevels[i]
+		before, after := beforeDist[level], afterDist[level]
+		if before == 0 && after == 0 {
+			continue
+		}
+		line := fmt.Sprintf("  %s %s %s  %s", fitCell(level, 11, columnAlignLeft), fitCell(formatCount(before), 8, columnAlignRight), fitCell(formatCount(after), 8, columnAlignRight), percentChange(before, after))
+		sb.WriteString(a.popupLine(popupWidth, severityStyle(level).Render(line)))
+	}
+
+	// Patterns that are new, gone or changed
+	sb.WriteString(a.popupLine(popupWidth, heading.Render(fmt.Sprintf("Patterns (%d changed)", len(c.changes)))))
+	if len(c.changes) == 0 {
+		sb.WriteString(a.popupLine(popupWidth, subtle.Render("  No pattern is new, gone or changed by ×2")))
+	}
+	visible := maxInt(3, a.height-24)
+	end := minInt(len(c.changes), c.scroll+visible)
+	for _, change := range c.changes[c.scroll:end] {
+		prefix := fmt.Sprintf("  %s %s  ", fitCell(change.status(), 6, columnAlignLeft), fitCell(fmt.Sprintf("%d → %d", change.before, change.after), 13, columnAlignRight))
+		line := prefix + truncate(change.template, maxInt(10, inner-lipgloss.Width(prefix)))
+		switch {
+		case change.before == 0:
+			line = lipgloss.NewStyle().Foreground(lipgloss.Color(colorGCPError)).Render(line)
+		case change.after == 0:
+			line = subtle.Render(line)
+		}
+		sb.WriteString(a.popupLine(popupWidth, line))
+	}
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+	sb.WriteString(a.popupLine(popupWidth, "j/k scroll patterns | r choose other windows | Esc close"))
+	sb.WriteString(a.popupBottom(popupWidth, '━'))
+	return sb.String()
+}
@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func compareTestLogs(base time.Time) (before, after []models.LogEntry) {
+	entry := func(i int, severity, message string, offset time.Duration) models.LogEntry {
+		return models.LogEntry{
+			ID:        fmt.Sprintf("%d", i),
+			Timestamp: base.Add(offset),
+			Severity:  severity,
+			Message:   message,
+		}
+	}
+	n := 0
+	for i := 0; i < 10; i++ {
+		n++
+		before = append(before, entry(n, "INFO", fmt.Sprintf("request %d served", i), -time.Duration(i+1)*time.Minute))
+	}
+	n++
+	before = append(before, entry(n, "INFO", "cache warmed", -20*time.Minute))
+	for i := 0; i < 12; i++ {
+		n++
+		after = append(after, entry(n, "INFO", fmt.Sprintf("request %d served", i), time.Duration(i+1)*time.Minute))
+	}
+	for i := 0; i < 3; i++ {
+		n++
+		after = append(after, entry(n, "ERROR", fmt.Sprintf("db pool exhausted after %dms", i*10), time.Duration(i+1)*time.Minute))
+	}
+	return before, after
+}
+
+func TestCompareWindows(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tr := models.TimeRange{Start: at.Add(-2 * time.Hour), End: at}
+	before, after := beforeAfterWindows(tr, at)
+	if !before.tr.Start.Equal(at.Add(-time.Hour)) || !before.tr.End.Equal(at) || !after.tr.End.Equal(at.Add(time.Hour)) {
+		t.Errorf("Expected hour long windows around the instant, got %v and %v", before.tr, after.tr)
+	}
+	before, after = dayBeforeWindows(tr, at)
+	if !before.tr.Start.Equal(tr.Start.Add(-24*time.Hour)) || after.tr != tr {
+		t.Errorf("Expected the range a day earlier, got %v", before.tr)
+	}
+}
+
+func TestComparePatterns(t *testing.T) {
+	before, after := compareTestLogs(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	changes := comparePatterns(before, after)
+	var got []string
+	for _, c := range changes {
+		got = append(got, fmt.Sprintf("%s %s", c.status(), c.template))
+	}
+	// 10 → 12 requests is not significant
+	want := "NEW db pool exhausted after <*>,GONE cache warmed"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Expected %s, got %v", want, got)
+	}
+	if !significantChange(4, 12) || significantChange(10, 14) || significantChange(1, 3) {
+		t.Error("Unexpected significance")
+	}
+}
+
+func TestCompareViewBeforeAfterEntry(t *testing.T) {
+	app := newTabsTestApp(t)
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	before, after := compareTestLogs(at)
+	app.state.FilterState.TimeRange = models.TimeRange{Start: at.Add(-time.Hour), End: at.Add(time.Hour)}
+	app.state.LogListState.Logs = append([]models.LogEntry{after[0]}, before...)
+	var ran []string
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		ran = append(ran, filter)
+		if strings.Contains(filter, `timestamp>="2024-05-01T10:01:00Z"`) {
+			return after, nil
+		}
+		return before, nil
+	})
+
+	app = pressKeys(app, runeKey('D'))
+	if app.activeModalName != "compare" {
+		t.Fatalf("Expected D to open the compare view, got %q", app.activeModalName)
+	}
+	_, cmd := app.handleKeyPress(runeKey('b'))
+	if cmd == nil {
+		t.Fatal("Expected b to query both windows")
+	}
+	model, _ := app.Update(cmd())
+	app = model.(*App)
+	// Each window is paged until a page brings no new entries
+	if len(ran) != 4 || !strings.Contains(ran[0], `timestamp<"2024-05-01T10:01:00Z"`) || !strings.Contains(ran[2], `timestamp>="2024-05-01T10:01:00Z"`) {
+		t.Errorf("Expected windows around the selected entry, got %q", ran)
+	}
+
+	view := ansiEscapeRegex.ReplaceAllString(app.View(), "")
+	for _, want := range []string{"COMPARE", "Volume", "+36%", "ERROR", "Patterns (2 changed)", "NEW", "0 → 3", "db pool exhausted after <*>", "GONE"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the compare view", want)
+		}
+	}
+}
+
+func TestCompareLoadsEveryPage(t *testing.T) {
+	app := newTabsTestApp(t)
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	app.state.FilterState.TimeRange = models.TimeRange{Start: at.Add(-time.Hour), End: at}
+	// Pages of 100 entries, newest first, 250 entries in every window
+	window := func(end time.Time) []models.LogEntry {
+		var logs []models.LogEntry
+		for i := 0; i < 250; i++ {
+			logs = append(logs, models.LogEntry{ID: fmt.Sprintf("%s-%d", end.Format(time.RFC3339), i), Timestamp: end.Add(-time.Duration(i+1) * time.Second), Severity: "INFO", Message: "tick"})
+		}
+		return logs
+	}
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		logs := window(at.Add(-24 * time.Hour))
+		if !strings.Contains(filter, `timestamp<"2024-04-30`) {
+			logs = window(at)
+		}
+		if i := strings.Index(filter, ") AND timestamp<"); i >= 0 {
+			oldest, err := time.Parse(time.RFC3339Nano, strings.Trim(filter[i+len(") AND timestamp<"):], `"`))
+			if err != nil {
+				t.Fatalf("Unexpected page filter %q", filter)
+			}
+			for len(logs) > 0 && !logs[0].Timestamp.Before(oldest) {
+				logs = logs[1:]
+			}
+		}
+		return logs[:minInt(100, len(logs))], nil
+	})
+
+	app = pressKeys(app, runeKey('D'))
+	_, cmd := app.handleKeyPress(runeKey('y'))
+	model, _ := app.Update(cmd())
+	app = model.(*App)
+	if len(app.compare.before.logs) != 250 || len(app.compare.after.logs) != 250 {
+		t.Errorf("Expected every page of both windows, got %d and %d entries", len(app.compare.before.logs), len(app.compare.after.logs))
+	}
+}
+
+func TestCompareSplitsEntriesAtTheInstant(t *testing.T) {
+	app := newTabsTestApp(t)
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	app.state.FilterState.TimeRange = models.TimeRange{Start: at.Add(-time.Minute), End: at.Add(time.Minute)}
+	// Several entries a second, the selected one in the middle
+	var logs []models.LogEntry
+	for i := -12; i <= 12; i++ {
+		logs = append(logs, models.LogEntry{ID: fmt.Sprintf("%d", i), Timestamp: at.Add(time.Duration(i) * 250 * time.Millisecond), Severity: "INFO", Message: "tick"})
+	}
+	app.state.LogListState.Logs = []models.LogEntry{logs[12]}
+	app.SetQueryExecutor(cappedTestExecutor(t, logs, 5))
+
+	app = pressKeys(app, runeKey('D'))
+	_, cmd := app.handleKeyPress(runeKey('b'))
+	model, _ := app.Update(cmd())
+	app = model.(*App)
+	if len(app.compare.before.logs) != 12 || len(app.compare.after.logs) != 13 {
+		t.Fatalf("Expected 12 entries before and 13 after, got %d and %d", len(app.compare.before.logs), len(app.compare.after.logs))
+	}
+	for _, entry := range app.compare.before.logs {
+		if entry.ID == "0" {
+			t.Error("Expected the entry at the instant only in the after window")
+		}
+	}
+}
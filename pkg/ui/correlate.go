@@ -0,0 +1,317 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+// followWindow widens the time range of a follow query on both sides, so
+// services that handled the value a little earlier or later are found
+const followWindow = time.Hour
+
+// correlationGroup holds the matches written by one service
+type correlationGroup struct {
+	name    string
+	entries []models.LogEntry
+}
+
+// correlationRow is a group header or one of its entries
+type correlationRow struct {
+	group *correlationGroup
+	entry *models.LogEntry
+}
+
+// correlationView is the state of the follow value view
+type correlationView struct {
+	key     string
+	value   string
+	term    string
+	filter  string
+	origin  string // key of the entry the value was followed from
+	loading bool
+	groups  []*correlationGroup
+	cursor  int
+}
+
+type correlationResultMsg struct {
+	filter string
+	logs   []models.LogEntry
+	err    error
+}
+
+// followValueTerm matches value in the entry field at fields, or anywhere in
+// the entry as a text search. A top-level payload key also matches the label
+// of the same name, and the other way round. With no fields only the text
+// search is used.
+func followValueTerm(fields []string, value string) string {
+	quoted := strconv.Quote(value)
+	var clauses []string
+	switch {
+	case len(fields) == 2 && fields[0] == "jsonPayload",
+		len(fields) == 2 && fields[0] == "labels":
+		clauses = append(clauses,
+			query.FieldPath("jsonPayload", fields[1])+"="+quoted,
+			query.FieldPath("labels", fields[1])+"="+quoted)
+	case len(fields) > 0:
+		clauses = append(clauses, query.FieldPath(fields...)+"="+quoted)
+	}
+	clauses = append(clauses, quoted)
+	return "(" + strings.Join(clauses, " OR ") + ")"
+}
+
+// followWindowRange is the time range widened by followWindow, or the
+// window around ts when no range is set
+func followWindowRange(tr models.TimeRange, ts time.Time) models.TimeRange {
+	if tr.Start.IsZero() || tr.End.IsZero() {
+		return models.TimeRange{Start: ts.Add(-followWindow), End: ts.Add(followWindow)}
+	}
+	return models.TimeRange{Start: tr.Start.Add(-followWindow), End: tr.End.Add(followWindow)}
+}
+
+// correlationGroupName names the service or resource that wrote an entry
+func correlationGroupName(entry models.LogEntry) string {
+	service := traceService(entry)
+	switch {
+	case entry.Resource.Type == "":
+		return service
+	case service == "":
+		return entry.Resource.Type
+	}
+	return entry.Resource.Type + " · " + service
+}
+
+// groupCorrelations groups matches by service. The group of the origin
+// entry comes first, then the others by size.
+func groupCorrelations(logs []models.LogEntry, origin string) []*correlationGroup {
+	byName := map[string]*correlationGroup{}
+	var groups []*correlationGroup
+	originGroup := ""
+	for _, entry := range logs {
+		name := correlationGroupName(entry)
+		group, ok := byName[name]
+		if !ok {
+			group = &correlationGroup{name: name}
+			byName[name] = group
+			groups = append(groups, group)
+		}
+		group.entries = append(group.entries, entry)
+		if logEntryKey(entry) == origin {
+			originGroup = name
+		}
+	}
+	for _, group := range groups {
+		sort.SliceStable(group.entries, func(i, j int) bool {
+			return group.entries[i].Timestamp.Before(group.entries[j].Timestamp)
+		})
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].name == originGroup) != (groups[j].name == originGroup) {
+			return groups[i].name == originGroup
+		}
+		if len(groups[i].entries) != len(groups[j].entries) {
+			return len(groups[i].entries) > len(groups[j].entries)
+		}
+		return groups[i].name < groups[j].name
+	})
+	return groups
+}
+
+func (c *correlationView) rows() []correlationRow {
+	var rows []correlationRow
+	for _, group := range c.groups {
+		rows = append(rows, correlationRow{group: group})
+		for i := range group.entries {
+			rows = append(rows, correlationRow{group: group, entry: &group.entries[i]})
+		}
+	}
+	return rows
+}
+
+// followSelectedValue queries every entry carrying the value of the selected
+// JSON tree node in a widened window and opens the correlation view
+func (a *App) followSelectedValue() tea.Cmd {
+	entry := a.getSelectedLog()
+	if entry == nil || a.detailViewMode != "json-tree" {
+		a.lastErr = "Switch to the JSON tree view to follow a value"
+		return nil
+	}
+	lines := a.currentJSONTreeLines()
+	if a.detailCursor < 0 || a.detailCursor >= len(lines) {
+		a.lastErr = "No payload node selected"
+		return nil
+	}
+	line := lines[a.detailCursor]
+	key := ""
+	for _, segment := range line.segments {
+		if !strings.HasPrefix(segment, "[") {
+			key = segment
+		}
+	}
+	value := ""
+	switch v := line.value.(type) {
+	case string:
+		value = v
+	case float64, bool:
+		value = templateString(v)
+	}
+	if key == "" || strings.TrimSpace(value) == "" {
+		a.lastErr = "Select a value such as a request ID to follow"
+		return nil
+	}
+
+	// JSON parsed from textPayload has no field to query; the text search
+	// still finds it
+	fields, _ := detailNodeField(*entry, line.segments)
+	term := followValueTerm(fields, value)
+	filter := query.NewBuilder("").
+		AddCustomFilter(term).
+		AddTimeRange(followWindowRange(a.state.FilterState.TimeRange, entry.Timestamp)).
+		Build()
+	a.correlation = correlationView{
+		key:     key,
+		value:   value,
+		term:    term,
+		filter:  filter,
+		origin:  logEntryKey(*entry),
+		loading: true,
+	}
+	a.activeModalName = "correlation"
+	if a.queryExec == nil {
+		var matches []models.LogEntry
+		for _, loaded := range a.state.LogListState.Logs {
+			if slices.ContainsFunc(query.EntryTexts(loaded), func(text string) bool { return strings.Contains(text, value) }) {
+				matches = append(matches, loaded)
+			}
+		}
+		a.applyCorrelationResult(correlationResultMsg{filter: filter, logs: matches})
+		return nil
+	}
+	project := a.state.CurrentProject
+	return func() tea.Msg {
+		logs, err := a.execQuery(project, filter)
+		return correlationResultMsg{filter: filter, logs: logs, err: err}
+	}
+}
+
+func (a *App) applyCorrelationResult(msg correlationResultMsg) {
+	if msg.filter != a.correlation.filter {
+		return
+	}
+	a.correlation.loading = false
+	if msg.err != nil {
+		a.lastErr = fmt.Sprintf("Follow query failed: %v", msg.err)
+		return
+	}
+	a.correlation.groups = groupCorrelations(msg.logs, a.correlation.origin)
+	a.correlation.cursor = 0
+	for i, row := range a.correlation.rows() {
+		if row.entry != nil && logEntryKey(*row.entry) == a.correlation.origin {
+			a.correlation.cursor = i
+			break
+		}
+	}
+	a.lastErr = fmt.Sprintf("Followed %s: %d entries from %d services", a.correlation.value, len(msg.logs), len(a.correlation.groups))
+}
+
+// handleCorrelationInput handles keys in the correlation view
+func (a *App) handleCorrelationInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rows := a.correlation.rows()
+	switch msg.String() {
+	case "esc":
+		a.activeModalName = "detailPopup"
+	case "j", "down":
+		a.correlation.cursor = minInt(a.correlation.cursor+1, maxInt(0, len(rows)-1))
+	case "k", "up":
+		a.correlation.cursor = maxInt(0, a.correlation.cursor-1)
+	case "J":
+		a.correlation.cursor = nextCorrelationGroup(rows, a.correlation.cursor, 1)
+	case "K":
+		a.correlation.cursor = nextCorrelationGroup(rows, a.correlation.cursor, -1)
+	case "enter":
+		if a.correlation.cursor < len(rows) && rows[a.correlation.cursor].entry != nil {
+			a.jumpToEntry(*rows[a.correlation.cursor].entry)
+		}
+	case "o":
+		// The query runs in the current time range, without the widening
+		a.activeModalName = "none"
+		a.lastErr = "Query: " + a.correlation.term
+		return a, a.setQueryAndRun(a.correlation.term)
+	}
+	return a, nil
+}
+
+// nextCorrelationGroup returns the row of the next or previous group header
+func nextCorrelationGroup(rows []correlationRow, cursor, dir int) int {
+	for i := cursor + dir; i >= 0 && i < len(rows); i += dir {
+		if rows[i].entry == nil {
+			return i
+		}
+	}
+	return cursor
+}
+
+func (a *App) renderCorrelationPopup() string {
+	var sb strings.Builder
+	popupWidth := minInt(maxInt(70, a.width-10), 140)
+	inner := a.popupInnerWidth(popupWidth)
+	subtle := lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle))
+	c := &a.correlation
+
+	sb.WriteString(a.popupTop(popupWidth, "FOLLOW VALUE"))
+	sb.WriteString(a.popupLine(popupWidth, fmt.Sprintf("%s = %s", c.key, strconv.Quote(c.value))))
+	rows := c.rows()
+	switch {
+	case c.loading:
+		sb.WriteString(a.popupLine(popupWidth, subtle.Render(fmt.Sprintf("Searching all logs within %s of the time range...", followWindow))))
+	default:
+		sb.WriteString(a.popupLine(popupWidth, subtle.Render(fmt.Sprintf("%d entries from %d services, time range widened by %s", len(rows)-len(c.groups), len(c.groups), followWindow))))
+	}
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+	if !c.loading && len(rows) == 0 {
+		sb.WriteString(a.popupLine(popupWidth, "No entries carry this value"))
+	}
+
+	visible := maxInt(3, a.height-14)
+	start := clampInt(c.cursor-visible/2, 0, maxInt(0, len(rows)-visible))
+	end := minInt(len(rows), start+visible)
+	for i := start; i < end; i++ {
+		row := rows[i]
+		selected := i == c.cursor
+		var line string
+		if row.entry == nil {
+			line = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorGCPBlueLight)).Render(
+				fmt.Sprintf("%s (%d)", row.group.name, len(row.group.entries)))
+		} else {
+			mark := "  "
+			if logEntryKey(*row.entry) == c.origin {
+				mark = "◆ "
+			}
+			_, badge := severityBadge(row.entry.Severity)
+			prefix := fmt.Sprintf("  %s%s %-5s ", mark, a.displayTime(row.entry.Timestamp).Format("15:04:05.000"), badge)
+			line = prefix + truncate(listMessage(*row.entry), maxInt(10, inner-lipgloss.Width(prefix)))
+			switch {
+			case mark != "  " && !selected:
+				line = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorGCPWarn)).Render(line)
+			case !selected:
+				line = severityStyle(row.entry.Severity).Render(line)
+			}
+		}
+		if selected {
+			line = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorSelectionFG)).Background(lipgloss.Color(colorSelectionBG)).Render(ansiEscapeRegex.ReplaceAllString(line, ""))
+		}
+		sb.WriteString(a.popupLine(popupWidth, line))
+	}
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+	sb.WriteString(a.popupLine(popupWidth, "◆ followed entry | Enter jump to entry | J/K next/prev service | o open as query | Esc back"))
+	sb.WriteString(a.popupBottom(popupWidth, '━'))
+	return sb.String()
+}
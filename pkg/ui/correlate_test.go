@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func correlationTestLogs() []models.LogEntry {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	entry := func(id, service, message string, offset time.Duration) models.LogEntry {
+		return models.LogEntry{
+			ID:          id,
+			Timestamp:   base.Add(offset),
+			Severity:    "INFO",
+			Message:     message,
+			JSONPayload: map[string]interface{}{"message": message, "requestId": "req-42"},
+			Resource:    models.Resource{Type: "cloud_run_revision", Labels: map[string]string{"service_name": service}},
+		}
+	}
+	return []models.LogEntry{
+		entry("1", "checkout", "order placed", 0),
+		entry("2", "payments", "card charged", -time.Second),
+		entry("3", "payments", "receipt sent", 2*time.Second),
+		entry("4", "checkout", "order confirmed", 3*time.Second),
+		entry("5", "mailer", "mail queued", 4*time.Second),
+	}
+}
+
+func TestFollowValueTerm(t *testing.T) {
+	want := `(jsonPayload.requestId="req-42" OR labels.requestId="req-42" OR "req-42")`
+	if got := followValueTerm([]string{"jsonPayload", "requestId"}, "req-42"); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	if got := followValueTerm([]string{"labels", "k8s-pod/app"}, "api"); !strings.Contains(got, `labels."k8s-pod/app"="api"`) {
+		t.Errorf("Expected a quoted label key, got %s", got)
+	}
+	want = `(jsonPayload.request.id="req-7" OR "req-7")`
+	if got := followValueTerm([]string{"jsonPayload", "request", "id"}, "req-7"); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	if got := followValueTerm(nil, "req-7"); got != `("req-7")` {
+		t.Errorf("Expected only the text search, got %s", got)
+	}
+}
+
+func TestFollowWindowRange(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	got := followWindowRange(models.TimeRange{}, ts)
+	if !got.Start.Equal(ts.Add(-followWindow)) || !got.End.Equal(ts.Add(followWindow)) {
+		t.Errorf("Expected a window around the entry, got %v - %v", got.Start, got.End)
+	}
+	tr := models.TimeRange{Start: ts.Add(-15 * time.Minute), End: ts}
+	got = followWindowRange(tr, ts)
+	if !got.Start.Equal(tr.Start.Add(-followWindow)) || !got.End.Equal(tr.End.Add(followWindow)) {
+		t.Errorf("Expected the time range widened, got %v - %v", got.Start, got.End)
+	}
+}
+
+func TestGroupCorrelationsOriginFirst(t *testing.T) {
+	logs := correlationTestLogs()
+	groups := groupCorrelations(logs, logEntryKey(logs[4]))
+	var names []string
+	for _, group := range groups {
+		names = append(names, group.name)
+	}
+	want := "cloud_run_revision · mailer,cloud_run_revision · checkout,cloud_run_revision · payments"
+	if got := strings.Join(names, ","); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	if payments := groups[2].entries; payments[0].ID != "2" || payments[1].ID != "3" {
+		t.Errorf("Expected the entries of a group in time order, got %s, %s", payments[0].ID, payments[1].ID)
+	}
+}
+
+func TestFollowSelectedValueOpensCorrelationView(t *testing.T) {
+	app := newTabsTestApp(t)
+	logs := correlationTestLogs()
+	app.state.LogListState.Logs = logs
+	var ran string
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		ran = filter
+		return logs, nil
+	})
+
+	newModel, _ := app.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	app = newModel.(*App)
+	app.detailTreeExpanded["$.payload"] = true
+	app.detailCursor = treeLineAt(t, app, "$.payload.requestId")
+
+	newModel, cmd := app.Update(runeKey('F'))
+	app = newModel.(*App)
+	if app.activeModalName != "correlation" || cmd == nil {
+		t.Fatalf("Expected the correlation view and a query, modal=%q", app.activeModalName)
+	}
+	newModel, _ = app.Update(cmd())
+	app = newModel.(*App)
+	if !strings.Contains(ran, `(jsonPayload.requestId="req-42" OR labels.requestId="req-42" OR "req-42")`) || !strings.Contains(ran, "timestamp>=") {
+		t.Errorf("Unexpected follow query %q", ran)
+	}
+
+	view := ansiEscapeRegex.ReplaceAllString(app.View(), "")
+	for _, want := range []string{"FOLLOW VALUE", `requestId = "req-42"`, "5 entries from 3 services", "cloud_run_revision · payments (2)", "◆ "} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the correlation view", want)
+		}
+	}
+	rows := app.correlation.rows()
+	if row := rows[app.correlation.cursor]; row.entry == nil || row.entry.ID != "1" {
+		t.Fatalf("Expected the cursor on the followed entry, got %+v", row)
+	}
+
+	// Next service, then its first entry
+	app = pressKeys(app, runeKey('J'), runeKey('j'), tea.KeyMsg{Type: tea.KeyEnter})
+	if selected := app.getSelectedLog(); app.activeModalName != "none" || selected == nil || selected.ID != "2" {
+		t.Errorf("Expected to jump to entry 2 in the list, modal=%q selected=%+v", app.activeModalName, selected)
+	}
+}
+
+func TestFollowSelectedValueUsesNestedPath(t *testing.T) {
+	app := newTabsTestApp(t)
+	logs := correlationTestLogs()
+	logs[0].JSONPayload["request"] = map[string]interface{}{"id": "req-7"}
+	app.state.LogListState.Logs = logs
+	var ran string
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		ran = filter
+		return logs, nil
+	})
+
+	newModel, _ := app.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	app = newModel.(*App)
+	app.detailTreeExpanded["$.payload"] = true
+	app.detailTreeExpanded["$.payload.request"] = true
+	app.detailCursor = treeLineAt(t, app, "$.payload.request.id")
+
+	newModel, cmd := app.Update(runeKey('F'))
+	app = newModel.(*App)
+	if cmd == nil {
+		t.Fatalf("Expected a follow query, lastErr=%q", app.lastErr)
+	}
+	app.Update(cmd())
+	if !strings.Contains(ran, `(jsonPayload.request.id="req-7" OR "req-7")`) || strings.Contains(ran, "jsonPayload.id") {
+		t.Errorf("Expected the full payload path in the follow query, got %q", ran)
+	}
+}
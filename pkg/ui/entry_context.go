@@ -0,0 +1,334 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+// Context windows: by time around the entry, or by a number of entries on
+// each side
+const (
+	contextBySeconds = "seconds"
+	contextByEntries = "entries"
+)
+
+const (
+	contextDefaultWindow  = 10 * time.Second
+	contextMaxWindow      = time.Hour
+	contextDefaultEntries = 20
+	contextEntriesStep    = 20
+)
+
+// entryContextView is the state of the context view
+type entryContextView struct {
+	anchor      models.LogEntry
+	mode        string
+	window      time.Duration // ± around the anchor
+	entries     int           // ± entries shown in entries mode
+	withLogName bool
+	filter      string
+	loading     bool
+	logs        []models.LogEntry // in time order, anchor included
+	cursor      int
+}
+
+type entryContextResultMsg struct {
+	filter string
+	logs   []models.LogEntry
+	err    error
+}
+
+// entryContextFilter selects what the resource of entry logged within window
+// of it, optionally only in the same log. The active filter is not applied.
+func entryContextFilter(entry models.LogEntry, window time.Duration, withLogName bool) string {
+	builder := query.NewBuilder("").AddResourceFilter(entry.Resource.Type)
+	keys := make([]string, 0, len(entry.Resource.Labels))
+	for key := range entry.Resource.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		builder.AddResourceLabelFilter(key, entry.Resource.Labels[key])
+	}
+	if withLogName && entry.LogName != "" {
+		builder.AddCustomFilter("logName=" + strconv.Quote(entry.LogName))
+	}
+	builder.AddTimeRange(models.TimeRange{Start: entry.Timestamp.Add(-window), End: entry.Timestamp.Add(window)})
+	return builder.Build()
+}
+
+// anchorIndex returns the position of the anchor in logs
+func (c *entryContextView) anchorIndex() int {
+	key := logEntryKey(c.anchor)
+	for i, entry := range c.logs {
+		if logEntryKey(entry) == key {
+			return i
+		}
+	}
+	return 0
+}
+
+// shown returns the entries in view: all of the window, or the entries
+// nearest the anchor in entries mode
+func (c *entryContextView) shown() []models.LogEntry {
+	if c.mode != contextByEntries {
+		return c.logs
+	}
+	idx := c.anchorIndex()
+	return c.logs[maxInt(0, idx-c.entries):minInt(len(c.logs), idx+c.entries+1)]
+}
+
+// openEntryContext shows what the instance of the selected entry logged
+// around it
+func (a *App) openEntryContext() tea.Cmd {
+	entry := a.getSelectedLog()
+	if entry == nil {
+		return nil
+	}
+	if entry.Resource.Type == "" {
+		a.lastErr = "The entry has no resource to take context from"
+		return nil
+	}
+	a.entryContext = entryContextView{
+		anchor:  *entry,
+		mode:    contextBySeconds,
+		window:  contextDefaultWindow,
+		entries: contextDefaultEntries,
+	}
+	a.activeModalName = "entryContext"
+	return a.runEntryContext()
+}
+
+// runEntryContext runs the context query for the current window. The window
+// is paged to the end like load-all: the executor returns the newest page
+// first, which on a busy resource would leave out the entries before the
+// anchor.
+func (a *App) runEntryContext() tea.Cmd {
+	c := &a.entryContext
+	c.filter = entryContextFilter(c.anchor, c.window, c.withLogName)
+	c.loading = true
+	if a.queryExec == nil {
+		return func() tea.Msg {
+			return entryContextResultMsg{filter: c.filter, logs: a.state.LogListState.Logs}
+		}
+	}
+	filter := c.filter
+	project := a.state.CurrentProject
+	return func() tea.Msg {
+		logs, err := a.loadAllPages(project, filter)
+		return entryContextResultMsg{filter: filter, logs: logs, err: err}
+	}
+}
+
+// applyEntryContextResult shows a context query result. In entries mode the
+// window keeps widening until both sides have enough entries.
+func (a *App) applyEntryContextResult(msg entryContextResultMsg) tea.Cmd {
+	c := &a.entryContext
+	if msg.filter != c.filter {
+		return nil
+	}
+	c.loading = false
+	if msg.err != nil {
+		a.lastErr = fmt.Sprintf("Context query failed: %v", msg.err)
+		return nil
+	}
+	anchorKey := logEntryKey(c.anchor)
+	logs := []models.LogEntry{c.anchor}
+	for _, entry := range msg.logs {
+		if logEntryKey(entry) != anchorKey && entryInContext(c.anchor, entry, c.window, c.withLogName) {
+			logs = append(logs, entry)
+		}
+	}
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].Timestamp.Before(logs[j].Timestamp)
+	})
+	c.logs = logs
+
+	idx := c.anchorIndex()
+	if c.mode == contextByEntries && (idx < c.entries || len(logs)-1-idx < c.entries) && c.window < contextMaxWindow {
+		c.window = minDuration(c.window*4, contextMaxWindow)
+		return a.runEntryContext()
+	}
+	c.cursor = 0
+	for i, entry := range c.shown() {
+		if logEntryKey(entry) == anchorKey {
+			c.cursor = i
+		}
+	}
+	a.lastErr = fmt.Sprintf("Context: %d entries within ±%s", len(logs), c.window)
+	return nil
+}
+
+// entryInContext reports whether entry belongs to the context of anchor. The
+// query already selects these; loaded entries used without an executor are
+// checked here.
+func entryInContext(anchor, entry models.LogEntry, window time.Duration, withLogName bool) bool {
+	if entry.Resource.Type != anchor.Resource.Type || len(entry.Resource.Labels) != len(anchor.Resource.Labels) {
+		return false
+	}
+	for key, value := range anchor.Resource.Labels {
+		if entry.Resource.Labels[key] != value {
+			return false
+		}
+	}
+	if withLogName && entry.LogName != anchor.LogName {
+		return false
+	}
+	d := entry.Timestamp.Sub(anchor.Timestamp)
+	return d >= -window && d <= window
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// widenEntryContext grows (+1) or shrinks (-1) the window and re-runs the
+// query when the loaded window no longer covers it
+func (a *App) widenEntryContext(dir int) tea.Cmd {
+	c := &a.entryContext
+	if c.mode == contextByEntries {
+		c.entries = maxInt(contextEntriesStep/2, c.entries+dir*contextEntriesStep)
+		idx := c.anchorIndex()
+		if dir > 0 && (idx < c.entries || len(c.logs)-1-idx < c.entries) && c.window < contextMaxWindow {
+			return a.runEntryContext()
+		}
+		a.lastErr = fmt.Sprintf("Context: ±%d entries", c.entries)
+		return nil
+	}
+	if dir > 0 {
+		if c.window >= contextMaxWindow {
+			a.lastErr = fmt.Sprintf("Context is at its widest (±%s)", contextMaxWindow)
+			return nil
+		}
+		c.window = minDuration(c.window*2, contextMaxWindow)
+		return a.runEntryContext()
+	}
+	if c.window <= time.Second {
+		return nil
+	}
+	c.window = maxDuration(c.window/2, time.Second)
+	return a.runEntryContext()
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// handleEntryContextInput handles keys in the context view
+func (a *App) handleEntryContextInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := &a.entryContext
+	shown := c.shown()
+	switch msg.String() {
+	case "esc", "x":
+		a.activeModalName = "none"
+	case "j", "down":
+		c.cursor = minInt(c.cursor+1, maxInt(0, len(shown)-1))
+	case "k", "up":
+		c.cursor = maxInt(0, c.cursor-1)
+	case "+", "=":
+		return a, a.widenEntryContext(1)
+	case "-":
+		return a, a.widenEntryContext(-1)
+	case "m":
+		if c.mode == contextBySeconds {
+			c.mode = contextByEntries
+		} else {
+			c.mode = contextBySeconds
+		}
+		return a, a.runEntryContext()
+	case "l":
+		c.withLogName = !c.withLogName
+		return a, a.runEntryContext()
+	case "enter":
+		if c.cursor < len(shown) {
+			a.jumpToEntry(shown[c.cursor])
+		}
+	}
+	return a, nil
+}
+
+// contextOffset renders the time from the anchor, e.g. "-1.2s" or "+300ms"
+func contextOffset(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "-" + formatLatency(-d)
+	case d > 0:
+		return "+" + formatLatency(d)
+	}
+	return "0"
+}
+
+func (a *App) renderEntryContextPopup() string {
+	var sb strings.Builder
+	popupWidth := minInt(maxInt(70, a.width-10), 140)
+	inner := a.popupInnerWidth(popupWidth)
+	subtle := lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle))
+	c := &a.entryContext
+
+	sb.WriteString(a.popupTop(popupWidth, "CONTEXT"))
+	scope := c.anchor.Resource.Type
+	if name := traceService(c.anchor); name != "" {
+		scope += " · " + name
+	}
+	if c.withLogName {
+		scope += " · log " + models.LogID(c.anchor.LogName)
+	}
+	sb.WriteString(a.popupLine(popupWidth, scope))
+	status := fmt.Sprintf("±%s around %s, ignoring the query", c.window, a.displayTime(c.anchor.Timestamp).Format("15:04:05.000"))
+	if c.mode == contextByEntries {
+		status = fmt.Sprintf("±%d entries (searched ±%s), ignoring the query", c.entries, c.window)
+	}
+	if c.loading {
+		status = "Querying the context..."
+	}
+	sb.WriteString(a.popupLine(popupWidth, subtle.Render(status)))
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+
+	shown := c.shown()
+	anchorKey := logEntryKey(c.anchor)
+	visible := maxInt(3, a.height-14)
+	start := clampInt(c.cursor-visible/2, 0, maxInt(0, len(shown)-visible))
+	end := minInt(len(shown), start+visible)
+	for i := start; i < end; i++ {
+		entry := shown[i]
+		anchor := logEntryKey(entry) == anchorKey
+		mark := "  "
+		if anchor {
+			mark = "◆ "
+		}
+		_, badge := severityBadge(entry.Severity)
+		prefix := fmt.Sprintf("%s%s %-5s ", mark, fitCell(contextOffset(entry.Timestamp.Sub(c.anchor.Timestamp)), 8, columnAlignRight), badge)
+		line := prefix + truncate(listMessage(entry), maxInt(10, inner-lipgloss.Width(prefix)))
+		switch {
+		case i == c.cursor:
+			line = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorSelectionFG)).Background(lipgloss.Color(colorSelectionBG)).Render(line)
+		case anchor:
+			line = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorGCPWarn)).Render(line)
+		default:
+			line = severityStyle(entry.Severity).Render(line)
+		}
+		sb.WriteString(a.popupLine(popupWidth, line))
+	}
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+	logHint := "l same log only"
+	if c.withLogName {
+		logHint = "l all logs"
+	}
+	sb.WriteString(a.popupLine(popupWidth, fmt.Sprintf("◆ selected entry | +/- widen/narrow | m seconds/entries | %s | Enter jump | Esc close", logHint)))
+	sb.WriteString(a.popupBottom(popupWidth, '━'))
+	return sb.String()
+}
@@ -0,0 +1,231 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func entryContextTestLogs() []models.LogEntry {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	entry := func(id, service, logName string, offset time.Duration) models.LogEntry {
+		return models.LogEntry{
+			ID:        id,
+			Timestamp: base.Add(offset),
+			Severity:  "INFO",
+			Message:   "line " + id,
+			LogName:   logName,
+			Resource:  models.Resource{Type: "cloud_run_revision", Labels: map[string]string{"service_name": service}},
+		}
+	}
+	return []models.LogEntry{
+		entry("1", "api", "projects/p/logs/stdout", 0),
+		entry("2", "api", "projects/p/logs/stderr", -3*time.Second),
+		entry("3", "api", "projects/p/logs/stdout", 4*time.Second),
+		entry("4", "worker", "projects/p/logs/stdout", time.Second),
+		entry("5", "api", "projects/p/logs/stdout", 25*time.Second),
+	}
+}
+
+func TestEntryContextFilter(t *testing.T) {
+	anchor := entryContextTestLogs()[0]
+	got := entryContextFilter(anchor, 10*time.Second, true)
+	for _, want := range []string{
+		`resource.type="cloud_run_revision"`,
+		`resource.labels.service_name="api"`,
+		`logName="projects/p/logs/stdout"`,
+		`timestamp>="2024-05-01T09:59:50Z"`,
+		`timestamp<="2024-05-01T10:00:10Z"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %s in %s", want, got)
+		}
+	}
+	if got := entryContextFilter(anchor, time.Second, false); strings.Contains(got, "logName") {
+		t.Errorf("Expected no log name, got %s", got)
+	}
+}
+
+func TestContextOffset(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                      "0",
+		-3 * time.Second:       "-3.0s",
+		300 * time.Millisecond: "+300ms",
+	} {
+		if got := contextOffset(d); got != want {
+			t.Errorf("contextOffset(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestEntryContextIgnoresQueryAndWidens(t *testing.T) {
+	app := newTabsTestApp(t)
+	logs := entryContextTestLogs()
+	app.state.LogListState.Logs = logs[:1]
+	app.state.FilterState.Severity = models.SeverityFilter{MinLevel: "ERROR"}
+	var ran []string
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		ran = append(ran, filter)
+		return logs, nil
+	})
+
+	_, cmd := app.handleKeyPress(runeKey('x'))
+	if cmd == nil || app.activeModalName != "entryContext" {
+		t.Fatal("Expected x to open the context view and query it")
+	}
+	model, _ := app.Update(cmd())
+	app = model.(*App)
+	if strings.Contains(ran[0], "severity") {
+		t.Errorf("Expected the active query to be ignored, got %s", ran[0])
+	}
+	var ids []string
+	for _, entry := range app.entryContext.shown() {
+		ids = append(ids, entry.ID)
+	}
+	if got := strings.Join(ids, ","); got != "2,1,3" {
+		t.Fatalf("Expected the api entries within 10s in time order, got %s", got)
+	}
+	if app.entryContext.cursor != 1 {
+		t.Errorf("Expected the cursor on the selected entry, got %d", app.entryContext.cursor)
+	}
+	view := ansiEscapeRegex.ReplaceAllString(app.View(), "")
+	for _, want := range []string{"CONTEXT", "◆", "-3.0s", "+4.0s", "ignoring the query"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the context view", want)
+		}
+	}
+
+	// Widen to 20s, then 40s which reaches entry 5
+	for i := 0; i < 2; i++ {
+		model, cmd = app.Update(runeKey('+'))
+		app = model.(*App)
+		model, _ = app.Update(cmd())
+		app = model.(*App)
+	}
+	if app.entryContext.window != 40*time.Second || len(app.entryContext.shown()) != 4 {
+		t.Errorf("Expected 4 entries within 40s, got %d within %s", len(app.entryContext.shown()), app.entryContext.window)
+	}
+
+	// Same log only drops the stderr entry
+	model, cmd = app.Update(runeKey('l'))
+	app = model.(*App)
+	model, _ = app.Update(cmd())
+	app = model.(*App)
+	if len(app.entryContext.shown()) != 3 || !strings.Contains(ran[len(ran)-1], "logName=") {
+		t.Errorf("Expected the log name filter, got %d entries", len(app.entryContext.shown()))
+	}
+}
+
+func TestEntryContextByEntriesWidensUntilFilled(t *testing.T) {
+	app := newTabsTestApp(t)
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var logs []models.LogEntry
+	for i := -30; i <= 30; i++ {
+		logs = append(logs, models.LogEntry{
+			ID:        fmt.Sprintf("%d", i),
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+			Resource:  models.Resource{Type: "gce_instance", Labels: map[string]string{"instance_id": "42"}},
+		})
+	}
+	app.state.LogListState.Logs = logs[30:31]
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		return logs, nil
+	})
+
+	cmd := app.openEntryContext()
+	model, _ := app.Update(cmd())
+	app = model.(*App)
+	model, cmd = app.Update(runeKey('m'))
+	app = model.(*App)
+	for cmd != nil {
+		model, cmd = app.Update(cmd())
+		app = model.(*App)
+	}
+	shown := app.entryContext.shown()
+	if len(shown) != 41 || shown[0].ID != "-20" || shown[40].ID != "20" {
+		t.Fatalf("Expected 20 entries on each side, got %d", len(shown))
+	}
+	if app.entryContext.window != 40*time.Second {
+		t.Errorf("Expected the window widened to 40s, got %s", app.entryContext.window)
+	}
+	if shown[app.entryContext.cursor].ID != "0" {
+		t.Errorf("Expected the cursor on the selected entry, got %s", shown[app.entryContext.cursor].ID)
+	}
+
+	// Only the selected entry is loaded in the list
+	app = pressKeys(app, runeKey('k'), tea.KeyMsg{Type: tea.KeyEnter})
+	if app.activeModalName != "entryContext" || !strings.Contains(app.lastErr, "not in the log list") {
+		t.Errorf("Expected to stay in the context view, got %q: %s", app.activeModalName, app.lastErr)
+	}
+	app = pressKeys(app, runeKey('j'), tea.KeyMsg{Type: tea.KeyEnter})
+	if app.activeModalName != "none" {
+		t.Errorf("Expected Enter to return to the list, got %q", app.activeModalName)
+	}
+}
+
+// cappedTestExecutor returns the newest limit entries of logs that match the
+// timestamp clauses of a filter, like a page of the gcloud executor
+func cappedTestExecutor(t *testing.T, logs []models.LogEntry, limit int) func(string) ([]models.LogEntry, error) {
+	clauseRe := regexp.MustCompile(`timestamp(>=|<=|<)"([^"]+)"`)
+	return func(filter string) ([]models.LogEntry, error) {
+		var page []models.LogEntry
+		for i := len(logs) - 1; i >= 0 && len(page) < limit; i-- {
+			ok := true
+			for _, m := range clauseRe.FindAllStringSubmatch(filter, -1) {
+				at, err := time.Parse(time.RFC3339Nano, m[2])
+				if err != nil {
+					t.Fatalf("Unexpected timestamp in %q", filter)
+				}
+				ts := logs[i].Timestamp
+				switch m[1] {
+				case ">=":
+					ok = ok && !ts.Before(at)
+				case "<=":
+					ok = ok && !ts.After(at)
+				case "<":
+					ok = ok && ts.Before(at)
+				}
+			}
+			if ok {
+				page = append(page, logs[i])
+			}
+		}
+		return page, nil
+	}
+}
+
+func TestEntryContextLoadsBothSidesOfBusyResource(t *testing.T) {
+	app := newTabsTestApp(t)
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var logs []models.LogEntry
+	for i := -8; i <= 8; i++ {
+		logs = append(logs, models.LogEntry{
+			ID:        fmt.Sprintf("%d", i),
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+			Resource:  models.Resource{Type: "gce_instance", Labels: map[string]string{"instance_id": "42"}},
+		})
+	}
+	app.state.LogListState.Logs = logs[8:9]
+	app.SetQueryExecutor(cappedTestExecutor(t, logs, 5))
+
+	cmd := app.openEntryContext()
+	model, _ := app.Update(cmd())
+	app = model.(*App)
+	shown := app.entryContext.shown()
+	if len(shown) != 17 || shown[0].ID != "-8" || shown[16].ID != "8" {
+		t.Fatalf("Expected every entry within 10s on both sides, got %d", len(shown))
+	}
+}
+
+func TestEntryContextNeedsResource(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.state.LogListState.Logs = []models.LogEntry{{ID: "1", Message: "bare"}}
+	if cmd := app.openEntryContext(); cmd != nil || app.activeModalName == "entryContext" {
+		t.Error("Expected no context view for an entry without a resource")
+	}
+}
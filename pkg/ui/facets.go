@@ -0,0 +1,397 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/config"
+	"github.com/user/log-explorer-tui/pkg/models"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+// facetTopValues is how many values each facet lists
+const facetTopValues = 5
+
+// defaultFacetFields are always counted; pinned jsonPayload fields follow
+var defaultFacetFields = []string{
+	"severity",
+	"resource.type",
+	"resource.labels.service_name",
+	"resource.labels.namespace_name",
+	"resource.labels.pod_name",
+	"logName",
+}
+
+type facetValue struct {
+	value string
+	count int
+}
+
+type facetGroup struct {
+	field  string
+	values []facetValue // by count, then value
+	pinned bool
+}
+
+type facetCache struct {
+	view   *logView
+	fields string
+	groups []facetGroup
+}
+
+// facetRow is a selectable sidebar row: an active query facet or a value
+type facetRow struct {
+	customKey string // CustomFilters key for rows already in the query
+	field     string
+	value     string
+	pinned    bool
+}
+
+// SetFacetFields sets the jsonPayload fields pinned in the facet sidebar
+func (a *App) SetFacetFields(fields []string) {
+	a.facetFields = nil
+	for _, field := range fields {
+		if field = normalizeFacetField(field); field != "" && !slices.Contains(a.facetFields, field) {
+			a.facetFields = append(a.facetFields, field)
+		}
+	}
+}
+
+// normalizeFacetField puts bare field names under jsonPayload
+func normalizeFacetField(field string) string {
+	parts := models.SplitFieldPath(strings.TrimSpace(field))
+	if len(parts) == 0 {
+		return ""
+	}
+	switch parts[0] {
+	case "jsonPayload", "labels", "resource", "severity", "logName", "textPayload", "trace", "sourceLocation":
+	default:
+		parts = append([]string{"jsonPayload"}, parts...)
+	}
+	return query.FieldPath(parts...)
+}
+
+// computeFacets counts field values over logs. Entries without the field
+// are not counted.
+func computeFacets(logs []models.LogEntry, fields []string, pinned map[string]bool) []facetGroup {
+	groups := make([]facetGroup, 0, len(fields))
+	for _, field := range fields {
+		counts := map[string]int{}
+		for _, entry := range logs {
+			if value, ok := entry.FieldValue(field); ok && value != "" {
+				counts[value]++
+			}
+		}
+		group := facetGroup{field: field, pinned: pinned[field]}
+		for value, count := range counts {
+			group.values = append(group.values, facetValue{value: value, count: count})
+		}
+		sort.Slice(group.values, func(i, j int) bool {
+			if group.values[i].count != group.values[j].count {
+				return group.values[i].count > group.values[j].count
+			}
+			return group.values[i].value < group.values[j].value
+		})
+		if len(group.values) > 0 || group.pinned {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// facets returns the facet groups for the shown entries, recomputed only
+// when the view or the pinned fields change
+func (a *App) facets() []facetGroup {
+	v := a.view()
+	fields := strings.Join(a.facetFields, "\x00")
+	if a.facetCache != nil && a.facetCache.view == v && a.facetCache.fields == fields {
+		return a.facetCache.groups
+	}
+	pinned := make(map[string]bool, len(a.facetFields))
+	all := append([]string{}, defaultFacetFields...)
+	for _, field := range a.facetFields {
+		pinned[field] = true
+		if !slices.Contains(all, field) {
+			all = append(all, field)
+		}
+	}
+	a.facetCache = &facetCache{view: v, fields: fields, groups: computeFacets(v.logs, all, pinned)}
+	return a.facetCache.groups
+}
+
+// sortedCustomFilterKeys lists CustomFilters keys by field path
+func (a *App) sortedCustomFilterKeys() []string {
+	keys := make([]string, 0, len(a.state.FilterState.CustomFilters))
+	for key := range a.state.FilterState.CustomFilters {
+		keys = append(keys, key)
+	}
+	query.SortCustomFilterKeys(keys, a.state.FilterState.CustomFilters)
+	return keys
+}
+
+func (a *App) facetRows() []facetRow {
+	var rows []facetRow
+	for _, key := range a.sortedCustomFilterKeys() {
+		path, _ := query.CustomFilterPath(key)
+		rows = append(rows, facetRow{customKey: key, field: path, value: a.state.FilterState.CustomFilters[key]})
+	}
+	for _, group := range a.facets() {
+		for i, v := range group.values {
+			if i == facetTopValues {
+				break
+			}
+			rows = append(rows, facetRow{field: group.field, value: v.value, pinned: group.pinned})
+		}
+	}
+	return rows
+}
+
+func (a *App) selectedFacetRow() (facetRow, bool) {
+	rows := a.facetRows()
+	if len(rows) == 0 {
+		return facetRow{}, false
+	}
+	a.facetCursor = clampInt(a.facetCursor, 0, len(rows)-1)
+	return rows[a.facetCursor], true
+}
+
+func clampInt(v, lo, hi int) int {
+	return maxInt(lo, minInt(v, hi))
+}
+
+// toggleFacets opens the sidebar with the focus on it, or closes it when it
+// already has the focus
+func (a *App) toggleFacets() {
+	if a.facetsOpen && a.activeModalName == "facets" {
+		a.facetsOpen = false
+		a.activeModalName = "none"
+		return
+	}
+	a.facetsOpen = true
+	a.activeModalName = "facets"
+}
+
+// handleFacetInput handles keys while the facet sidebar has the focus
+func (a *App) handleFacetInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "tab":
+		a.activeModalName = "none"
+		return a, nil
+	case "b":
+		a.toggleFacets()
+		return a, nil
+	case "j", "down":
+		a.facetCursor++
+		a.selectedFacetRow()
+		return a, nil
+	case "k", "up":
+		a.facetCursor = maxInt(0, a.facetCursor-1)
+		return a, nil
+	case "p":
+		a.prompt.Open("facetPin", "PIN FACET FIELD", "")
+		a.prompt.SetHint("jsonPayload field such as user.id or jsonPayload.httpRequest.status")
+		a.activeModalName = "prompt"
+		return a, nil
+	}
+
+	row, ok := a.selectedFacetRow()
+	if !ok {
+		return a, nil
+	}
+	switch msg.String() {
+	case "i", "enter":
+		a.addFacetLocalFilter(row, false)
+	case "x":
+		a.addFacetLocalFilter(row, true)
+	case "I":
+		return a, a.setFacetQueryFilter(row, false)
+	case "X":
+		return a, a.setFacetQueryFilter(row, true)
+	case "d":
+		if row.customKey != "" {
+			delete(a.state.FilterState.CustomFilters, row.customKey)
+			a.lastErr = "Removed from query: " + row.field
+			return a, a.rerunQueryCmd()
+		}
+		if row.pinned {
+			a.unpinFacetField(row.field)
+		}
+	}
+	return a, nil
+}
+
+// facetClause renders field="value", or NOT field="value" when excluding
+func facetClause(field, value string, exclude bool) string {
+	clause := query.FieldPath(models.SplitFieldPath(field)...) + "=" + strconv.Quote(value)
+	if exclude {
+		return "NOT " + clause
+	}
+	return clause
+}
+
+// addFacetLocalFilter narrows the loaded logs by a facet value without a
+// new query
+func (a *App) addFacetLocalFilter(row facetRow, exclude bool) {
+	clause := facetClause(row.field, row.value, exclude)
+	a.updateLocalFilters(func(fs []models.LocalFilter) []models.LocalFilter {
+		return append(fs, models.LocalFilter{Kind: query.LocalKindQuery, Expr: clause, Enabled: true})
+	})
+	a.lastErr = fmt.Sprintf("Local filter %s: %s", clause, a.shownCountLabel())
+}
+
+// setFacetQueryFilter adds a facet value to the server-side query through
+// FilterState.CustomFilters and re-runs it
+func (a *App) setFacetQueryFilter(row facetRow, exclude bool) tea.Cmd {
+	key := query.CustomFilterKey(query.FieldPath(models.SplitFieldPath(row.field)...), row.value, exclude)
+	if a.state.FilterState.CustomFilters == nil {
+		a.state.FilterState.CustomFilters = map[string]string{}
+	}
+	a.state.FilterState.CustomFilters[key] = row.value
+	a.lastErr = "Added to query: " + facetClause(row.field, row.value, exclude)
+	return a.rerunQueryCmd()
+}
+
+func (a *App) rerunQueryCmd() tea.Cmd {
+	a.facetCursor = 0
+	if a.queryExec == nil {
+		return nil
+	}
+	return a.executePrimaryQueryCmd(a.buildEffectiveFilter(""))
+}
+
+func (a *App) pinFacetField(field string) {
+	a.activeModalName = "facets"
+	field = normalizeFacetField(field)
+	if field == "" || slices.Contains(a.facetFields, field) {
+		return
+	}
+	a.facetFields = append(a.facetFields, field)
+	a.persistPreferences(func(p *config.Preferences) { p.FacetFields = slices.Clone(a.facetFields) })
+	a.lastErr = "Pinned facet " + field
+}
+
+func (a *App) unpinFacetField(field string) {
+	fields := make([]string, 0, len(a.facetFields))
+	for _, f := range a.facetFields {
+		if f != field {
+			fields = append(fields, f)
+		}
+	}
+	a.facetFields = fields
+	a.persistPreferences(func(p *config.Preferences) { p.FacetFields = slices.Clone(a.facetFields) })
+	a.lastErr = "Unpinned facet " + field
+}
+
+// facetSidebarWidth is the sidebar width for the current terminal width,
+// or 0 when the terminal is too narrow to show it
+func (a *App) facetSidebarWidth() int {
+	if a.width < 60 {
+		return 0
+	}
+	return clampInt(a.width/4, 26, 44)
+}
+
+// renderFacetLayout draws the facet sidebar left of the log panel(s)
+func (a *App) renderFacetLayout(logsHeight int, renderLogs func(int) (string, int, int)) (string, int, int) {
+	sidebarWidth := a.facetSidebarWidth()
+	if sidebarWidth == 0 {
+		return renderLogs(logsHeight)
+	}
+	width := a.width
+	a.width = width - sidebarWidth
+	logsPanel, windowStart, windowEnd := renderLogs(logsHeight)
+	a.width = sidebarWidth
+	sidebar := a.renderFacetSidebar(strings.Count(logsPanel, "\n"))
+	a.width = width
+	return renderHorizontalSplit([]string{sidebar, logsPanel}, []int{sidebarWidth, width - sidebarWidth}) + "\n", windowStart, windowEnd
+}
+
+// renderFacetSidebar renders the sidebar with exactly height lines, using
+// a.width as its width
+func (a *App) renderFacetSidebar(height int) string {
+	focused := a.activeModalName == "facets"
+	subtle := lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle))
+	selected := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorSelectionFG)).Background(lipgloss.Color(colorSelectionBG))
+	inner := a.panelInnerWidth()
+
+	var body []string
+	cursorLine := -1
+	rowIdx := 0
+	addRow := func(text string) {
+		text = truncate(text, inner)
+		if rowIdx == a.facetCursor && focused {
+			cursorLine = len(body)
+			text = selected.Width(inner).MaxWidth(inner).Render(text)
+		}
+		body = append(body, text)
+		rowIdx++
+	}
+
+	if keys := a.sortedCustomFilterKeys(); len(keys) > 0 {
+		body = append(body, subtle.Render("in query"))
+		for _, key := range keys {
+			path, exclude := query.CustomFilterPath(key)
+			mark := "+"
+			if exclude {
+				mark = "-"
+			}
+			addRow(fmt.Sprintf(" %s %s=%s", mark, path, a.state.FilterState.CustomFilters[key]))
+		}
+	}
+	for _, group := range a.facets() {
+		title := group.field
+		if group.pinned {
+			title += " (pinned)"
+		}
+		body = append(body, subtle.Render(truncate(title, inner)))
+		if len(group.values) == 0 {
+			body = append(body, subtle.Render("   no values"))
+		}
+		for i, v := range group.values {
+			if i == facetTopValues {
+				body = append(body, subtle.Render(fmt.Sprintf("   +%d more", len(group.values)-facetTopValues)))
+				break
+			}
+			addRow(fmt.Sprintf("%5d %s", v.count, v.value))
+		}
+	}
+	if rowIdx == 0 && len(body) == 0 {
+		body = append(body, subtle.Render("No values in the loaded logs"))
+	}
+
+	// Frame: top, title, separator, body, separator, hint. Like the log
+	// panel, the sidebar is closed by the panel below it.
+	bodyHeight := maxInt(1, height-5)
+	start := 0
+	if cursorLine >= bodyHeight {
+		start = cursorLine - bodyHeight + 1
+	}
+	end := minInt(len(body), start+bodyHeight)
+
+	var sb strings.Builder
+	sb.WriteString(a.panelTop())
+	title := fmt.Sprintf("FACETS (%s)", formatCount(len(a.viewLogs())))
+	if focused {
+		title = "▶ " + title
+	}
+	sb.WriteString(a.panelLine(lipgloss.NewStyle().Bold(true).Render(title)))
+	sb.WriteString(a.panelSeparator('━'))
+	for _, line := range body[start:end] {
+		sb.WriteString(a.panelLine(line))
+	}
+	for i := end - start; i < bodyHeight; i++ {
+		sb.WriteString(a.panelLine(""))
+	}
+	sb.WriteString(a.panelSeparator('━'))
+	hint := "b: focus facets"
+	if focused {
+		hint = "i/x local  I/X query"
+	}
+	sb.WriteString(a.panelLine(subtle.Render(truncate(hint, inner))))
+	return sb.String()
+}
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+func facetTestLogs() []models.LogEntry {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	service := func(name string) models.Resource {
+		return models.Resource{Type: "cloud_run_revision", Labels: map[string]string{"service_name": name}}
+	}
+	return []models.LogEntry{
+		{Timestamp: base, Severity: "INFO", Message: "a", Resource: service("api"), JSONPayload: map[string]interface{}{"user": "u1"}},
+		{Timestamp: base.Add(time.Minute), Severity: "ERROR", Message: "b", Resource: service("api"), JSONPayload: map[string]interface{}{"user": "u2"}},
+		{Timestamp: base.Add(2 * time.Minute), Severity: "ERROR", Message: "c", Resource: service("billing")},
+		{Timestamp: base.Add(3 * time.Minute), Severity: "INFO", Message: "d", Resource: service("api"), JSONPayload: map[string]interface{}{"user": "u1"}},
+	}
+}
+
+func newFacetTestApp(t *testing.T) *App {
+	t.Helper()
+	app := newTabsTestApp(t)
+	app.state.LogListState.Logs = facetTestLogs()
+	app.SetFacetFields([]string{"user"})
+	return app
+}
+
+func TestComputeFacets(t *testing.T) {
+	groups := computeFacets(facetTestLogs(), []string{"severity", "resource.labels.service_name", "jsonPayload.user", "logName"}, nil)
+	if len(groups) != 3 {
+		t.Fatalf("Expected empty facets to be dropped, got %d groups", len(groups))
+	}
+	if v := groups[1].values; len(v) != 2 || v[0].value != "api" || v[0].count != 3 {
+		t.Errorf("Unexpected service facet %+v", v)
+	}
+	if v := groups[0].values; v[0].value != "ERROR" || v[1].value != "INFO" {
+		t.Errorf("Expected ties ordered by value, got %+v", v)
+	}
+	if v := groups[2].values; len(v) != 2 || v[0].count != 2 {
+		t.Errorf("Expected entries without the field to be skipped, got %+v", v)
+	}
+}
+
+func TestFacetSidebarLocalInclude(t *testing.T) {
+	app := newFacetTestApp(t)
+	if app.facetFields[0] != "jsonPayload.user" {
+		t.Fatalf("Expected bare pinned field under jsonPayload, got %v", app.facetFields)
+	}
+	app = pressKeys(app, runeKey('b'))
+	if !app.facetsOpen || app.activeModalName != "facets" {
+		t.Fatalf("Expected b to open and focus the sidebar")
+	}
+	view := app.View()
+	if !strings.Contains(view, "FACETS (4)") || !strings.Contains(view, "resource.labels.service") || !strings.Contains(view, "jsonPayload.user (pinned)") {
+		t.Error("Expected facet groups in the sidebar")
+	}
+
+	// Rows: ERROR, INFO, cloud_run_revision, api, billing, u1, u2
+	app = pressKeys(app, runeKey('j'), runeKey('j'), runeKey('j'), runeKey('x'))
+	if len(app.viewLogs()) != 1 || app.viewLogs()[0].Message != "c" {
+		t.Fatalf("Expected excluding api to leave one entry, got %d", len(app.viewLogs()))
+	}
+	if got := app.state.FilterState.LocalFilters[0].Expr; got != `NOT resource.labels.service_name="api"` {
+		t.Errorf("Unexpected local filter %q", got)
+	}
+	if !strings.Contains(app.View(), "FACETS (1)") {
+		t.Error("Expected facets to follow the shown entries")
+	}
+
+	app = pressKeys(app, tea.KeyMsg{Type: tea.KeyEsc})
+	if !app.facetsOpen || app.activeModalName != "none" {
+		t.Error("Esc should return the focus to the list and keep the sidebar")
+	}
+	app = pressKeys(app, runeKey('b'), runeKey('b'))
+	if app.facetsOpen {
+		t.Error("b in the focused sidebar should close it")
+	}
+}
+
+func TestFacetSidebarServerFilter(t *testing.T) {
+	app := newFacetTestApp(t)
+	var filters []string
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		filters = append(filters, filter)
+		return facetTestLogs(), nil
+	})
+	app = pressKeys(app, runeKey('b'))
+	newModel, cmd := app.Update(runeKey('I'))
+	app = newModel.(*App)
+	if app.state.FilterState.CustomFilters[query.CustomFilterKey("severity", "ERROR", false)] != "ERROR" || cmd == nil {
+		t.Fatalf("Expected severity facet in CustomFilters, got %v", app.state.FilterState.CustomFilters)
+	}
+	cmd()
+	if len(filters) != 1 || !strings.Contains(filters[0], `severity="ERROR"`) || !strings.Contains(filters[0], `resource.type="api"`) {
+		t.Errorf("Expected facet clause next to the query, got %v", filters)
+	}
+	if !strings.Contains(app.View(), "in query") {
+		t.Error("Expected active query facets in the sidebar")
+	}
+
+	// The active facet is the first row; d removes it
+	app.facetCursor = 0
+	newModel, cmd = app.Update(runeKey('d'))
+	app = newModel.(*App)
+	if len(app.state.FilterState.CustomFilters) != 0 || cmd == nil {
+		t.Errorf("Expected d to remove the facet and rerun, got %v", app.state.FilterState.CustomFilters)
+	}
+}
+
+func TestFacetSidebarServerExcludesSeveralValues(t *testing.T) {
+	app := newFacetTestApp(t)
+	var filters []string
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		filters = append(filters, filter)
+		return facetTestLogs(), nil
+	})
+	app = pressKeys(app, runeKey('b'))
+	for _, value := range []string{"api", "billing"} {
+		app.facetCursor = -1
+		for i, row := range app.facetRows() {
+			if row.customKey == "" && row.field == "resource.labels.service_name" && row.value == value {
+				app.facetCursor = i
+			}
+		}
+		if app.facetCursor < 0 {
+			t.Fatalf("No facet row for service %s", value)
+		}
+		newModel, cmd := app.Update(runeKey('X'))
+		app = newModel.(*App)
+		cmd()
+	}
+	if len(app.state.FilterState.CustomFilters) != 2 {
+		t.Fatalf("Expected both excludes kept, got %q", app.state.FilterState.CustomFilters)
+	}
+	last := filters[len(filters)-1]
+	if !strings.Contains(last, `NOT resource.labels.service_name="api"`) || !strings.Contains(last, `NOT resource.labels.service_name="billing"`) {
+		t.Errorf("Expected both excludes in the query, got %q", last)
+	}
+	view := app.View()
+	if strings.Count(view, " - resource.labels.serv") != 2 {
+		t.Error("Expected both excludes listed in the sidebar")
+	}
+
+	// d removes only the selected value
+	app.facetCursor = 0
+	newModel, _ := app.Update(runeKey('d'))
+	app = newModel.(*App)
+	if len(app.state.FilterState.CustomFilters) != 1 || app.state.FilterState.CustomFilters[query.CustomFilterKey("resource.labels.service_name", "billing", true)] != "billing" {
+		t.Errorf("Expected d to remove one exclude, got %q", app.state.FilterState.CustomFilters)
+	}
+}
+
+func TestFacetPinPrompt(t *testing.T) {
+	app := pressKeys(newFacetTestApp(t), runeKey('b'), runeKey('p'))
+	for _, r := range "labels.\"k8s-pod/app\"" {
+		app = pressKeys(app, runeKey(r))
+	}
+	app = pressKeys(app, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(app.facetFields) != 2 || app.facetFields[1] != `labels."k8s-pod/app"` || app.activeModalName != "facets" {
+		t.Errorf("Expected pinned label field, got %v (modal %q)", app.facetFields, app.activeModalName)
+	}
+}
@@ -0,0 +1,257 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+// compiledLocalFilters compiles the enabled local filters of the active tab.
+// Filters that no longer compile are skipped; they are validated on entry.
+func (a *App) compiledLocalFilters() []*query.LocalFilter {
+	var out []*query.LocalFilter
+	for _, lf := range a.state.FilterState.LocalFilters {
+		if !lf.Enabled {
+			continue
+		}
+		if compiled, err := query.CompileLocalFilter(lf.Kind, lf.Expr); err == nil {
+			out = append(out, compiled)
+		}
+	}
+	if clause := a.localSeverityClause(); clause != "" {
+		if compiled, err := query.CompileLocalFilter(query.LocalKindQuery, clause); err == nil {
+			out = append(out, compiled)
+		}
+	}
+	return out
+}
+
+// localFiltersKey identifies the local filter stack for the view cache
+func (a *App) localFiltersKey() string {
+	var sb strings.Builder
+	for _, lf := range a.state.FilterState.LocalFilters {
+		fmt.Fprintf(&sb, "%s\x01%s\x01%t\x02", lf.Kind, lf.Expr, lf.Enabled)
+	}
+	sb.WriteString(a.localSeverityClause())
+	return sb.String()
+}
+
+func matchesAll(filters []*query.LocalFilter, entry models.LogEntry) bool {
+	for _, f := range filters {
+		if !f.Matches(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *App) openFilterStack() {
+	a.activeModalName = "filterStack"
+	a.filterStackCursor = minInt(a.filterStackCursor, maxInt(0, len(a.state.FilterState.LocalFilters)-1))
+}
+
+// handleFilterStackInput handles keys in the local filter stack popup
+func (a *App) handleFilterStackInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	filters := a.state.FilterState.LocalFilters
+	cursor := a.filterStackCursor
+	switch msg.String() {
+	case "esc", "F":
+		a.activeModalName = "none"
+	case "j", "down":
+		a.filterStackCursor = minInt(cursor+1, maxInt(0, len(filters)-1))
+	case "k", "up":
+		a.filterStackCursor = maxInt(0, cursor-1)
+	case "a":
+		a.prompt.Open("localQuery", "ADD LOCAL FILTER (query syntax)", "")
+		a.prompt.SetHint(`e.g. NOT httpRequest.requestUrl:"/healthz" or severity>=WARNING`)
+		a.activeModalName = "prompt"
+	case "g":
+		a.prompt.Open("localGrep", "ADD LOCAL FILTER (grep)", "")
+		a.prompt.SetHint("Regular expression over the whole entry; -v inverts, -i ignores case")
+		a.activeModalName = "prompt"
+	case "enter", "e":
+		if cursor < len(filters) {
+			a.prompt.Open("localEdit", "EDIT LOCAL FILTER ("+filters[cursor].Kind+")", filters[cursor].Expr)
+			a.activeModalName = "prompt"
+		}
+	case " ":
+		if cursor < len(filters) {
+			a.updateLocalFilters(func(fs []models.LocalFilter) []models.LocalFilter {
+				fs[cursor].Enabled = !fs[cursor].Enabled
+				return fs
+			})
+		}
+	case "J":
+		if cursor+1 < len(filters) {
+			a.updateLocalFilters(func(fs []models.LocalFilter) []models.LocalFilter {
+				fs[cursor], fs[cursor+1] = fs[cursor+1], fs[cursor]
+				return fs
+			})
+			a.filterStackCursor++
+		}
+	case "K":
+		if cursor > 0 && cursor < len(filters) {
+			a.updateLocalFilters(func(fs []models.LocalFilter) []models.LocalFilter {
+				fs[cursor], fs[cursor-1] = fs[cursor-1], fs[cursor]
+				return fs
+			})
+			a.filterStackCursor--
+		}
+	case "d", "x":
+		if cursor < len(filters) {
+			a.updateLocalFilters(func(fs []models.LocalFilter) []models.LocalFilter {
+				return append(fs[:cursor], fs[cursor+1:]...)
+			})
+			a.filterStackCursor = minInt(cursor, maxInt(0, len(a.state.FilterState.LocalFilters)-1))
+		}
+	case "p":
+		if cursor < len(filters) {
+			return a, a.promoteLocalFilter(cursor)
+		}
+	}
+	return a, nil
+}
+
+// updateLocalFilters applies fn to a copy of the stack, so tabs cloned from
+// this one keep their own stack, and keeps the selected entry in view.
+func (a *App) updateLocalFilters(fn func([]models.LocalFilter) []models.LocalFilter) {
+	a.preserveSelection(func() {
+		filters := append([]models.LocalFilter{}, a.state.FilterState.LocalFilters...)
+		a.state.FilterState.LocalFilters = fn(filters)
+	})
+}
+
+// addLocalFilter validates and pushes a filter onto the stack
+func (a *App) addLocalFilter(kind, expr string) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		a.activeModalName = "filterStack"
+		return
+	}
+	if _, err := query.CompileLocalFilter(kind, expr); err != nil {
+		a.lastErr = fmt.Sprintf("Invalid local filter: %v", err)
+		a.activeModalName = "filterStack"
+		return
+	}
+	a.updateLocalFilters(func(fs []models.LocalFilter) []models.LocalFilter {
+		return append(fs, models.LocalFilter{Kind: kind, Expr: expr, Enabled: true})
+	})
+	a.filterStackCursor = len(a.state.FilterState.LocalFilters) - 1
+	a.activeModalName = "filterStack"
+	a.lastErr = fmt.Sprintf("Local filter added: %s shown", a.shownCountLabel())
+}
+
+func (a *App) editLocalFilter(expr string) {
+	cursor := a.filterStackCursor
+	a.activeModalName = "filterStack"
+	if cursor >= len(a.state.FilterState.LocalFilters) {
+		return
+	}
+	kind := a.state.FilterState.LocalFilters[cursor].Kind
+	if _, err := query.CompileLocalFilter(kind, expr); err != nil {
+		a.lastErr = fmt.Sprintf("Invalid local filter: %v", err)
+		return
+	}
+	a.updateLocalFilters(func(fs []models.LocalFilter) []models.LocalFilter {
+		fs[cursor].Expr = strings.TrimSpace(expr)
+		return fs
+	})
+}
+
+// promoteLocalFilter moves a stack item into the server-side query and
+// re-runs it
+func (a *App) promoteLocalFilter(idx int) tea.Cmd {
+	lf := a.state.FilterState.LocalFilters[idx]
+	clause := query.ToServerFilter(lf.Kind, lf.Expr)
+	a.updateLocalFilters(func(fs []models.LocalFilter) []models.LocalFilter {
+		return append(fs[:idx], fs[idx+1:]...)
+	})
+	a.filterStackCursor = minInt(idx, maxInt(0, len(a.state.FilterState.LocalFilters)-1))
+	a.activeModalName = "none"
+	a.lastErr = "Promoted to query: " + clause
+	return a.appendToQueryAndRun(clause)
+}
+
+// appendToQueryAndRun ANDs a clause onto the current query on its own line
+// and runs the query
+func (a *App) appendToQueryAndRun(clause string) tea.Cmd {
+	return a.setQueryAndRun(appendClause(a.state.CurrentQuery.Filter, clause))
+}
+
+// appendClause adds clause to filter on its own line
+func appendClause(filter, clause string) string {
+	filter = strings.TrimRight(filter, "\n ")
+	if strings.TrimSpace(filter) == "" {
+		return clause
+	}
+	return filter + "\n" + clause
+}
+
+// setQueryAndRun replaces the current query and runs it
+func (a *App) setQueryAndRun(filter string) tea.Cmd {
+	a.state.CurrentQuery.Filter = filter
+	a.queryModal.SetInput(filter)
+	a.addQueryHistory(filter)
+	if a.queryExec == nil {
+		return nil
+	}
+	return a.executePrimaryQueryCmd(a.buildEffectiveFilter(filter))
+}
+
+// shownCountLabel renders "1,204 loaded / 87 shown"
+func (a *App) shownCountLabel() string {
+	return fmt.Sprintf("%s loaded / %s shown", formatCount(len(a.state.LogListState.Logs)), formatCount(len(a.viewLogs())))
+}
+
+// formatCount formats n with thousands separators
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+func (a *App) renderFilterStackPopup() string {
+	var sb strings.Builder
+	popupWidth := minInt(maxInt(50, a.width-20), 110)
+	sb.WriteString(a.popupTop(popupWidth, "LOCAL FILTERS"))
+	sb.WriteString(a.popupLine(popupWidth, lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle)).Render(
+		"Applied in order to the loaded logs without querying GCP | "+a.shownCountLabel())))
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+	filters := a.state.FilterState.LocalFilters
+	if len(filters) == 0 {
+		sb.WriteString(a.popupLine(popupWidth, "No local filters yet (a: query syntax, g: grep)"))
+	}
+	for i, lf := range filters {
+		prefix := "  "
+		if i == a.filterStackCursor {
+			prefix = "▶ "
+		}
+		check := "[ ]"
+		if lf.Enabled {
+			check = "[x]"
+		}
+		line := fmt.Sprintf("%s%s %d. %-5s %s", prefix, check, i+1, lf.Kind, lf.Expr)
+		line = truncate(line, popupWidth-4)
+		if i == a.filterStackCursor {
+			line = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorSelectionFG)).Background(lipgloss.Color(colorSelectionBG)).Render(line)
+		} else if !lf.Enabled {
+			line = lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle)).Render(line)
+		}
+		sb.WriteString(a.popupLine(popupWidth, line))
+	}
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+	sb.WriteString(a.popupLine(popupWidth, "a add query | g add grep | Enter edit | Space toggle | J/K reorder | d delete"))
+	sb.WriteString(a.popupLine(popupWidth, "p promote to server query | Esc close"))
+	sb.WriteString(a.popupBottom(popupWidth, '━'))
+	return sb.String()
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+func pressKeys(app *App, keys ...tea.KeyMsg) *App {
+	for _, key := range keys {
+		newModel, _ := app.Update(key)
+		app = newModel.(*App)
+	}
+	return app
+}
+
+func runeKey(r rune) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+}
+
+func addLocalFilterByKeys(app *App, kindKey rune, expr string) *App {
+	app = pressKeys(app, runeKey(kindKey))
+	for _, r := range expr {
+		app = pressKeys(app, runeKey(r))
+	}
+	return pressKeys(app, tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestFilterStackNarrowsLoadedLogs(t *testing.T) {
+	app := pressKeys(newSearchTestApp(), runeKey('F'))
+	if app.activeModalName != "filterStack" {
+		t.Fatalf("Expected F to open the filter stack, got %q", app.activeModalName)
+	}
+	app = addLocalFilterByKeys(app, 'a', "-healthz")
+	app = addLocalFilterByKeys(app, 'g', "-i BILLING")
+	if app.activeModalName != "filterStack" || len(app.state.FilterState.LocalFilters) != 2 {
+		t.Fatalf("Expected two filters on the stack, modal=%q filters=%+v", app.activeModalName, app.state.FilterState.LocalFilters)
+	}
+	if got := len(app.viewLogs()); got != 3 {
+		t.Fatalf("Expected 3 entries shown, got %d", got)
+	}
+	if !strings.Contains(app.View(), "5 loaded / 3 shown") {
+		t.Error("Expected loaded and shown counts in the view")
+	}
+
+	// Space toggles the selected (last added) filter off
+	app = pressKeys(app, runeKey(' '))
+	if app.state.FilterState.LocalFilters[1].Enabled || len(app.viewLogs()) != 3 {
+		t.Errorf("Expected grep filter disabled with 3 entries shown, got %d", len(app.viewLogs()))
+	}
+	app = pressKeys(app, runeKey(' '), runeKey('K'))
+	if app.state.FilterState.LocalFilters[0].Kind != query.LocalKindGrep || app.filterStackCursor != 0 {
+		t.Errorf("Expected K to move the grep filter up, got %+v", app.state.FilterState.LocalFilters)
+	}
+	app = pressKeys(app, runeKey('d'))
+	if len(app.state.FilterState.LocalFilters) != 1 || len(app.viewLogs()) != 3 {
+		t.Errorf("Expected one filter left with 3 entries shown, got %d", len(app.viewLogs()))
+	}
+}
+
+func TestFilterStackRejectsInvalidFilter(t *testing.T) {
+	app := pressKeys(newSearchTestApp(), runeKey('F'))
+	app = addLocalFilterByKeys(app, 'a', "(severity=ERROR")
+	if len(app.state.FilterState.LocalFilters) != 0 || !strings.Contains(app.lastErr, "Invalid local filter") {
+		t.Errorf("Expected invalid filter to be rejected, got %q", app.lastErr)
+	}
+	if app.activeModalName != "filterStack" {
+		t.Errorf("Expected to return to the filter stack, got %q", app.activeModalName)
+	}
+	app = pressKeys(app, runeKey('g'), tea.KeyMsg{Type: tea.KeyEsc})
+	if app.activeModalName != "filterStack" {
+		t.Errorf("Esc in the prompt should return to the filter stack, got %q", app.activeModalName)
+	}
+}
+
+func TestFilterStackPromoteToServerQuery(t *testing.T) {
+	app := newTabsTestApp(t)
+	var filters []string
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		filters = append(filters, filter)
+		return nil, nil
+	})
+	app.state.FilterState.LocalFilters = []models.LocalFilter{{Kind: query.LocalKindGrep, Expr: "-v healthz", Enabled: true}}
+
+	app = pressKeys(app, runeKey('F'))
+	newModel, cmd := app.Update(runeKey('p'))
+	app = newModel.(*App)
+	if len(app.state.FilterState.LocalFilters) != 0 || app.activeModalName != "none" {
+		t.Errorf("Expected the filter to leave the stack, got %+v", app.state.FilterState.LocalFilters)
+	}
+	if want := "resource.type=\"api\"\nNOT \"healthz\""; app.state.CurrentQuery.Filter != want {
+		t.Errorf("Expected promoted clause in the query, got %q", app.state.CurrentQuery.Filter)
+	}
+	if cmd == nil {
+		t.Fatal("Expected promote to run the query")
+	}
+	cmd()
+	if len(filters) != 1 || !strings.Contains(filters[0], `NOT "healthz"`) {
+		t.Errorf("Expected the promoted clause in the executed filter, got %v", filters)
+	}
+}
+
+func TestFilterStackIsPerTab(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.state.FilterState.LocalFilters = []models.LocalFilter{{Kind: query.LocalKindQuery, Expr: "severity>=ERROR", Enabled: true}}
+	app = pressKeys(app, tea.KeyMsg{Type: tea.KeyCtrlT})
+	app.state.FilterState.LocalFilters[0].Enabled = false
+	app = pressKeys(app, tea.KeyMsg{Type: tea.KeyCtrlW})
+	if !app.state.FilterState.LocalFilters[0].Enabled {
+		t.Error("Changing the stack in a new tab should not affect the original tab")
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	for n, want := range map[int]string{0: "0", 87: "87", 1204: "1,204", 1234567: "1,234,567", -1500: "-1,500"} {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
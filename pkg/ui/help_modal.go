@@ -105,6 +105,8 @@ func (hm *HelpModal) getSections() []helpSection {
 				{"g / G", "Jump to first / last visible log"},
 				{"Enter / Ctrl+D", "Toggle details panel"},
 				{"Ctrl+P", "Open full log popup"},
+				{"/", "Search loaded logs (Tab mode, Ctrl+F filter)"},
+				{"n / N", "Next / previous search match"},
 				{"Esc / ?", "Close modal / help"},
 			},
 		},
@@ -129,6 +131,12 @@ func (hm *HelpModal) getSections() []helpSection {
 				{"h/l", "Collapse / expand JSON node"},
 				{"z / Z", "Collapse all / expand all nodes"},
 				{"y / Y", "Copy selected node / full payload"},
+				{"= / ! / *", "Add node to query: equals / NOT equals / exists"},
+				{"F", "Follow the node value across services"},
+				{"#", "Plot the numeric node on the timeline"},
+				{"p / r", "Audit log: query by principal / resource"},
+				{"T", "Trace view of the selected entry (Tab waterfall)"},
+				{"x", "Context: what the entry's resource logged around it"},
 				{"Ctrl+E", "Open payload in $EDITOR"},
 				{"Ctrl+O", "Open selected log in $EDITOR"},
 				{"Ctrl+L / Alt+L", "Open loaded logs as JSON / CSV"},
@@ -140,12 +148,37 @@ func (hm *HelpModal) getSections() []helpSection {
 			rows: [][2]string{
 				{"t", "Time range filter"},
 				{"f", "Severity filter"},
+				{"F", "Local filter stack (a/g add, Space, J/K, p promote)"},
+				{"b", "Facet sidebar (i/x local, I/X query, p pin)"},
+				{"C", "Columns from field paths (a add, s sort, S/Q save)"},
+				{"v", "Cycle row templates from config"},
+				{"H", "Toggle the HTTP request view"},
+				{"O", "Sort by request latency, slowest first"},
+				{"K", "Kubernetes navigator (s scope, c compare pods)"},
+				{"M", "Message patterns (x hide, X exclude from query)"},
+				{"D", "Compare two windows: before/after an entry or a day earlier"},
+				{"a / A", "Next timeline anomaly / zoom the time range to it"},
+				{"Tab", "Focus the timeline: h/l bucket, Space brush, Enter zoom, u zoom out"},
+				{"V", "Cycle the timeline: sparkline, stacked severity chart, plotted fields"},
+				{"#", "Plot a numeric field on the timeline (again to remove it)"},
 				{"m", "Toggle stream mode"},
 				{"Ctrl+A", "Toggle auto-load all pages"},
 				{"r", "Rerun query (bypass cache)"},
 				{"F8", "Toggle log order bottom/top"},
 			},
 		},
+		{
+			title:   "Tabs",
+			summary: "Parallel workspaces and the time-synced split view",
+			rows: [][2]string{
+				{"Ctrl+T", "Open tab with current query"},
+				{"Ctrl+W", "Close tab"},
+				{"] / [", "Next / previous tab"},
+				{"F2", "Rename tab"},
+				{"\\", "Split view: stacked / side by side / off"},
+				{"w", "Switch split pane (partner follows by time)"},
+			},
+		},
 		{
 			title:   "System",
 			summary: "Session and environment controls",
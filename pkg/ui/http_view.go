@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// httpLatencyField is the sort field of the latency toggle
+const httpLatencyField = "httpRequest.latency"
+
+// httpMinURLWidth is the room the request URL keeps before the client
+// columns are left out
+const httpMinURLWidth = 40
+
+// httpColumns replace the list columns in the HTTP request view. The request
+// URL takes the message column.
+var httpColumns = []models.Column{
+	{Field: "httpRequest.status", Width: 6, Align: columnAlignRight},
+	{Field: "httpRequest.requestMethod", Width: 7},
+	{Field: httpLatencyField, Width: 8, Align: columnAlignRight},
+	{Field: "httpRequest.responseSize", Width: 7, Align: columnAlignRight},
+}
+
+// httpClientColumns follow httpColumns on terminals wide enough to keep
+// httpMinURLWidth for the URL. Long user agents and IPv6 addresses are cut
+// to the column width.
+var httpClientColumns = []models.Column{
+	{Field: "httpRequest.remoteIp", Width: 15},
+	{Field: "httpRequest.userAgent", Width: 18},
+}
+
+// listColumns returns the columns shown in the log list
+func (a *App) listColumns() []models.Column {
+	if a.httpMode {
+		columns := append(slices.Clone(httpColumns), httpClientColumns...)
+		width := 0
+		for _, col := range columns {
+			width += columnWidth(col) + 2
+		}
+		if a.width-logRowPrefixWidth-width < httpMinURLWidth {
+			return httpColumns
+		}
+		return columns
+	}
+	return a.state.LogListState.Columns
+}
+
+// cellValue renders latencies and sizes of HTTP request fields compactly
+func cellValue(field, value string) string {
+	if value == "" {
+		return value
+	}
+	switch field {
+	case "httpRequest.latency":
+		if d, err := time.ParseDuration(value); err == nil {
+			return formatLatency(d)
+		}
+	case "httpRequest.requestSize", "httpRequest.responseSize":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return formatBytes(n)
+		}
+	}
+	return value
+}
+
+// formatLatency renders a duration as "850µs", "120ms", "2.1s" or "1m5s"
+func formatLatency(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return fmt.Sprintf("%dµs", d.Microseconds())
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return d.Round(time.Second).String()
+}
+
+// formatBytes renders a size as "512B", "1.5K", "3.2M" or "1.1G"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	value := float64(n) / unit
+	for _, suffix := range []string{"K", "M", "G"} {
+		if value < unit || suffix == "G" {
+			return fmt.Sprintf("%.1f%s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
+// httpMessage is the message shown for entry in the HTTP request view
+func httpMessage(entry models.LogEntry) string {
+	if entry.HTTPRequest == nil || entry.HTTPRequest.RequestURL == "" {
+		return listMessage(entry)
+	}
+	return entry.HTTPRequest.RequestURL
+}
+
+// httpStatusStyle colors a row by the status class of its request. Entries
+// without a request are dimmed.
+func httpStatusStyle(entry models.LogEntry) lipgloss.Style {
+	status := 0
+	if entry.HTTPRequest != nil {
+		status = entry.HTTPRequest.Status
+	}
+	switch {
+	case status >= 500:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(colorGCPError))
+	case status >= 400:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(colorGCPWarn))
+	case status >= 300:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(colorGCPBlueLight))
+	case status >= 100:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralText))
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle))
+}
+
+// toggleHTTPMode switches the log list between the default layout and the
+// HTTP request view
+func (a *App) toggleHTTPMode() {
+	a.httpMode = !a.httpMode
+	if !a.httpMode {
+		if a.state.LogListState.SortField == httpLatencyField {
+			a.setLogSort("", false)
+		}
+		a.lastErr = "HTTP request view off"
+		return
+	}
+	a.lastErr = "HTTP request view (O: sort by latency)"
+	for _, entry := range a.state.LogListState.Logs {
+		if entry.HTTPRequest != nil {
+			return
+		}
+	}
+	a.lastErr = "HTTP request view: no loaded entries have an httpRequest"
+}
+
+// toggleLatencySort sorts the log list by latency, slowest first, or turns
+// the sort off again
+func (a *App) toggleLatencySort() {
+	if a.state.LogListState.SortField == httpLatencyField {
+		a.setLogSort("", false)
+		a.lastErr = "Sort: off"
+		return
+	}
+	a.setLogSort(httpLatencyField, true)
+	a.lastErr = "Sort: latency, slowest first"
+}
+
+// latencyStats summarizes the requests among a set of entries
+type latencyStats struct {
+	requests      int
+	p50, p95, p99 time.Duration
+	serverErrors  int
+}
+
+// buildLatencyStats computes nearest-rank latency percentiles and the number
+// of 5xx responses over the entries that carry an httpRequest
+func buildLatencyStats(logs []models.LogEntry) latencyStats {
+	var stats latencyStats
+	var latencies []time.Duration
+	for _, log := range logs {
+		if log.HTTPRequest == nil {
+			continue
+		}
+		stats.requests++
+		if log.HTTPRequest.Status >= 500 {
+			stats.serverErrors++
+		}
+		if d, err := time.ParseDuration(log.HTTPRequest.Latency); err == nil {
+			latencies = append(latencies, d)
+		}
+	}
+	if len(latencies) == 0 {
+		return stats
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	percentile := func(p int) time.Duration {
+		rank := (p*len(latencies) + 99) / 100
+		return latencies[maxInt(0, rank-1)]
+	}
+	stats.p50, stats.p95, stats.p99 = percentile(50), percentile(95), percentile(99)
+	return stats
+}
+
+// renderHTTPStats is the graph panel line of the HTTP request view
+func (a *App) renderHTTPStats() string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorGCPBlue)).Render("Requests")
+	stats := buildLatencyStats(a.state.LogListState.Logs)
+	if stats.requests == 0 {
+		return title + " none loaded"
+	}
+	parts := []string{title}
+	if stats.p50 > 0 || stats.p99 > 0 {
+		parts = append(parts,
+			"p50 "+formatLatency(stats.p50),
+			"p95 "+formatLatency(stats.p95),
+			"p99 "+formatLatency(stats.p99))
+	}
+	rate := fmt.Sprintf("5xx %.1f%% (%d/%d)", 100*float64(stats.serverErrors)/float64(stats.requests), stats.serverErrors, stats.requests)
+	if stats.serverErrors > 0 {
+		rate = lipgloss.NewStyle().Foreground(lipgloss.Color(colorGCPError)).Render(rate)
+	}
+	parts = append(parts, rate)
+	return strings.Join(parts, "  ")
+}
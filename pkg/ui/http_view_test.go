@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func TestBuildLatencyStats(t *testing.T) {
+	var logs []models.LogEntry
+	for i := 1; i <= 100; i++ {
+		status := 200
+		if i%25 == 0 {
+			status = 503
+		}
+		logs = append(logs, models.LogEntry{HTTPRequest: &models.HTTPRequest{
+			Status:  status,
+			Latency: (time.Duration(i) * time.Millisecond).String(),
+		}})
+	}
+	logs = append(logs, models.LogEntry{Message: "no request"})
+
+	stats := buildLatencyStats(logs)
+	if stats.requests != 100 || stats.serverErrors != 4 {
+		t.Errorf("Expected 100 requests with 4 5xx, got %+v", stats)
+	}
+	if stats.p50 != 50*time.Millisecond || stats.p95 != 95*time.Millisecond || stats.p99 != 99*time.Millisecond {
+		t.Errorf("Unexpected percentiles p50=%v p95=%v p99=%v", stats.p50, stats.p95, stats.p99)
+	}
+}
+
+func TestCellValue(t *testing.T) {
+	cases := map[[2]string]string{
+		{"httpRequest.latency", "0.120s"}:       "120ms",
+		{"httpRequest.latency", "2.54s"}:        "2.5s",
+		{"httpRequest.latency", "0.0004s"}:      "400µs",
+		{"httpRequest.responseSize", "512"}:     "512B",
+		{"httpRequest.responseSize", "1572864"}: "1.5M",
+		{"jsonPayload.latency", "0.120s"}:       "0.120s",
+	}
+	for in, want := range cases {
+		if got := cellValue(in[0], in[1]); got != want {
+			t.Errorf("cellValue(%q, %q) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
+
+func TestHTTPModeCutsURLsByRune(t *testing.T) {
+	app := newTabsTestApp(t)
+	logs := columnTestLogs()
+	logs[0].HTTPRequest.RequestURL = "https://example.com/" + strings.Repeat("ü", 200)
+	app.state.LogListState.Logs = logs
+	app = pressKeys(app, runeKey('H'))
+	view := app.View()
+	if !utf8.ValidString(view) || !strings.Contains(view, "üü...") {
+		t.Error("Expected the long URL cut between runes")
+	}
+}
+
+func TestHTTPModeColumnsAndLatencySort(t *testing.T) {
+	app := newTabsTestApp(t)
+	logs := columnTestLogs()
+	logs[0].HTTPRequest.RequestMethod = "GET"
+	logs[0].HTTPRequest.RequestURL = "https://api.example.com/v1/users"
+	logs[0].HTTPRequest.RemoteIP = "203.0.113.7"
+	logs[0].HTTPRequest.UserAgent = "Mozilla/5.0 (X11; Linux x86_64) Chrome/124.0"
+	app.state.LogListState.Logs = logs
+
+	app = pressKeys(app, runeKey('H'))
+	if !app.httpMode {
+		t.Fatal("Expected H to turn on the HTTP request view")
+	}
+	view := ansiEscapeRegex.ReplaceAllString(app.View(), "")
+	for _, want := range []string{"STATUS  METHOD", "URL", "https://api.example.com/v1/users", "120ms", "p50 ", "5xx 33.3% (1/3)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the HTTP request view", want)
+		}
+	}
+	if strings.Contains(view, "REMOTE IP") {
+		t.Error("Expected the client columns left out when the URL would not fit")
+	}
+	newModel, _ := app.Update(tea.WindowSizeMsg{Width: 180, Height: 40})
+	app = newModel.(*App)
+	view = ansiEscapeRegex.ReplaceAllString(app.View(), "")
+	for _, want := range []string{"SIZE  REMOTE IP", "USER AGENT", "203.0.113.7", "Mozilla/5.0 (X11;…", "https://api.example.com/v1/users"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the wide HTTP request view", want)
+		}
+	}
+
+	app = pressKeys(app, runeKey('O'))
+	var ids []string
+	for _, entry := range app.viewLogs() {
+		ids = append(ids, entry.ID)
+	}
+	if got := strings.Join(ids, ","); got != "3,1,4,2" {
+		t.Errorf("Expected slowest requests first, got %s", got)
+	}
+
+	app = pressKeys(app, runeKey('H'))
+	if app.httpMode || app.state.LogListState.SortField != "" {
+		t.Error("Expected H to leave the view and drop the latency sort")
+	}
+	if view := ansiEscapeRegex.ReplaceAllString(app.View(), ""); strings.Contains(view, "Requests") {
+		t.Error("Expected the request stats to leave the graph panel")
+	}
+}
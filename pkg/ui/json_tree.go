@@ -11,6 +11,7 @@ import (
 
 type jsonTreeLine struct {
 	path      string
+	segments  []string // path segments below the root; array items as "[i]"
 	text      string
 	value     interface{}
 	canExpand bool
@@ -25,11 +26,11 @@ func buildJSONTreeLines(root interface{}, expanded map[string]bool) []jsonTreeLi
 		expanded["$"] = true
 	}
 	lines := make([]jsonTreeLine, 0, 64)
-	appendJSONTreeNode(&lines, root, "$", "$", nil, true, expanded)
+	appendJSONTreeNode(&lines, root, "$", nil, "$", nil, true, expanded)
 	return lines
 }
 
-func appendJSONTreeNode(lines *[]jsonTreeLine, value interface{}, path, label string, ancestorsHasNext []bool, isLast bool, expanded map[string]bool) {
+func appendJSONTreeNode(lines *[]jsonTreeLine, value interface{}, path string, segments []string, label string, ancestorsHasNext []bool, isLast bool, expanded map[string]bool) {
 	canExpand := isExpandableJSONValue(value)
 	isExpanded := canExpand && expanded[path]
 	marker := "•"
@@ -49,6 +50,7 @@ func appendJSONTreeNode(lines *[]jsonTreeLine, value interface{}, path, label st
 
 	*lines = append(*lines, jsonTreeLine{
 		path:      path,
+		segments:  segments,
 		value:     value,
 		canExpand: canExpand,
 		expanded:  isExpanded,
@@ -70,18 +72,24 @@ func appendJSONTreeNode(lines *[]jsonTreeLine, value interface{}, path, label st
 		sort.Strings(keys)
 		for i, key := range keys {
 			childPath := path + "." + key
-			appendJSONTreeNode(lines, typed[key], childPath, key+":", nextAncestors, i == len(keys)-1, expanded)
+			appendJSONTreeNode(lines, typed[key], childPath, childKeys(segments, key), key+":", nextAncestors, i == len(keys)-1, expanded)
 		}
 		return
 	}
 	if typed, ok := toSlice(value); ok {
 		for i, child := range typed {
 			childPath := fmt.Sprintf("%s[%d]", path, i)
-			appendJSONTreeNode(lines, child, childPath, "["+strconv.Itoa(i)+"]:", nextAncestors, i == len(typed)-1, expanded)
+			appendJSONTreeNode(lines, child, childPath, childKeys(segments, "["+strconv.Itoa(i)+"]"), "["+strconv.Itoa(i)+"]:", nextAncestors, i == len(typed)-1, expanded)
 		}
 	}
 }
 
+func childKeys(segments []string, key string) []string {
+	out := make([]string, len(segments), len(segments)+1)
+	copy(out, segments)
+	return append(out, key)
+}
+
 func buildTreePrefix(ancestorsHasNext []bool, isLast bool) string {
 	if len(ancestorsHasNext) == 0 {
 		return ""
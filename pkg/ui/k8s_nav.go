@@ -0,0 +1,472 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+// k8sResourceType is the monitored resource of GKE container logs
+const k8sResourceType = "k8s_container"
+
+// k8sScopePrefix starts the query line written by the navigator
+var k8sScopePrefix = query.NewBuilder("").AddResourceFilter(k8sResourceType).Build()
+
+// k8sScopeComment marks the line written by the navigator, so scoping again
+// replaces it and leaves k8s_container lines the user wrote alone. Comment
+// lines are dropped before the query runs.
+const k8sScopeComment = "-- k8s scope"
+
+// Navigator levels, outermost first
+const (
+	k8sLevelCluster = iota
+	k8sLevelNamespace
+	k8sLevelWorkload
+	k8sLevelPod
+	k8sLevelContainer
+)
+
+var k8sLevelNames = []string{"clusters", "namespaces", "workloads", "pods", "containers"}
+
+// k8sResourceLabels are the resource labels of each level; workloads have none
+var k8sResourceLabels = []string{"cluster_name", "namespace_name", "", "pod_name", "container_name"}
+
+// k8sWorkloadLabel is the pod label GKE copies into the entry labels
+const k8sWorkloadLabel = "k8s-pod/app"
+
+// podShape is the suffix a controller adds to the pods it creates
+type podShape struct {
+	suffix string
+	re     *regexp.Regexp
+}
+
+func newPodShape(suffix string) podShape {
+	return podShape{suffix: suffix, re: regexp.MustCompile(`^(.+)` + suffix)}
+}
+
+// podShapes match Deployment pods (name-<replicaset hash>-<id>), StatefulSet
+// pods (name-<ordinal>), then DaemonSet and Job pods (name-<id>)
+var podShapes = []podShape{
+	newPodShape(`-[a-z0-9]{6,10}-[a-z0-9]{5}$`),
+	newPodShape(`-[0-9]+$`),
+	newPodShape(`-[a-z0-9]{5}$`),
+}
+
+// k8sStep is the name of one level of an entry's place in the cluster
+type k8sStep struct {
+	name    string
+	pattern string // pod_name regexp of a workload derived from pod names
+}
+
+// k8sNode is a navigator row and the entries below it
+type k8sNode struct {
+	step   k8sStep
+	count  int
+	errors int
+	pods   int
+	first  time.Time
+	last   time.Time
+}
+
+// k8sNavigator is the state of the Kubernetes navigator
+type k8sNavigator struct {
+	path          []k8sStep // levels chosen above the listed one
+	cursor        int
+	marked        string // pod marked for comparison
+	sample        []models.LogEntry
+	sampleProject string
+	loading       bool
+}
+
+type k8sDiscoveryMsg struct {
+	project string
+	logs    []models.LogEntry
+	err     error
+}
+
+// k8sPath returns the cluster, namespace, workload, pod and container of a
+// k8s_container entry
+func k8sPath(entry models.LogEntry) ([]k8sStep, bool) {
+	if entry.Resource.Type != k8sResourceType {
+		return nil, false
+	}
+	labels := entry.Resource.Labels
+	pod := labels["pod_name"]
+	return []k8sStep{
+		{name: labels["cluster_name"]},
+		{name: labels["namespace_name"]},
+		k8sWorkload(pod, entry.Labels[k8sWorkloadLabel]),
+		{name: pod},
+		{name: labels["container_name"]},
+	}, true
+}
+
+// k8sWorkload names the workload of a pod: its app label, or the pod name
+// without the suffix added by its controller
+func k8sWorkload(pod, app string) k8sStep {
+	if app != "" {
+		return k8sStep{name: app}
+	}
+	for _, shape := range podShapes {
+		if m := shape.re.FindStringSubmatch(pod); m != nil {
+			return k8sStep{name: m[1], pattern: "^" + regexp.QuoteMeta(m[1]) + shape.suffix}
+		}
+	}
+	return k8sStep{name: pod, pattern: "^" + regexp.QuoteMeta(pod) + "$"}
+}
+
+func k8sUnder(steps, path []k8sStep) bool {
+	for i, step := range path {
+		if steps[i].name != step.name {
+			return false
+		}
+	}
+	return true
+}
+
+// k8sChildren counts the entries under path by the next level. Pods are
+// listed in the order they first logged, so replaced instances read in
+// sequence; other levels by count.
+func k8sChildren(logs []models.LogEntry, path []k8sStep) []k8sNode {
+	level := len(path)
+	if level > k8sLevelContainer {
+		return nil
+	}
+	index := map[string]int{}
+	pods := map[string]map[string]bool{}
+	var nodes []k8sNode
+	for _, entry := range logs {
+		steps, ok := k8sPath(entry)
+		if !ok || !k8sUnder(steps, path) {
+			continue
+		}
+		step := steps[level]
+		i, seen := index[step.name]
+		if !seen {
+			i = len(nodes)
+			index[step.name] = i
+			nodes = append(nodes, k8sNode{step: step, first: entry.Timestamp, last: entry.Timestamp})
+			pods[step.name] = map[string]bool{}
+		}
+		n := &nodes[i]
+		n.count++
+		if models.SeverityRank(entry.Severity) >= models.SeverityRank(models.SeverityError) {
+			n.errors++
+		}
+		if entry.Timestamp.Before(n.first) {
+			n.first = entry.Timestamp
+		}
+		if entry.Timestamp.After(n.last) {
+			n.last = entry.Timestamp
+		}
+		pods[step.name][steps[k8sLevelPod].name] = true
+	}
+	for i := range nodes {
+		nodes[i].pods = len(pods[nodes[i].step.name])
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if level == k8sLevelPod && !nodes[i].first.Equal(nodes[j].first) {
+			return nodes[i].first.Before(nodes[j].first)
+		}
+		if nodes[i].count != nodes[j].count {
+			return nodes[i].count > nodes[j].count
+		}
+		return nodes[i].step.name < nodes[j].step.name
+	})
+	return nodes
+}
+
+// k8sReplaced reports whether another pod of the list started logging after
+// pod i stopped, as after a restart or a rollout
+func k8sReplaced(nodes []k8sNode, i int) bool {
+	for j, other := range nodes {
+		if j != i && other.first.After(nodes[i].last) {
+			return true
+		}
+	}
+	return false
+}
+
+// k8sScopeClause builds the query line selecting the entries under path
+func k8sScopeClause(path []k8sStep) string {
+	builder := query.NewBuilder("").AddResourceFilter(k8sResourceType)
+	for level, step := range path {
+		if level != k8sLevelWorkload {
+			builder.AddResourceLabelFilter(k8sResourceLabels[level], step.name)
+			continue
+		}
+		switch {
+		case len(path) > k8sLevelPod:
+			// The pod name is narrower than its workload
+		case step.pattern != "":
+			builder.AddResourceLabelMatch("pod_name", step.pattern)
+		default:
+			builder.AddLabelFilter(k8sWorkloadLabel, step.name)
+		}
+	}
+	return builder.Build()
+}
+
+// k8sScopeLines is the marked scope line appended to the query
+func k8sScopeLines(path []k8sStep) string {
+	return k8sScopeComment + "\n" + k8sScopeClause(path)
+}
+
+// withoutK8sScope drops the line written by the navigator, and its marker,
+// from a query
+func withoutK8sScope(filter string) string {
+	var kept []string
+	lines := strings.Split(filter, "\n")
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != k8sScopeComment {
+			kept = append(kept, lines[i])
+			continue
+		}
+		if i+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i+1]), k8sScopePrefix) {
+			i++
+		}
+	}
+	return strings.TrimRight(strings.Join(kept, "\n"), "\n ")
+}
+
+func k8sPathLabel(path []k8sStep) string {
+	names := make([]string, len(path))
+	for i, step := range path {
+		names[i] = k8sName(step.name)
+	}
+	return strings.Join(names, " › ")
+}
+
+func k8sName(name string) string {
+	if name == "" {
+		return "(none)"
+	}
+	return name
+}
+
+// k8sEntries are the entries the navigator counts: the discovery sample of
+// the current project, or the loaded entries
+func (a *App) k8sEntries() []models.LogEntry {
+	if a.k8s.sample != nil && a.k8s.sampleProject == a.state.CurrentProject {
+		return a.k8s.sample
+	}
+	return a.state.LogListState.Logs
+}
+
+func (a *App) openK8sNavigator() {
+	a.activeModalName = "k8s"
+	nodes := k8sChildren(a.k8sEntries(), a.k8s.path)
+	a.k8s.cursor = clampInt(a.k8s.cursor, 0, maxInt(0, len(nodes)-1))
+}
+
+// handleK8sInput handles keys in the Kubernetes navigator
+func (a *App) handleK8sInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	nodes := k8sChildren(a.k8sEntries(), a.k8s.path)
+	level := len(a.k8s.path)
+	switch msg.String() {
+	case "esc", "K":
+		a.activeModalName = "none"
+	case "j", "down":
+		a.k8s.cursor = minInt(a.k8s.cursor+1, maxInt(0, len(nodes)-1))
+	case "k", "up":
+		a.k8s.cursor = maxInt(0, a.k8s.cursor-1)
+	case "l", "right", "enter":
+		if a.k8s.cursor >= len(nodes) {
+			return a, nil
+		}
+		path := append(append([]k8sStep(nil), a.k8s.path...), nodes[a.k8s.cursor].step)
+		if level == k8sLevelContainer {
+			return a, a.scopeK8s(path)
+		}
+		a.k8s.path = path
+		a.k8s.cursor = 0
+		a.k8s.marked = ""
+	case "h", "left", "backspace":
+		if level > 0 {
+			a.k8s.path = a.k8s.path[:level-1]
+			a.k8s.cursor = 0
+			a.k8s.marked = ""
+		}
+	case "s":
+		if a.k8s.cursor < len(nodes) {
+			return a, a.scopeK8s(append(append([]k8sStep(nil), a.k8s.path...), nodes[a.k8s.cursor].step))
+		}
+	case "S":
+		return a, a.scopeK8s(a.k8s.path)
+	case "c":
+		return a, a.compareK8sPods(nodes)
+	case "d":
+		return a, a.runK8sDiscovery()
+	case "D":
+		a.k8s.sample = nil
+		a.lastErr = "Counting loaded entries"
+	}
+	return a, nil
+}
+
+// scopeK8s replaces the navigator line of the query with one selecting path
+// and runs it. An empty path removes the line.
+func (a *App) scopeK8s(path []k8sStep) tea.Cmd {
+	a.activeModalName = "none"
+	filter := withoutK8sScope(a.state.CurrentQuery.Filter)
+	if len(path) == 0 {
+		a.lastErr = "Cleared the Kubernetes scope"
+		return a.setQueryAndRun(filter)
+	}
+	a.lastErr = "Scoped to " + k8sPathLabel(path)
+	return a.setQueryAndRun(appendClause(filter, k8sScopeLines(path)))
+}
+
+// compareK8sPods marks the selected pod, or opens the marked pod in this tab
+// and the selected one in a new tab side by side
+func (a *App) compareK8sPods(nodes []k8sNode) tea.Cmd {
+	if len(a.k8s.path) != k8sLevelPod || a.k8s.cursor >= len(nodes) {
+		a.lastErr = "Open a workload to compare its pods"
+		return nil
+	}
+	pod := nodes[a.k8s.cursor].step.name
+	if a.k8s.marked == "" || a.k8s.marked == pod {
+		if a.k8s.marked == pod {
+			a.k8s.marked = ""
+			a.lastErr = "Unmarked " + pod
+			return nil
+		}
+		a.k8s.marked = pod
+		a.lastErr = "Marked " + pod + " (c on another pod compares)"
+		return nil
+	}
+	marked := a.k8s.marked
+	a.k8s.marked = ""
+	a.activeModalName = "none"
+	scoped := func(name string) string {
+		path := append(append([]k8sStep(nil), a.k8s.path...), k8sStep{name: name})
+		return appendClause(withoutK8sScope(a.state.CurrentQuery.Filter), k8sScopeLines(path))
+	}
+	first, second := scoped(marked), scoped(pod)
+	firstCmd := a.setQueryAndRun(first)
+	partnerID := a.activeTabID()
+	secondCmd := a.openTabWithFilter(second)
+	a.tabs[a.activeTab].name = pod
+	a.splitMode = splitSide
+	a.splitPartnerID = partnerID
+	a.lastErr = fmt.Sprintf("Comparing %s with %s (w switches pane)", marked, pod)
+	return tea.Batch(firstCmd, secondCmd)
+}
+
+// runK8sDiscovery queries the k8s_container entries of the time range, so
+// the navigator also lists workloads the current query does not load
+func (a *App) runK8sDiscovery() tea.Cmd {
+	if a.queryExec == nil {
+		a.lastErr = "No query executor configured"
+		return nil
+	}
+	builder := query.NewBuilder("").AddResourceFilter(k8sResourceType)
+	if tr := a.state.FilterState.TimeRange; !tr.Start.IsZero() && !tr.End.IsZero() {
+		builder.AddTimeRange(tr)
+	}
+	filter := builder.Build()
+	project := a.state.CurrentProject
+	a.k8s.loading = true
+	a.lastErr = "Running discovery query..."
+	return func() tea.Msg {
+		logs, err := a.execQuery(project, filter)
+		return k8sDiscoveryMsg{project: project, logs: logs, err: err}
+	}
+}
+
+func (a *App) applyK8sDiscovery(msg k8sDiscoveryMsg) {
+	a.k8s.loading = false
+	if msg.err != nil {
+		a.lastErr = fmt.Sprintf("Discovery query failed: %v", msg.err)
+		return
+	}
+	if msg.logs == nil {
+		msg.logs = []models.LogEntry{}
+	}
+	a.k8s.sample = msg.logs
+	a.k8s.sampleProject = msg.project
+	a.k8s.cursor = 0
+	a.lastErr = fmt.Sprintf("Discovery query: %s entries", formatCount(len(msg.logs)))
+}
+
+func (a *App) renderK8sPopup() string {
+	var sb strings.Builder
+	popupWidth := minInt(maxInt(60, a.width-20), 110)
+	subtle := lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle))
+	entries := a.k8sEntries()
+	level := len(a.k8s.path)
+	nodes := k8sChildren(entries, a.k8s.path)
+
+	sb.WriteString(a.popupTop(popupWidth, "KUBERNETES"))
+	crumb := k8sLevelNames[level]
+	if level > 0 {
+		crumb = k8sPathLabel(a.k8s.path) + " › " + crumb
+	}
+	sb.WriteString(a.popupLine(popupWidth, crumb))
+	source := fmt.Sprintf("Counts from %s loaded entries", formatCount(len(entries)))
+	if a.k8s.loading {
+		source = "Running discovery query..."
+	} else if a.k8s.sample != nil && a.k8s.sampleProject == a.state.CurrentProject {
+		source = fmt.Sprintf("Counts from a discovery query of %s entries", formatCount(len(entries)))
+	}
+	sb.WriteString(a.popupLine(popupWidth, subtle.Render(source)))
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+
+	if len(nodes) == 0 {
+		sb.WriteString(a.popupLine(popupWidth, "No k8s_container entries (d runs a discovery query)"))
+	}
+	visible := maxInt(3, a.height-16)
+	start := clampInt(a.k8s.cursor-visible/2, 0, maxInt(0, len(nodes)-visible))
+	end := minInt(len(nodes), start+visible)
+	nameWidth := clampInt(a.popupInnerWidth(popupWidth)-60, 12, 40)
+	for i := start; i < end; i++ {
+		node := nodes[i]
+		prefix := "  "
+		if i == a.k8s.cursor {
+			prefix = "▶ "
+		}
+		errText := ""
+		if node.errors > 0 {
+			errText = fmt.Sprintf("%d err", node.errors)
+		}
+		detail := ""
+		switch {
+		case level == k8sLevelPod:
+			detail = a.displayTime(node.first).Format("15:04:05") + "–" + a.displayTime(node.last).Format("15:04:05")
+			if k8sReplaced(nodes, i) {
+				detail += " replaced"
+			}
+			if node.step.name == a.k8s.marked {
+				detail += " ◆ marked"
+			}
+		case level < k8sLevelPod:
+			detail = fmt.Sprintf("%d pods", node.pods)
+			if node.pods == 1 {
+				detail = "1 pod"
+			}
+		}
+		line := fmt.Sprintf("%s%-*s %8s %8s  %s", prefix, nameWidth, truncate(k8sName(node.step.name), nameWidth), formatCount(node.count), errText, detail)
+		switch {
+		case i == a.k8s.cursor:
+			line = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorSelectionFG)).Background(lipgloss.Color(colorSelectionBG)).Render(line)
+		case node.errors > 0:
+			line = severityStyle(models.SeverityError).Render(line)
+		}
+		sb.WriteString(a.popupLine(popupWidth, line))
+	}
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+	sb.WriteString(a.popupLine(popupWidth, "Enter/l open | h back | s scope query to row | S scope to this level"))
+	if level == k8sLevelPod {
+		sb.WriteString(a.popupLine(popupWidth, "c mark a pod, then c on another to compare them side by side"))
+	}
+	sb.WriteString(a.popupLine(popupWidth, "d discovery query | D count loaded entries | Esc close"))
+	sb.WriteString(a.popupBottom(popupWidth, '━'))
+	return sb.String()
+}
@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func k8sTestEntry(id, namespace, pod, container, severity string, at time.Time) models.LogEntry {
+	return models.LogEntry{
+		ID:        id,
+		Timestamp: at,
+		Severity:  severity,
+		Message:   "msg " + id,
+		Resource: models.Resource{Type: k8sResourceType, Labels: map[string]string{
+			"cluster_name":   "prod-eu",
+			"namespace_name": namespace,
+			"pod_name":       pod,
+			"container_name": container,
+		}},
+	}
+}
+
+func k8sTestLogs() []models.LogEntry {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	return []models.LogEntry{
+		k8sTestEntry("1", "shop", "api-7d9f8c6b5-x2k9p", "api", "INFO", base),
+		k8sTestEntry("2", "shop", "api-7d9f8c6b5-x2k9p", "api", "ERROR", base.Add(time.Minute)),
+		k8sTestEntry("3", "shop", "api-7d9f8c6b5-q7w4z", "api", "INFO", base.Add(5*time.Minute)),
+		k8sTestEntry("4", "shop", "api-7d9f8c6b5-q7w4z", "istio-proxy", "INFO", base.Add(6*time.Minute)),
+		k8sTestEntry("5", "shop", "db-0", "postgres", "INFO", base.Add(2*time.Minute)),
+		k8sTestEntry("6", "kube-system", "fluentbit-gke-abcde", "fluentbit", "WARNING", base),
+		{ID: "7", Timestamp: base, Resource: models.Resource{Type: "cloud_run_revision"}},
+	}
+}
+
+func TestK8sWorkload(t *testing.T) {
+	cases := map[string]k8sStep{
+		"api-7d9f8c6b5-x2k9p": {name: "api", pattern: `^api-[a-z0-9]{6,10}-[a-z0-9]{5}$`},
+		"db-0":                {name: "db", pattern: `^db-[0-9]+$`},
+		"fluentbit-gke-abcde": {name: "fluentbit-gke", pattern: `^fluentbit-gke-[a-z0-9]{5}$`},
+		"standalone":          {name: "standalone", pattern: `^standalone$`},
+	}
+	for pod, want := range cases {
+		if got := k8sWorkload(pod, ""); got != want {
+			t.Errorf("k8sWorkload(%q) = %+v, want %+v", pod, got, want)
+		}
+	}
+	if got := k8sWorkload("api-7d9f8c6b5-x2k9p", "checkout"); got.name != "checkout" || got.pattern != "" {
+		t.Errorf("Expected the app label to name the workload, got %+v", got)
+	}
+}
+
+func TestK8sChildrenCounts(t *testing.T) {
+	logs := k8sTestLogs()
+	namespaces := k8sChildren(logs, []k8sStep{{name: "prod-eu"}})
+	if len(namespaces) != 2 || namespaces[0].step.name != "shop" || namespaces[0].count != 5 || namespaces[0].pods != 3 || namespaces[0].errors != 1 {
+		t.Fatalf("Unexpected namespaces %+v", namespaces)
+	}
+
+	path := []k8sStep{{name: "prod-eu"}, {name: "shop"}, k8sWorkload("api-7d9f8c6b5-x2k9p", "")}
+	pods := k8sChildren(logs, path)
+	if len(pods) != 2 || pods[0].step.name != "api-7d9f8c6b5-x2k9p" {
+		t.Fatalf("Expected the pods in start order, got %+v", pods)
+	}
+	if !k8sReplaced(pods, 0) || k8sReplaced(pods, 1) {
+		t.Error("Expected the first pod to be replaced by the second")
+	}
+}
+
+func TestK8sScopeClause(t *testing.T) {
+	workload := k8sWorkload("api-7d9f8c6b5-x2k9p", "")
+	path := []k8sStep{{name: "prod-eu"}, {name: "shop"}, workload}
+	want := `resource.type="k8s_container" AND resource.labels.cluster_name="prod-eu" AND resource.labels.namespace_name="shop" AND resource.labels.pod_name=~"^api-[a-z0-9]{6,10}-[a-z0-9]{5}$"`
+	if got := k8sScopeClause(path); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+
+	path = append(path, k8sStep{name: "api-7d9f8c6b5-x2k9p"})
+	if got := k8sScopeClause(path); strings.Contains(got, "=~") || !strings.HasSuffix(got, `resource.labels.pod_name="api-7d9f8c6b5-x2k9p"`) {
+		t.Errorf("Expected the pod name to replace the workload pattern, got %s", got)
+	}
+	if got := k8sScopeClause([]k8sStep{{name: "c"}, {name: "ns"}, {name: "checkout"}}); !strings.HasSuffix(got, `labels."k8s-pod/app"="checkout"`) {
+		t.Errorf("Expected a label filter for a labelled workload, got %s", got)
+	}
+}
+
+func TestK8sNavigatorScopesQuery(t *testing.T) {
+	app := newTabsTestApp(t)
+	var ran []string
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		ran = append(ran, filter)
+		return nil, nil
+	})
+	app.state.LogListState.Logs = k8sTestLogs()
+	own := `resource.type="k8s_container" AND jsonPayload.msg:"x"`
+	app.state.CurrentQuery.Filter = "severity>=INFO\n" + own
+
+	app = pressKeys(app, runeKey('K'), tea.KeyMsg{Type: tea.KeyEnter})
+	view := ansiEscapeRegex.ReplaceAllString(app.View(), "")
+	if !strings.Contains(view, "prod-eu › namespaces") || !strings.Contains(view, "kube-system") {
+		t.Fatal("Expected the namespaces of the cluster")
+	}
+
+	_, cmd := app.handleKeyPress(runeKey('s'))
+	if cmd == nil {
+		t.Fatal("Expected scoping to run the query")
+	}
+	cmd()
+	want := "severity>=INFO\n" + own + "\n" + k8sScopeComment + "\n" + `resource.type="k8s_container" AND resource.labels.cluster_name="prod-eu" AND resource.labels.namespace_name="shop"`
+	if app.state.CurrentQuery.Filter != want {
+		t.Errorf("Expected the scoped query, got %q", app.state.CurrentQuery.Filter)
+	}
+
+	app = pressKeys(app, runeKey('K'), runeKey('j'), runeKey('s'))
+	got := app.state.CurrentQuery.Filter
+	if strings.Count(got, k8sScopeComment) != 1 || strings.Contains(got, `namespace_name="shop"`) || !strings.Contains(got, `namespace_name="kube-system"`) {
+		t.Errorf("Expected scoping again to replace the line, got %q", got)
+	}
+	if !strings.Contains(got, own) {
+		t.Errorf("Expected the user's own k8s_container line to survive scoping, got %q", got)
+	}
+	if len(ran) == 0 || strings.Contains(ran[len(ran)-1], k8sScopeComment) {
+		t.Errorf("Expected the scoped query to run without the marker, got %q", ran)
+	}
+}
+
+func TestK8sNavigatorComparePods(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) { return nil, nil })
+	app.state.LogListState.Logs = k8sTestLogs()
+
+	// cluster -> shop -> api -> pods
+	app = pressKeys(app, runeKey('K'), tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(app.k8s.path) != k8sLevelPod {
+		t.Fatalf("Expected the pod level, got path %+v", app.k8s.path)
+	}
+	view := ansiEscapeRegex.ReplaceAllString(app.View(), "")
+	if !strings.Contains(view, "replaced") {
+		t.Error("Expected the first pod to be shown as replaced")
+	}
+
+	app = pressKeys(app, runeKey('c'), runeKey('j'), runeKey('c'))
+	if len(app.tabs) != 2 || app.splitMode != splitSide {
+		t.Fatalf("Expected a side by side split of two tabs, got %d tabs split=%q", len(app.tabs), app.splitMode)
+	}
+	if !strings.Contains(app.state.CurrentQuery.Filter, `pod_name="api-7d9f8c6b5-q7w4z"`) {
+		t.Errorf("Expected the new tab on the second pod, got %q", app.state.CurrentQuery.Filter)
+	}
+	partner := app.tabs[app.splitPartnerIndex()]
+	if !strings.Contains(partner.state.CurrentQuery.Filter, `pod_name="api-7d9f8c6b5-x2k9p"`) {
+		t.Errorf("Expected the partner tab on the marked pod, got %q", partner.state.CurrentQuery.Filter)
+	}
+}
+
+func TestK8sDiscoveryReplacesCounts(t *testing.T) {
+	app := newTabsTestApp(t)
+	var ran string
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		ran = filter
+		return k8sTestLogs()[:1], nil
+	})
+	app.openK8sNavigator()
+	_, cmd := app.handleKeyPress(runeKey('d'))
+	if cmd == nil {
+		t.Fatal("Expected a discovery query")
+	}
+	model, _ := app.Update(cmd())
+	app = model.(*App)
+	if !strings.HasPrefix(ran, k8sScopePrefix) {
+		t.Errorf("Expected a k8s_container query, got %q", ran)
+	}
+	if nodes := k8sChildren(app.k8sEntries(), nil); len(nodes) != 1 || nodes[0].count != 1 {
+		t.Errorf("Expected counts from the discovery sample, got %+v", nodes)
+	}
+}
@@ -17,7 +17,7 @@ func renderHorizontalSplit(panes []string, widths []int) string {
 	maxHeight := 0
 
 	for i, pane := range panes {
-		lines := strings.Split(pane, "\n")
+		lines := strings.Split(strings.TrimSuffix(pane, "\n"), "\n")
 		paneLines[i] = lines
 		if len(lines) > maxHeight {
 			maxHeight = len(lines)
@@ -37,12 +37,14 @@ func renderHorizontalSplit(panes []string, widths []int) string {
 		var lineParts []string
 		for paneIdx := range paneLines {
 			line := paneLines[paneIdx][lineIdx]
-			// Pad line to width
+			// Pad line to width; widths are display cells so styled
+			// lines are measured without their escape codes
 			width := widths[paneIdx]
-			if len(line) < width {
-				line += strings.Repeat(" ", width-len(line))
-			} else if len(line) > width {
-				line = line[:width]
+			if lipgloss.Width(line) > width {
+				line = lipgloss.NewStyle().MaxWidth(width).Render(line)
+			}
+			if w := lipgloss.Width(line); w < width {
+				line += strings.Repeat(" ", width-w)
 			}
 			lineParts = append(lineParts, line)
 		}
@@ -52,9 +54,21 @@ func renderHorizontalSplit(panes []string, widths []int) string {
 	return strings.Join(result, "\n")
 }
 
-// renderVerticalSplit renders multiple panes stacked vertically
+// renderVerticalSplit renders multiple panes stacked vertically, padding or
+// cutting each pane to its height
 func renderVerticalSplit(panes []string, heights []int) string {
-	return strings.Join(panes, "\n")
+	var result []string
+	for i, pane := range panes {
+		lines := strings.Split(strings.TrimSuffix(pane, "\n"), "\n")
+		if i < len(heights) && heights[i] > 0 {
+			for len(lines) < heights[i] {
+				lines = append(lines, "")
+			}
+			lines = lines[:heights[i]]
+		}
+		result = append(result, lines...)
+	}
+	return strings.Join(result, "\n")
 }
 
 // StyleBorder applies border styling to content
@@ -2,9 +2,11 @@ package ui
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/user/log-explorer-tui/pkg/models"
 )
 
@@ -14,6 +16,9 @@ type LogFormatter struct {
 	timeFormat   string
 	useColor     bool
 	useLocalTime bool
+	rowTemplates []*rowTemplate
+	rowTemplate  *rowTemplate
+	env          rowTemplateEnv
 }
 
 // NewLogFormatter creates a new log formatter
@@ -27,7 +32,12 @@ func NewLogFormatter(maxWidth int, useColor bool) *LogFormatter {
 }
 
 // FormatLogLine formats a log entry as a single line for list display
+// using the selected row template, if any
 func (lf *LogFormatter) FormatLogLine(entry models.LogEntry, maxLen int) string {
+	if lf.rowTemplate != nil {
+		return lf.formatRowTemplate(entry, maxLen, lf.useColor)
+	}
+
 	// Format: [TIME] [SEVERITY] MESSAGE
 	timestamp := lf.displayTime(entry.Timestamp).Format(lf.timeFormat)
 	severity := padRight(entry.Severity, 8)
@@ -124,15 +134,26 @@ func (lf *LogFormatter) FormatCompact(entry models.LogEntry) string {
 	return sb.String()
 }
 
-// truncate truncates a string to max length with ellipsis
+// truncate cuts a string to maxLen display cells with an ellipsis, never
+// inside a rune
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if lipgloss.Width(s) <= maxLen {
 		return s
 	}
 	if maxLen < 3 {
 		return "..."
 	}
-	return s[:maxLen-3] + "..."
+	var sb strings.Builder
+	width := 0
+	for _, r := range s {
+		w := lipgloss.Width(string(r))
+		if width+w > maxLen-3 {
+			break
+		}
+		sb.WriteRune(r)
+		width += w
+	}
+	return sb.String() + "..."
 }
 
 // padRight pads a string to the right
@@ -171,7 +192,9 @@ func (lf *LogFormatter) HighlightMessage(message, keyword string) string {
 		return message
 	}
 
-	// Simple highlight by surrounding with markers
-	// In actual implementation, would use ANSI codes
+	if lf.useColor {
+		return highlightSpans(message, regexp.MustCompile(regexp.QuoteMeta(keyword)), lipgloss.NewStyle(), searchHitStyle())
+	}
+	// Without color, surround hits with markers
 	return strings.ReplaceAll(message, keyword, fmt.Sprintf("[%s]", keyword))
 }
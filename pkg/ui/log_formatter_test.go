@@ -143,6 +143,8 @@ func TestTruncate(t *testing.T) {
 		{"hello world", 5, "he..."},
 		{"abc", 2, "..."},
 		{"", 5, ""},
+		{"héllo wörld", 8, "héllo..."},
+		{"日本語のテキスト", 7, "日本..."},
 	}
 
 	for _, tt := range tests {
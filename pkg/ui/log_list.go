@@ -183,8 +183,9 @@ func (llv *LogListView) ApplySearch() {
 		return
 	}
 
+	search := newLogSearch(llv.searchTerm, searchInsensitive)
 	for i, log := range llv.logs {
-		if strings.Contains(strings.ToLower(log.Message), llv.searchTerm) {
+		if search.Matches(log) {
 			llv.filteredIndices = append(llv.filteredIndices, i)
 		}
 	}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+// maxMetricSeries caps the numeric fields overlaid on the timeline
+const maxMetricSeries = 4
+
+// normalizeMetricField puts bare field names under jsonPayload
+func normalizeMetricField(field string) string {
+	parts := models.SplitFieldPath(strings.TrimSpace(field))
+	if len(parts) == 0 {
+		return ""
+	}
+	switch parts[0] {
+	case "jsonPayload", "protoPayload", "httpRequest", "labels", "resource", "sourceLocation":
+	default:
+		parts = append([]string{"jsonPayload"}, parts...)
+	}
+	return query.FieldPath(parts...)
+}
+
+// openMetricPrompt asks for a numeric field to plot on the timeline
+func (a *App) openMetricPrompt() {
+	a.prompt.Open("metricField", "PLOT NUMERIC FIELD", "")
+	a.prompt.SetHint("Field such as latency_ms, jsonPayload.queue_depth or httpRequest.responseSize; a plotted field is removed, empty clears")
+	a.activeModalName = "prompt"
+}
+
+// toggleMetricField plots field on the timeline, or removes it when it is
+// plotted already. An empty field clears the plot.
+func (a *App) toggleMetricField(field string) {
+	field = normalizeMetricField(field)
+	switch i := slices.Index(a.metricFields, field); {
+	case field == "":
+		a.metricFields = nil
+		a.lastErr = "Cleared the plotted fields"
+	case i >= 0:
+		a.metricFields = slices.Delete(a.metricFields, i, i+1)
+		a.lastErr = "Removed " + field + " from the plot"
+	case len(a.metricFields) >= maxMetricSeries:
+		a.lastErr = fmt.Sprintf("At most %d fields can be plotted; enter a plotted field to remove it", maxMetricSeries)
+		return
+	default:
+		a.metricFields = append(a.metricFields, field)
+		a.timelineBuilder.SetChartMode(ChartMetrics)
+		values := 0
+		for _, entry := range a.state.LogListState.Logs {
+			if _, ok := NumericFieldValue(entry, field); ok {
+				values++
+			}
+		}
+		a.lastErr = fmt.Sprintf("Plotting the %s of %s from %s loaded values (s on the timeline: min/avg/max/p95)", a.metricStat, field, formatCount(values))
+		if values == 0 {
+			a.lastErr = "Plotting " + field + ": no loaded entry has a numeric value for it yet"
+		}
+	}
+	a.leaveEmptyMetricChart()
+}
+
+// leaveEmptyMetricChart shows the sparkline instead of the metric chart when
+// no field is plotted, as after the last field is removed or on switching to
+// a tab that plots none
+func (a *App) leaveEmptyMetricChart() {
+	if len(a.metricFields) == 0 && a.timelineBuilder.GetChartMode() == ChartMetrics {
+		a.timelineBuilder.SetChartMode(ChartSparkline)
+	}
+}
+
+// plotSelectedNode plots the field of the selected JSON tree node
+func (a *App) plotSelectedNode() {
+	entry := a.getSelectedLog()
+	if entry == nil || a.detailViewMode != "json-tree" {
+		a.lastErr = "Switch to the JSON tree view to plot a field"
+		return
+	}
+	lines := a.currentJSONTreeLines()
+	if a.detailCursor < 0 || a.detailCursor >= len(lines) {
+		a.lastErr = "No payload node selected"
+		return
+	}
+	fields, err := detailNodeField(*entry, lines[a.detailCursor].segments)
+	if err != nil {
+		a.lastErr = "Cannot plot: " + err.Error()
+		return
+	}
+	field := query.FieldPath(fields...)
+	if _, ok := NumericFieldValue(*entry, field); !ok {
+		a.lastErr = "Cannot plot " + field + ": the value is not a number"
+		return
+	}
+	a.toggleMetricField(field)
+}
+
+// cycleMetricStat switches the statistic the series plot
+func (a *App) cycleMetricStat() {
+	i := slices.Index(metricStats, a.metricStat)
+	a.metricStat = metricStats[(i+1)%len(metricStats)]
+	a.lastErr = "Plotting the " + a.metricStat + " per bucket"
+}
+
+// metricSeries builds the plotted series over the loaded entries, in the
+// timeline buckets
+func (a *App) metricSeries() []MetricSeries {
+	series := make([]MetricSeries, 0, len(a.metricFields))
+	for _, field := range a.metricFields {
+		series = append(series, MetricSeries{
+			Field:  field,
+			Stat:   a.metricStat,
+			Points: a.timelineBuilder.BuildMetricSeries(a.state.LogListState.Logs, field),
+		})
+	}
+	return series
+}
+
+// metricTooltips describes every series in the bucket under the timeline
+// cursor, one line per series after a swatch of its color
+func (a *App) metricTooltips(points []models.LogGraphPoint, series []MetricSeries) []string {
+	if len(points) == 0 {
+		return nil
+	}
+	cursor := points[clampInt(a.timelineCursor, 0, len(points)-1)].Timestamp
+	var lines []string
+	for i, s := range series {
+		text := "no values in this bucket"
+		for _, point := range s.Points {
+			if point.Timestamp.Equal(cursor) {
+				text = metricSummary(point)
+				break
+			}
+		}
+		swatch := lipgloss.NewStyle().Foreground(lipgloss.Color(*metricColors[i%len(metricColors)])).Render("■")
+		lines = append(lines, swatch+" "+truncate(s.Field+"  "+text, a.panelInnerWidth()-2))
+	}
+	return lines
+}
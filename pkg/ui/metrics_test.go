@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func TestNormalizeMetricField(t *testing.T) {
+	for field, want := range map[string]string{
+		"latency_ms":               "jsonPayload.latency_ms",
+		"jsonPayload.queue_depth":  "jsonPayload.queue_depth",
+		"httpRequest.responseSize": "httpRequest.responseSize",
+		" ":                        "",
+	} {
+		if got := normalizeMetricField(field); got != want {
+			t.Errorf("normalizeMetricField(%q) = %q, want %q", field, got, want)
+		}
+	}
+}
+
+func TestPlotMetricFieldWithTooltips(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.SetTimelineBucket(time.Minute)
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	app.state.LogListState.Logs = metricTestLogs(start)
+
+	app = pressKeys(app, runeKey('#'))
+	if app.activeModalName != "prompt" || app.prompt.Purpose() != "metricField" {
+		t.Fatalf("Expected # to ask for a field, got %q", app.activeModalName)
+	}
+	app = pressKeys(app, runeKey('l'), runeKey('a'), runeKey('t'), runeKey('e'), runeKey('n'), runeKey('c'), runeKey('y'), runeKey('_'), runeKey('m'), runeKey('s'), tea.KeyMsg{Type: tea.KeyEnter})
+	if len(app.metricFields) != 1 || app.timelineBuilder.GetChartMode() != ChartMetrics {
+		t.Fatalf("Expected latency_ms plotted, got %v in %s mode", app.metricFields, app.timelineBuilder.GetChartMode())
+	}
+	if !strings.Contains(app.lastErr, "from 40 loaded values") {
+		t.Errorf("Unexpected status %q", app.lastErr)
+	}
+	app.toggleMetricField("queue_depth")
+
+	app.panes.LogList.scrollOffset = len(app.viewLogs()) - 1 // 10:00:00
+	app = pressKeys(app, tea.KeyMsg{Type: tea.KeyTab}, runeKey('s'))
+	if app.metricStat != MetricP95 {
+		t.Errorf("Expected s to plot the p95, got %s", app.metricStat)
+	}
+	panel := ansiEscapeRegex.ReplaceAllString(app.renderGraphPanel(), "")
+	for _, want := range []string{
+		"Timeline ■ latency_ms p95 ↑40  ■ queue_depth p95 ↑9",
+		"■ jsonPayload.latency_ms  min 10 avg 25 max 40 p95 40 (4 values)",
+		"■ jsonPayload.queue_depth  min 0 avg 0 max 0 p95 0 (4 values)",
+		"u zoom out | s p95",
+	} {
+		if !strings.Contains(panel, want) {
+			t.Errorf("Expected %q in the graph panel, got:\n%s", want, panel)
+		}
+	}
+
+	// V cycles through the charts and back to the plot
+	app = pressKeys(app, tea.KeyMsg{Type: tea.KeyEsc}, runeKey('V'), runeKey('V'))
+	if app.timelineBuilder.GetChartMode() != ChartStacked {
+		t.Errorf("Expected the stacked chart after the sparkline, got %s", app.timelineBuilder.GetChartMode())
+	}
+	app = pressKeys(app, runeKey('V'))
+	if app.timelineBuilder.GetChartMode() != ChartMetrics {
+		t.Errorf("Expected the plot after the stacked chart, got %s", app.timelineBuilder.GetChartMode())
+	}
+
+	// Entering a plotted field removes it; an empty field clears the plot
+	app.toggleMetricField("jsonPayload.latency_ms")
+	if len(app.metricFields) != 1 || app.metricFields[0] != "jsonPayload.queue_depth" {
+		t.Errorf("Expected latency_ms removed, got %v", app.metricFields)
+	}
+	app = pressKeys(app, runeKey('#'), tea.KeyMsg{Type: tea.KeyEnter})
+	if len(app.metricFields) != 0 || app.timelineBuilder.GetChartMode() != ChartSparkline {
+		t.Errorf("Expected the plot cleared, got %v in %s mode", app.metricFields, app.timelineBuilder.GetChartMode())
+	}
+}
+
+func TestPlotSelectedTreeNode(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.state.LogListState.Logs = metricTestLogs(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))[:4]
+
+	newModel, _ := app.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	app = newModel.(*App)
+	app.detailTreeExpanded["$.payload"] = true
+	app.detailCursor = treeLineAt(t, app, "$.payload.queue_depth")
+	app = pressKeys(app, runeKey('#'))
+	if len(app.metricFields) != 1 || app.metricFields[0] != "jsonPayload.queue_depth" {
+		t.Errorf("Expected the selected node plotted, got %v (%s)", app.metricFields, app.lastErr)
+	}
+}
+
+func TestPlotMetricFieldSizesBucketsInUpdate(t *testing.T) {
+	app := newTabsTestApp(t)
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	app.state.LogListState.Logs = metricTestLogs(start)
+	app.state.FilterState.TimeRange = models.TimeRange{Start: start.Add(-2 * time.Hour), End: start.Add(10 * time.Minute)}
+
+	app = pressKeys(app, runeKey('#'))
+	for _, r := range "latency_ms" {
+		app = pressKeys(app, runeKey(r))
+	}
+	app = pressKeys(app, tea.KeyMsg{Type: tea.KeyEnter})
+	if app.timelineBuilder.GetChartMode() != ChartMetrics {
+		t.Fatalf("Expected the metric chart, got %s", app.timelineBuilder.GetChartMode())
+	}
+	spanStart, spanEnd, _ := app.timelineSpan()
+	want := AdaptiveBucketSize(spanEnd.Sub(spanStart), app.timelineColumns())
+	if got := app.timelineBuilder.GetBucketSize(); got != want {
+		t.Errorf("Expected the bucket of the chart width, %s, got %s", want, got)
+	}
+
+	// Rendering only reads the bucket size
+	app.timelineBuilder.SetBucketSize(time.Hour)
+	app.View()
+	if got := app.timelineBuilder.GetBucketSize(); got != time.Hour {
+		t.Errorf("Expected View to leave the bucket size alone, got %s", got)
+	}
+}
+
+func TestTabsKeepTheirPlottedFields(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.state.LogListState.Logs = metricTestLogs(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	app.toggleMetricField("latency_ms")
+	app.cycleMetricStat()
+
+	app.openNewTab()
+	if len(app.metricFields) != 1 || app.metricStat != MetricP95 {
+		t.Fatalf("Expected the new tab to start with the plot, got %v %s", app.metricFields, app.metricStat)
+	}
+	app.toggleMetricField("")
+	if app.timelineBuilder.GetChartMode() != ChartSparkline {
+		t.Errorf("Expected the sparkline once the plot is cleared, got %s", app.timelineBuilder.GetChartMode())
+	}
+
+	app.switchTab(0)
+	if len(app.metricFields) != 1 || app.metricFields[0] != "jsonPayload.latency_ms" || app.metricStat != MetricP95 {
+		t.Errorf("Expected the first tab's plot back, got %v %s", app.metricFields, app.metricStat)
+	}
+	app.toggleMetricField("queue_depth")
+	app.switchTab(1)
+	if len(app.metricFields) != 0 || app.timelineBuilder.GetChartMode() != ChartSparkline {
+		t.Errorf("Expected the second tab without a plot, got %v in %s mode", app.metricFields, app.timelineBuilder.GetChartMode())
+	}
+}
@@ -0,0 +1,332 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+// patternWildcard stands for a variable token in a pattern template
+const patternWildcard = "<*>"
+
+// patternSimilarity is the share of tokens a message must have in common
+// with a template to join its pattern
+const patternSimilarity = 0.5
+
+// Variable tokens masked before clustering, in order
+var (
+	patternUUIDRegex   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	patternIPRegex     = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b`)
+	patternIDRegex     = regexp.MustCompile(`\b(?:0x)?[0-9a-fA-F]{8,}\b|\b[A-Za-z0-9_-]{16,}\b`)
+	patternNumberRegex = regexp.MustCompile(`\b\d+(?:\.\d+)?(?:ms|us|ns|s|m|h|[kKMG]?B)?\b`)
+)
+
+// logPattern is a message template and the entries that match it
+type logPattern struct {
+	tokens   []string
+	entries  []models.LogEntry
+	severity string // highest severity
+	first    time.Time
+	last     time.Time
+}
+
+func (p *logPattern) template() string {
+	return strings.Join(p.tokens, " ")
+}
+
+// add adds an entry with the masked message tokens, turning the tokens that
+// differ from the template into wildcards
+func (p *logPattern) add(entry models.LogEntry, tokens []string) {
+	for i, token := range tokens {
+		if p.tokens[i] != token {
+			p.tokens[i] = patternWildcard
+		}
+	}
+	p.entries = append(p.entries, entry)
+	if models.SeverityRank(entry.Severity) > models.SeverityRank(p.severity) {
+		p.severity = entry.Severity
+	}
+	if p.first.IsZero() || entry.Timestamp.Before(p.first) {
+		p.first = entry.Timestamp
+	}
+	if entry.Timestamp.After(p.last) {
+		p.last = entry.Timestamp
+	}
+}
+
+// severityMix renders the entry count per severity badge, e.g. "ERR 2 INF 40"
+func (p *logPattern) severityMix() string {
+	counts := map[string]int{}
+	for _, entry := range p.entries {
+		_, badge := severityBadge(entry.Severity)
+		counts[badge]++
+	}
+	var parts []string
+	for _, badge := range []string{"ERR", "WRN", "INF", "DBG", "LOG"} {
+		if counts[badge] > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", badge, counts[badge]))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// maskMessage replaces UUIDs, IP addresses, IDs and numbers in the first line
+// of a message with wildcards and splits it into tokens
+func maskMessage(message string) []string {
+	line, _, _ := strings.Cut(message, "\n")
+	line = patternUUIDRegex.ReplaceAllString(line, patternWildcard)
+	line = patternIPRegex.ReplaceAllString(line, patternWildcard)
+	line = patternIDRegex.ReplaceAllStringFunc(line, func(token string) string {
+		if strings.ContainsAny(token, "0123456789") {
+			return patternWildcard
+		}
+		return token
+	})
+	line = patternNumberRegex.ReplaceAllString(line, patternWildcard)
+	return strings.Fields(line)
+}
+
+// patternMiner clusters messages into templates in one pass, Drain style:
+// messages are grouped by token count and first token, then each joins the
+// most similar pattern of its group or starts a new one
+type patternMiner struct {
+	groups   map[string][]*logPattern
+	patterns []*logPattern
+}
+
+func newPatternMiner() *patternMiner {
+	return &patternMiner{groups: map[string][]*logPattern{}}
+}
+
+func (m *patternMiner) add(entry models.LogEntry) {
+	tokens := maskMessage(listMessage(entry))
+	key := strconv.Itoa(len(tokens))
+	if len(tokens) > 0 && !strings.Contains(tokens[0], patternWildcard) {
+		key += " " + tokens[0]
+	}
+
+	var best *logPattern
+	bestScore := -1.0
+	for _, p := range m.groups[key] {
+		if score := tokenSimilarity(p.tokens, tokens); score >= patternSimilarity && score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	if best == nil {
+		best = &logPattern{tokens: slices.Clone(tokens)}
+		m.groups[key] = append(m.groups[key], best)
+		m.patterns = append(m.patterns, best)
+	}
+	best.add(entry, tokens)
+}
+
+// tokenSimilarity is the share of positions where template and tokens hold
+// the same literal token. Empty messages are alike.
+func tokenSimilarity(template, tokens []string) float64 {
+	if len(tokens) == 0 {
+		return 1
+	}
+	same := 0
+	for i, token := range tokens {
+		if template[i] == token && token != patternWildcard {
+			same++
+		}
+	}
+	return float64(same) / float64(len(tokens))
+}
+
+// minePatterns clusters logs into patterns, largest first
+func minePatterns(logs []models.LogEntry) []*logPattern {
+	m := newPatternMiner()
+	for _, entry := range logs {
+		m.add(entry)
+	}
+	patterns := m.patterns
+	sort.SliceStable(patterns, func(i, j int) bool {
+		if len(patterns[i].entries) != len(patterns[j].entries) {
+			return len(patterns[i].entries) > len(patterns[j].entries)
+		}
+		return patterns[i].first.Before(patterns[j].first)
+	})
+	for _, p := range patterns {
+		sort.SliceStable(p.entries, func(i, j int) bool {
+			return p.entries[i].Timestamp.Before(p.entries[j].Timestamp)
+		})
+	}
+	return patterns
+}
+
+// patternExcludeClause excludes entries that contain every fixed part of
+// the template, e.g. NOT ("connection to" AND "timed out")
+func patternExcludeClause(template string) (string, bool) {
+	var terms []string
+	for _, part := range strings.Split(template, patternWildcard) {
+		part = strings.TrimSpace(part)
+		if strings.IndexFunc(part, unicode.IsLetter) >= 0 {
+			terms = append(terms, strconv.Quote(part))
+		}
+	}
+	switch len(terms) {
+	case 0:
+		return "", false
+	case 1:
+		return "NOT " + terms[0], true
+	}
+	return "NOT (" + strings.Join(terms, " AND ") + ")", true
+}
+
+// patternView is the state of the pattern view
+type patternView struct {
+	patterns    []*logPattern
+	cursor      int
+	open        *logPattern // pattern whose entries are listed
+	entryCursor int
+	total       int
+}
+
+// openPatternView clusters the shown entries into patterns
+func (a *App) openPatternView() {
+	logs := a.viewLogs()
+	if len(logs) == 0 {
+		a.lastErr = "No log entries to find patterns in"
+		return
+	}
+	a.patterns = patternView{patterns: minePatterns(logs), total: len(logs)}
+	a.activeModalName = "patterns"
+	a.lastErr = fmt.Sprintf("%d patterns in %s entries", len(a.patterns.patterns), formatCount(len(logs)))
+}
+
+// excludePattern hides the selected pattern with a local filter, or adds the
+// clause to the query when inQuery is set
+func (a *App) excludePattern(inQuery bool) tea.Cmd {
+	pv := &a.patterns
+	if pv.cursor >= len(pv.patterns) {
+		return nil
+	}
+	clause, ok := patternExcludeClause(pv.patterns[pv.cursor].template())
+	if !ok {
+		a.lastErr = "The pattern has no fixed text to filter on"
+		return nil
+	}
+	if inQuery {
+		a.activeModalName = "none"
+		a.lastErr = "Added to query: " + clause
+		return a.appendToQueryAndRun(clause)
+	}
+	a.updateLocalFilters(func(fs []models.LocalFilter) []models.LocalFilter {
+		return append(fs, models.LocalFilter{Kind: query.LocalKindQuery, Expr: clause, Enabled: true})
+	})
+	cursor := pv.cursor
+	a.activeModalName = "none"
+	a.openPatternView()
+	a.patterns.cursor = clampInt(cursor, 0, maxInt(0, len(a.patterns.patterns)-1))
+	a.lastErr = fmt.Sprintf("Local filter %s: %s", clause, a.shownCountLabel())
+	return nil
+}
+
+// handlePatternInput handles keys in the pattern view
+func (a *App) handlePatternInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	pv := &a.patterns
+	if pv.open != nil {
+		switch msg.String() {
+		case "esc", "h", "left":
+			pv.open = nil
+		case "j", "down":
+			pv.entryCursor = minInt(pv.entryCursor+1, maxInt(0, len(pv.open.entries)-1))
+		case "k", "up":
+			pv.entryCursor = maxInt(0, pv.entryCursor-1)
+		case "enter":
+			if pv.entryCursor < len(pv.open.entries) {
+				a.jumpToEntry(pv.open.entries[pv.entryCursor])
+			}
+		}
+		return a, nil
+	}
+	switch msg.String() {
+	case "esc", "M":
+		a.activeModalName = "none"
+	case "j", "down":
+		pv.cursor = minInt(pv.cursor+1, maxInt(0, len(pv.patterns)-1))
+	case "k", "up":
+		pv.cursor = maxInt(0, pv.cursor-1)
+	case "enter", "l", "right":
+		if pv.cursor < len(pv.patterns) {
+			pv.open = pv.patterns[pv.cursor]
+			pv.entryCursor = 0
+		}
+	case "x":
+		return a, a.excludePattern(false)
+	case "X":
+		return a, a.excludePattern(true)
+	}
+	return a, nil
+}
+
+func (a *App) renderPatternPopup() string {
+	var sb strings.Builder
+	popupWidth := minInt(maxInt(70, a.width-10), 140)
+	inner := a.popupInnerWidth(popupWidth)
+	subtle := lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle))
+	selected := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorSelectionFG)).Background(lipgloss.Color(colorSelectionBG))
+	pv := &a.patterns
+	visible := maxInt(3, a.height-14)
+
+	if p := pv.open; p != nil {
+		sb.WriteString(a.popupTop(popupWidth, "PATTERN ENTRIES"))
+		sb.WriteString(a.popupLine(popupWidth, truncate(p.template(), inner)))
+		sb.WriteString(a.popupLine(popupWidth, subtle.Render(fmt.Sprintf("%d entries | %s", len(p.entries), p.severityMix()))))
+		sb.WriteString(a.popupSeparator(popupWidth, '━'))
+		start := clampInt(pv.entryCursor-visible/2, 0, maxInt(0, len(p.entries)-visible))
+		end := minInt(len(p.entries), start+visible)
+		for i := start; i < end; i++ {
+			entry := p.entries[i]
+			_, badge := severityBadge(entry.Severity)
+			prefix := fmt.Sprintf("%s %-5s ", a.displayTime(entry.Timestamp).Format("15:04:05.000"), badge)
+			line := prefix + truncate(listMessage(entry), maxInt(10, inner-lipgloss.Width(prefix)))
+			if i == pv.entryCursor {
+				line = selected.Render(line)
+			} else {
+				line = severityStyle(entry.Severity).Render(line)
+			}
+			sb.WriteString(a.popupLine(popupWidth, line))
+		}
+		sb.WriteString(a.popupSeparator(popupWidth, '━'))
+		sb.WriteString(a.popupLine(popupWidth, "Enter jump to entry in the list | Esc back to patterns"))
+		sb.WriteString(a.popupBottom(popupWidth, '━'))
+		return sb.String()
+	}
+
+	sb.WriteString(a.popupTop(popupWidth, "PATTERNS"))
+	sb.WriteString(a.popupLine(popupWidth, subtle.Render(fmt.Sprintf("%d patterns in %s shown entries; numbers, IDs, IPs and UUIDs are masked as %s", len(pv.patterns), formatCount(pv.total), patternWildcard))))
+	header := fmt.Sprintf("%s  %s  %s  %s", fitCell("COUNT", 6, columnAlignRight), fitCell("SEVERITY", 18, columnAlignLeft), fitCell("FIRST - LAST", 17, columnAlignLeft), "TEMPLATE")
+	sb.WriteString(a.popupLine(popupWidth, subtle.Render(header)))
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+	start := clampInt(pv.cursor-visible/2, 0, maxInt(0, len(pv.patterns)-visible))
+	end := minInt(len(pv.patterns), start+visible)
+	for i := start; i < end; i++ {
+		p := pv.patterns[i]
+		seen := a.displayTime(p.first).Format("15:04:05") + " - " + a.displayTime(p.last).Format("15:04:05")
+		prefix := fmt.Sprintf("%s  %s  %s  ", fitCell(formatCount(len(p.entries)), 6, columnAlignRight), fitCell(p.severityMix(), 18, columnAlignLeft), fitCell(seen, 17, columnAlignLeft))
+		line := prefix + truncate(p.template(), maxInt(10, inner-lipgloss.Width(prefix)))
+		if i == pv.cursor {
+			line = selected.Render(line)
+		} else {
+			line = severityStyle(p.severity).Render(line)
+		}
+		sb.WriteString(a.popupLine(popupWidth, line))
+	}
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+	sb.WriteString(a.popupLine(popupWidth, "Enter entries | x hide pattern | X exclude from query | Esc close"))
+	sb.WriteString(a.popupBottom(popupWidth, '━'))
+	return sb.String()
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func patternTestLogs() []models.LogEntry {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	messages := []struct{ severity, message string }{
+		{"INFO", "GET /api/orders/1842 completed in 12ms"},
+		{"INFO", "GET /api/orders/1843 completed in 48ms"},
+		{"ERROR", "connection to 10.0.0.12:5432 timed out after 30s"},
+		{"INFO", "GET /api/orders/1844 completed in 7ms"},
+		{"ERROR", "connection to 10.0.0.13:5432 timed out after 30s"},
+		{"WARNING", "user 3f2b6c1e-8a1d-4c7e-9b0a-2d4e6f8a0b1c retried checkout"},
+	}
+	var logs []models.LogEntry
+	for i, m := range messages {
+		logs = append(logs, models.LogEntry{
+			ID:        fmt.Sprintf("%d", i+1),
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+			Severity:  m.severity,
+			Message:   m.message,
+		})
+	}
+	return logs
+}
+
+func TestMaskMessage(t *testing.T) {
+	tests := map[string]string{
+		"connection to 10.0.0.12:5432 timed out after 30s":    "connection to <*> timed out after <*>",
+		"user 3f2b6c1e-8a1d-4c7e-9b0a-2d4e6f8a0b1c logged in": "user <*> logged in",
+		"trace 4bf92f3577b34da6 ok\nstack":                    "trace <*> ok",
+		"GET /api/orders/1842 in 12ms":                        "GET /api/orders/<*> in <*>",
+		"build v2 deployed":                                   "build v2 deployed",
+	}
+	for message, want := range tests {
+		if got := strings.Join(maskMessage(message), " "); got != want {
+			t.Errorf("maskMessage(%q) = %q, want %q", message, got, want)
+		}
+	}
+}
+
+func TestMinePatterns(t *testing.T) {
+	patterns := minePatterns(patternTestLogs())
+	if len(patterns) != 3 {
+		t.Fatalf("Expected 3 patterns, got %d", len(patterns))
+	}
+	first := patterns[0]
+	if first.template() != "GET /api/orders/<*> completed in <*>" || len(first.entries) != 3 {
+		t.Errorf("Expected the largest pattern first, got %q (%d)", first.template(), len(first.entries))
+	}
+	if got := patterns[1].severityMix(); got != "ERR 2" || patterns[1].severity != "ERROR" {
+		t.Errorf("Unexpected severity mix %q", got)
+	}
+	if !patterns[1].first.Before(patterns[1].last) {
+		t.Error("Expected first and last seen times")
+	}
+
+	// Tokens that vary between messages become wildcards
+	logs := []models.LogEntry{{Message: "cache hit for users"}, {Message: "cache hit for orders"}, {Message: "cache miss for orders"}}
+	if patterns := minePatterns(logs); len(patterns) != 1 || patterns[0].template() != "cache <*> for <*>" {
+		t.Errorf("Expected one merged pattern, got %d", len(patterns))
+	}
+}
+
+func TestPatternExcludeClause(t *testing.T) {
+	got, ok := patternExcludeClause("connection to <*> timed out after <*>")
+	if !ok || got != `NOT ("connection to" AND "timed out after")` {
+		t.Errorf("Unexpected clause %q", got)
+	}
+	if got, _ := patternExcludeClause("ready"); got != `NOT "ready"` {
+		t.Errorf("Unexpected clause %q", got)
+	}
+	if _, ok := patternExcludeClause("<*> <*>"); ok {
+		t.Error("Expected no clause for a template without fixed text")
+	}
+}
+
+func TestPatternViewDrillAndExclude(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.state.LogListState.Logs = patternTestLogs()
+	var ran string
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		ran = filter
+		return nil, nil
+	})
+
+	app = pressKeys(app, runeKey('M'))
+	if app.activeModalName != "patterns" {
+		t.Fatalf("Expected M to open the pattern view, got %q", app.activeModalName)
+	}
+	view := ansiEscapeRegex.ReplaceAllString(app.View(), "")
+	for _, want := range []string{"PATTERNS", "3 patterns in 6 shown entries", "GET /api/orders/<*> completed in <*>", "ERR 2"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the pattern view", want)
+		}
+	}
+
+	// Entries of the connection pattern, then jump to the second one
+	app = pressKeys(app, runeKey('j'), tea.KeyMsg{Type: tea.KeyEnter})
+	if view := ansiEscapeRegex.ReplaceAllString(app.View(), ""); !strings.Contains(view, "PATTERN ENTRIES") {
+		t.Fatal("Expected the entries of the pattern")
+	}
+	app = pressKeys(app, runeKey('j'), tea.KeyMsg{Type: tea.KeyEnter})
+	if selected := app.getSelectedLog(); app.activeModalName != "none" || selected == nil || selected.ID != "5" {
+		t.Fatalf("Expected to jump to entry 5, modal=%q selected=%+v", app.activeModalName, selected)
+	}
+
+	// x hides the pattern locally
+	app = pressKeys(app, runeKey('M'), runeKey('j'), runeKey('x'))
+	if len(app.viewLogs()) != 4 || len(app.patterns.patterns) != 2 {
+		t.Errorf("Expected the pattern hidden, %d shown in %d patterns", len(app.viewLogs()), len(app.patterns.patterns))
+	}
+
+	// X excludes it in the query
+	var cmd tea.Cmd
+	app.patterns.cursor = 0
+	_, cmd = app.handleKeyPress(runeKey('X'))
+	if cmd == nil || app.activeModalName != "none" {
+		t.Fatal("Expected X to run the query")
+	}
+	cmd()
+	if !strings.Contains(ran, `NOT ("GET /api/orders/" AND "completed in")`) {
+		t.Errorf("Expected the exclusion in the query, got %q", ran)
+	}
+}
@@ -0,0 +1,216 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+// Prompt is a single-line text input shown as a popup. The purpose string
+// tells the app what to do with the value when it is submitted.
+type Prompt struct {
+	visible bool
+	purpose string
+	title   string
+	hint    string
+	value   []rune
+	cursor  int
+}
+
+// NewPrompt creates a hidden prompt
+func NewPrompt() *Prompt {
+	return &Prompt{}
+}
+
+// Open shows the prompt with an initial value and the cursor at the end
+func (p *Prompt) Open(purpose, title, initial string) {
+	p.visible = true
+	p.purpose = purpose
+	p.title = title
+	p.hint = ""
+	p.value = []rune(initial)
+	p.cursor = len(p.value)
+}
+
+// Close hides the prompt
+func (p *Prompt) Close() {
+	p.visible = false
+}
+
+// IsVisible returns if the prompt is shown
+func (p *Prompt) IsVisible() bool {
+	return p.visible
+}
+
+// Purpose returns what the prompt was opened for
+func (p *Prompt) Purpose() string {
+	return p.purpose
+}
+
+// Title returns the prompt title
+func (p *Prompt) Title() string {
+	return p.title
+}
+
+// SetTitle replaces the title, e.g. to show a mode changed while typing
+func (p *Prompt) SetTitle(title string) {
+	p.title = title
+}
+
+// SetHint sets a short help line rendered under the input
+func (p *Prompt) SetHint(hint string) {
+	p.hint = hint
+}
+
+// Hint returns the help line
+func (p *Prompt) Hint() string {
+	return p.hint
+}
+
+// Value returns the current input
+func (p *Prompt) Value() string {
+	return string(p.value)
+}
+
+// SetValue replaces the input and moves the cursor to the end
+func (p *Prompt) SetValue(value string) {
+	p.value = []rune(value)
+	p.cursor = len(p.value)
+}
+
+// HandleKey applies an editing key. It returns false for keys it does not
+// handle so the caller can treat them (enter, esc, tab, ...).
+func (p *Prompt) HandleKey(key string, runes []rune) bool {
+	switch key {
+	case "left":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "right":
+		if p.cursor < len(p.value) {
+			p.cursor++
+		}
+	case "home", "ctrl+a":
+		p.cursor = 0
+	case "end", "ctrl+e":
+		p.cursor = len(p.value)
+	case "backspace":
+		if p.cursor > 0 {
+			p.value = append(p.value[:p.cursor-1], p.value[p.cursor:]...)
+			p.cursor--
+		}
+	case "delete":
+		if p.cursor < len(p.value) {
+			p.value = append(p.value[:p.cursor], p.value[p.cursor+1:]...)
+		}
+	case "ctrl+u":
+		p.value = p.value[p.cursor:]
+		p.cursor = 0
+	case "ctrl+w":
+		start := p.cursor
+		for start > 0 && p.value[start-1] == ' ' {
+			start--
+		}
+		for start > 0 && p.value[start-1] != ' ' {
+			start--
+		}
+		p.value = append(p.value[:start], p.value[p.cursor:]...)
+		p.cursor = start
+	default:
+		if len(runes) == 0 || key == "enter" || key == "esc" || key == "tab" {
+			return false
+		}
+		inserted := make([]rune, 0, len(p.value)+len(runes))
+		inserted = append(inserted, p.value[:p.cursor]...)
+		inserted = append(inserted, runes...)
+		inserted = append(inserted, p.value[p.cursor:]...)
+		p.value = inserted
+		p.cursor += len(runes)
+	}
+	return true
+}
+
+// InputWithCursor renders the input with a block cursor
+func (p *Prompt) InputWithCursor() string {
+	var sb strings.Builder
+	sb.WriteString(string(p.value[:p.cursor]))
+	sb.WriteString("█")
+	sb.WriteString(string(p.value[p.cursor:]))
+	return sb.String()
+}
+
+// handlePromptInput handles input when the prompt popup is active
+func (a *App) handlePromptInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	search := a.prompt.Purpose() == "search"
+	switch msg.String() {
+	case "esc":
+		purpose := a.prompt.Purpose()
+		a.prompt.Close()
+		a.activeModalName = "none"
+		if strings.HasPrefix(purpose, "local") {
+			a.activeModalName = "filterStack"
+		} else if purpose == "facetPin" {
+			a.activeModalName = "facets"
+		} else if strings.HasPrefix(purpose, "column") {
+			a.activeModalName = "columns"
+		}
+		if search {
+			a.cancelSearchPrompt()
+		}
+		return a, nil
+	case "enter":
+		purpose := a.prompt.Purpose()
+		value := a.prompt.Value()
+		a.prompt.Close()
+		a.activeModalName = "none"
+		return a, a.submitPrompt(purpose, value)
+	}
+	if search && a.handleSearchPromptKey(msg.String()) {
+		return a, nil
+	}
+	if a.prompt.HandleKey(msg.String(), msg.Runes) && search {
+		a.applySearchInput(a.prompt.Value())
+	}
+	return a, nil
+}
+
+// submitPrompt applies a submitted prompt value according to its purpose
+func (a *App) submitPrompt(purpose, value string) tea.Cmd {
+	switch purpose {
+	case "renameTab":
+		a.renameActiveTab(value)
+	case "search":
+		a.submitSearch(value)
+	case "localQuery":
+		a.addLocalFilter(query.LocalKindQuery, value)
+	case "localGrep":
+		a.addLocalFilter(query.LocalKindGrep, value)
+	case "localEdit":
+		a.editLocalFilter(value)
+	case "facetPin":
+		a.pinFacetField(value)
+	case "columnAdd":
+		a.submitColumnSpec(value, false)
+	case "columnEdit":
+		a.submitColumnSpec(value, true)
+	case "metricField":
+		a.toggleMetricField(value)
+	}
+	return nil
+}
+
+func (a *App) renderPromptPopup() string {
+	var sb strings.Builder
+	popupWidth := minInt(maxInt(40, a.width-20), 90)
+	sb.WriteString(a.popupTop(popupWidth, a.prompt.Title()))
+	sb.WriteString(a.popupLine(popupWidth, "> "+a.prompt.InputWithCursor()))
+	if hint := a.prompt.Hint(); hint != "" {
+		sb.WriteString(a.popupLine(popupWidth, lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle)).Render(hint)))
+	}
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+	sb.WriteString(a.popupLine(popupWidth, "Enter apply | Esc cancel"))
+	sb.WriteString(a.popupBottom(popupWidth, '━'))
+	return sb.String()
+}
@@ -0,0 +1,36 @@
+package ui
+
+import "testing"
+
+func TestPromptEditing(t *testing.T) {
+	p := NewPrompt()
+	p.Open("renameTab", "RENAME TAB", "api worker")
+	if !p.IsVisible() || p.Purpose() != "renameTab" {
+		t.Fatalf("Expected visible prompt, got %+v", p)
+	}
+
+	p.HandleKey("ctrl+w", nil)
+	if p.Value() != "api " {
+		t.Errorf("Expected last word deleted, got %q", p.Value())
+	}
+	p.HandleKey("backspace", nil)
+	p.HandleKey("home", nil)
+	p.HandleKey("x", []rune("x-"))
+	if p.Value() != "x-api" {
+		t.Errorf("Expected insert at start, got %q", p.Value())
+	}
+	if p.InputWithCursor() != "x-█api" {
+		t.Errorf("Unexpected cursor rendering %q", p.InputWithCursor())
+	}
+	p.HandleKey("ctrl+u", nil)
+	if p.Value() != "api" {
+		t.Errorf("Expected text before cursor removed, got %q", p.Value())
+	}
+	if p.HandleKey("enter", nil) {
+		t.Error("Enter should be left to the caller")
+	}
+	p.Close()
+	if p.IsVisible() {
+		t.Error("Expected prompt to be hidden")
+	}
+}
@@ -68,8 +68,8 @@ func (qh *QueryHandler) BuildFilterFromState(fs models.FilterState) string {
 	builder := query.NewBuilder("")
 
 	// Add custom filters
-	for key, value := range fs.CustomFilters {
-		builder.AddCustomFilter(fmt.Sprintf("%s=%q", key, value))
+	for _, clause := range query.CustomFilterClauses(fs.CustomFilters) {
+		builder.AddCustomFilter(clause)
 	}
 
 	// Add severity filter
@@ -0,0 +1,262 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/config"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// rowTemplate is a compiled row format from the rowTemplates config. It is
+// executed with the models.LogEntry as data, so {{.Resource.Labels.pod_name}}
+// and {{.JSONPayload.method}} work as written.
+type rowTemplate struct {
+	name string
+	tmpl *template.Template
+}
+
+// rowTemplateEnv carries the settings of one execution to the helpers
+type rowTemplateEnv struct {
+	color bool
+	now   time.Time
+}
+
+// valueColors is the palette that color picks from for non-severity values
+var valueColors = []string{"39", "42", "141", "208", "44", "170", "149", "215", "75", "204"}
+
+// rowTemplateFuncs returns the helpers available in row templates
+func (lf *LogFormatter) rowTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// pad pads to n cells; a negative n right-aligns
+		"pad": func(n int, v interface{}) string {
+			if n < 0 {
+				return fitCell(templateString(v), -n, columnAlignRight)
+			}
+			return fitCell(templateString(v), n, columnAlignLeft)
+		},
+		"trunc": func(n int, v interface{}) string {
+			s := templateString(v)
+			if lipgloss.Width(s) <= n {
+				return s
+			}
+			return fitCell(s, n, columnAlignLeft)
+		},
+		// color picks a stable color per value; severities use their level color
+		"color": func(v interface{}) string {
+			s := templateString(v)
+			if !lf.env.color || s == "" {
+				return s
+			}
+			if models.SeverityRank(s) >= 0 {
+				return severityStyle(strings.ToUpper(s)).Render(s)
+			}
+			h := fnv.New32a()
+			h.Write([]byte(strings.TrimSpace(s)))
+			return lipgloss.NewStyle().Foreground(lipgloss.Color(valueColors[h.Sum32()%uint32(len(valueColors))])).Render(s)
+		},
+		"ago": func(t time.Time) string {
+			return relativeTime(lf.env.now.Sub(t))
+		},
+		"time": func(layout string, t time.Time) string {
+			return lf.displayTime(t).Format(layout)
+		},
+		// field resolves a Cloud Logging field path, e.g. field "httpRequest.status" .
+		"field": func(path string, entry models.LogEntry) string {
+			value, _ := entry.FieldValue(path)
+			return value
+		},
+		"default": func(def string, v interface{}) string {
+			if s := templateString(v); s != "" {
+				return s
+			}
+			return def
+		},
+		// printed is piped after every printed action by AddRowTemplate
+		"printed": func(v interface{}) interface{} {
+			if v == nil {
+				return ""
+			}
+			return v
+		},
+	}
+}
+
+// pipePrinted ends the pipeline of every action that prints under node with
+// the printed helper, so a field missing from a payload map prints as ""
+// rather than text/template's "<no value>"
+func pipePrinted(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			pipePrinted(child)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) == 0 {
+			ident := parse.NewIdentifier("printed").SetPos(n.Pos)
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos, Args: []parse.Node{ident}})
+		}
+	case *parse.IfNode:
+		pipePrinted(n.List)
+		pipePrinted(n.ElseList)
+	case *parse.RangeNode:
+		pipePrinted(n.List)
+		pipePrinted(n.ElseList)
+	case *parse.WithNode:
+		pipePrinted(n.List)
+		pipePrinted(n.ElseList)
+	}
+}
+
+// templateString renders a template value the way the list shows fields
+func templateString(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case time.Time:
+		return value.UTC().Format("2006-01-02 15:04:05")
+	case fmt.Stringer:
+		return value.String()
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
+
+// relativeTime renders a duration as "45s ago", "3m ago", "2h ago" or "5d ago"
+func relativeTime(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "in " + strings.TrimSuffix(relativeTime(-d), " ago")
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
+
+// AddRowTemplate compiles a named row template. Parse errors, including
+// unknown helpers, are returned so they can be reported at startup.
+func (lf *LogFormatter) AddRowTemplate(name, format string) error {
+	tmpl, err := template.New(name).Funcs(lf.rowTemplateFuncs()).Parse(format)
+	if err != nil {
+		return err
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			pipePrinted(t.Tree.Root)
+		}
+	}
+	for i, existing := range lf.rowTemplates {
+		if existing.name == name {
+			lf.rowTemplates[i].tmpl = tmpl
+			return nil
+		}
+	}
+	lf.rowTemplates = append(lf.rowTemplates, &rowTemplate{name: name, tmpl: tmpl})
+	return nil
+}
+
+// UseRowTemplate selects a row template by name; "" selects the default
+// layout. It reports whether the name is known.
+func (lf *LogFormatter) UseRowTemplate(name string) bool {
+	if name == "" {
+		lf.rowTemplate = nil
+		return true
+	}
+	for _, t := range lf.rowTemplates {
+		if t.name == name {
+			lf.rowTemplate = t
+			return true
+		}
+	}
+	return false
+}
+
+// RowTemplateName returns the selected row template, or "" for the default
+// layout
+func (lf *LogFormatter) RowTemplateName() string {
+	if lf.rowTemplate == nil {
+		return ""
+	}
+	return lf.rowTemplate.name
+}
+
+// NextRowTemplate selects the next template in config order, then the
+// default layout again, and returns its name
+func (lf *LogFormatter) NextRowTemplate() string {
+	next := 0
+	for i, t := range lf.rowTemplates {
+		if t == lf.rowTemplate {
+			next = i + 1
+		}
+	}
+	if next >= len(lf.rowTemplates) {
+		lf.rowTemplate = nil
+	} else {
+		lf.rowTemplate = lf.rowTemplates[next]
+	}
+	return lf.RowTemplateName()
+}
+
+// formatRowTemplate renders entry with the selected template on one line of
+// at most width cells
+func (lf *LogFormatter) formatRowTemplate(entry models.LogEntry, width int, color bool) string {
+	lf.env = rowTemplateEnv{color: color, now: time.Now()}
+	var sb strings.Builder
+	if err := lf.rowTemplate.tmpl.Execute(&sb, entry); err != nil {
+		return "template " + lf.rowTemplate.name + ": " + err.Error()
+	}
+	line := strings.NewReplacer("\r\n", " ", "\n", " ", "\t", " ").Replace(sb.String())
+	if lipgloss.Width(line) > width {
+		line = lipgloss.NewStyle().MaxWidth(width).Render(line)
+	}
+	return line
+}
+
+// SetRowTemplates compiles the configured row templates and selects active,
+// falling back to the default layout when it is not defined
+func (a *App) SetRowTemplates(templates []config.RowTemplate, active string) error {
+	for _, t := range templates {
+		if err := a.formatter.AddRowTemplate(t.Name, t.Format); err != nil {
+			return err
+		}
+	}
+	a.formatter.UseRowTemplate(active)
+	return nil
+}
+
+// cycleRowTemplate switches the log list to the next row template
+func (a *App) cycleRowTemplate() {
+	if len(a.formatter.rowTemplates) == 0 {
+		a.lastErr = "No row templates configured (rowTemplates in config.json)"
+		return
+	}
+	if name := a.formatter.NextRowTemplate(); name != "" {
+		a.lastErr = "Row template: " + name
+	} else {
+		a.lastErr = "Row template: default"
+	}
+	a.persistPreferences(func(p *config.Preferences) { p.RowTemplate = a.formatter.RowTemplateName() })
+}
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/log-explorer-tui/pkg/config"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func rowTemplateTestEntry() models.LogEntry {
+	return models.LogEntry{
+		Timestamp:   time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Severity:    "ERROR",
+		Message:     "charge failed",
+		Resource:    models.Resource{Type: "cloud_run_revision", Labels: map[string]string{"service_name": "billing"}},
+		JSONPayload: map[string]interface{}{"method": "POST", "attempt": float64(3)},
+		HTTPRequest: &models.HTTPRequest{Status: 502},
+	}
+}
+
+func TestFormatLogLineWithRowTemplate(t *testing.T) {
+	lf := NewLogFormatter(120, false)
+	format := `{{.Resource.Labels.service_name | pad 10}}|{{.JSONPayload.method}} {{.JSONPayload.missing}}{{field "httpRequest.status" . | pad -5}} {{.JSONPayload.attempt}} {{.Message | trunc 8}} {{.Timestamp | time "15:04"}}`
+	if err := lf.AddRowTemplate("svc", format); err != nil {
+		t.Fatalf("AddRowTemplate failed: %v", err)
+	}
+	if !lf.UseRowTemplate("svc") {
+		t.Fatal("Expected the template to be selectable")
+	}
+	line := lf.FormatLogLine(rowTemplateTestEntry(), 120)
+	if want := "billing   |POST   502 3 charge … 10:00"; line != want {
+		t.Errorf("Unexpected line\n got %q\nwant %q", line, want)
+	}
+	if line := lf.FormatLogLine(rowTemplateTestEntry(), 10); len([]rune(line)) > 10 {
+		t.Errorf("Expected the line to be cut to 10 cells, got %q", line)
+	}
+}
+
+func TestRowTemplateKeepsLiteralNoValue(t *testing.T) {
+	lf := NewLogFormatter(120, false)
+	format := `{{.Message}}|{{.JSONPayload.missing}}|{{if .JSONPayload.method}}{{.JSONPayload.gone}}{{end}}|{{$m := .JSONPayload.method}}{{$m}}`
+	if err := lf.AddRowTemplate("raw", format); err != nil {
+		t.Fatalf("AddRowTemplate failed: %v", err)
+	}
+	lf.UseRowTemplate("raw")
+	entry := rowTemplateTestEntry()
+	entry.Message = "lookup returned <no value>"
+	if line, want := lf.FormatLogLine(entry, 120), "lookup returned <no value>|||POST"; line != want {
+		t.Errorf("Unexpected line\n got %q\nwant %q", line, want)
+	}
+}
+
+func TestRowTemplateErrors(t *testing.T) {
+	lf := NewLogFormatter(120, false)
+	if err := lf.AddRowTemplate("bad", "{{.Message | shout}}"); err == nil {
+		t.Error("Expected an unknown helper to be rejected")
+	}
+	if lf.UseRowTemplate("bad") {
+		t.Error("Expected a rejected template not to be selectable")
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		45 * time.Second:  "45s ago",
+		3 * time.Minute:   "3m ago",
+		5 * time.Hour:     "5h ago",
+		50 * time.Hour:    "2d ago",
+		-10 * time.Second: "in 10s",
+	} {
+		if got := relativeTime(d); got != want {
+			t.Errorf("relativeTime(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestCycleRowTemplates(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.state.LogListState.Logs = []models.LogEntry{rowTemplateTestEntry()}
+	err := app.SetRowTemplates([]config.RowTemplate{
+		{Name: "svc", Format: "{{.Resource.Labels.service_name}} {{.Message}}"},
+		{Name: "http", Format: `{{field "httpRequest.status" .}}`},
+	}, "missing")
+	if err != nil {
+		t.Fatalf("SetRowTemplates failed: %v", err)
+	}
+	if name := app.formatter.RowTemplateName(); name != "" {
+		t.Fatalf("Expected an unknown active template to fall back to the default, got %q", name)
+	}
+
+	var saved config.Preferences
+	app.SetPreferencesPersistFn(func(p config.Preferences) error {
+		saved = p
+		return nil
+	})
+	app = pressKeys(app, runeKey('v'))
+	view := ansiEscapeRegex.ReplaceAllString(app.View(), "")
+	if !strings.Contains(view, "IDX   SVC") || !strings.Contains(view, "billing charge failed") {
+		t.Error("Expected rows rendered with the svc template")
+	}
+	if saved.RowTemplate != "svc" {
+		t.Errorf("Expected the selected template to be saved, got %q", saved.RowTemplate)
+	}
+	app = pressKeys(app, runeKey('v'), runeKey('v'))
+	if name := app.formatter.RowTemplateName(); name != "" {
+		t.Errorf("Expected v to cycle back to the default layout, got %q", name)
+	}
+}
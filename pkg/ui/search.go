@@ -0,0 +1,381 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+// Search match modes, cycled with tab in the search prompt
+const (
+	searchPlain       = "plain"
+	searchInsensitive = "insensitive"
+	searchRegex       = "regex"
+)
+
+var searchModes = []string{searchPlain, searchInsensitive, searchRegex}
+
+// logSearch matches a term against the message, labels and payload of
+// entries. All modes compile to a regexp so matching and highlighting share
+// one code path.
+type logSearch struct {
+	term string
+	mode string
+	re   *regexp.Regexp
+	err  error
+}
+
+func newLogSearch(term, mode string) logSearch {
+	s := logSearch{term: term, mode: mode}
+	if term == "" {
+		return s
+	}
+	switch mode {
+	case searchRegex:
+		s.re, s.err = regexp.Compile(term)
+	case searchInsensitive:
+		s.re = regexp.MustCompile("(?i)" + regexp.QuoteMeta(term))
+	default:
+		s.re = regexp.MustCompile(regexp.QuoteMeta(term))
+	}
+	return s
+}
+
+// active reports whether the search has a valid term
+func (s logSearch) active() bool {
+	return s.re != nil
+}
+
+// Matches reports whether the entry has a hit in any searchable field
+func (s logSearch) Matches(entry models.LogEntry) bool {
+	if !s.active() {
+		return false
+	}
+	for _, text := range query.EntryTexts(entry) {
+		if s.re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// logView is the list shown in the log pane: the loaded logs, narrowed when
+// the search filters the list. It is rebuilt only when the loaded logs or
+// the search change.
+type logView struct {
+	source  *models.LogEntry
+	size    int
+	key     string
+	logs    []models.LogEntry
+	matches []int // indexes into logs
+}
+
+func (a *App) currentSearch() logSearch {
+	fs := a.state.FilterState
+	return newLogSearch(fs.SearchTerm, fs.SearchMatch)
+}
+
+// view returns the cached log view, rebuilding it when stale
+func (a *App) view() *logView {
+	logs := a.state.LogListState.Logs
+	var source *models.LogEntry
+	if len(logs) > 0 {
+		source = &logs[0]
+	}
+	fs := a.state.FilterState
+	ls := a.state.LogListState
+	key := fmt.Sprintf("%s\x00%s\x00%t\x00%s\x00%s\x00%t", fs.SearchTerm, fs.SearchMatch, fs.SearchFilter, a.localFiltersKey(), ls.SortField, ls.SortDesc)
+	if a.viewCache != nil && a.viewCache.source == source && a.viewCache.size == len(logs) && a.viewCache.key == key {
+		return a.viewCache
+	}
+
+	v := &logView{source: source, size: len(logs), key: key, logs: logs}
+	if filters := a.compiledLocalFilters(); len(filters) > 0 {
+		v.logs = make([]models.LogEntry, 0, len(logs))
+		for _, entry := range logs {
+			if matchesAll(filters, entry) {
+				v.logs = append(v.logs, entry)
+			}
+		}
+		logs = v.logs
+	}
+	if ls.SortField != "" {
+		v.logs = sortLogsByField(v.logs, ls.SortField, ls.SortDesc)
+		logs = v.logs
+	}
+	search := a.currentSearch()
+	if search.active() {
+		if fs.SearchFilter {
+			v.logs = make([]models.LogEntry, 0, len(logs))
+			for _, entry := range logs {
+				if search.Matches(entry) {
+					v.matches = append(v.matches, len(v.logs))
+					v.logs = append(v.logs, entry)
+				}
+			}
+		} else {
+			for i, entry := range logs {
+				if search.Matches(entry) {
+					v.matches = append(v.matches, i)
+				}
+			}
+		}
+	}
+	a.viewCache = v
+	return v
+}
+
+// viewLogs returns the entries shown in the log pane. Selection and scroll
+// offsets index into this slice.
+func (a *App) viewLogs() []models.LogEntry {
+	return a.view().logs
+}
+
+// viewFiltered reports whether the shown list is narrower than the loaded one
+func (a *App) viewFiltered() bool {
+	return len(a.viewLogs()) != len(a.state.LogListState.Logs)
+}
+
+// openSearchPrompt opens the incremental search prompt. Esc restores the
+// search that was active before.
+func (a *App) openSearchPrompt() {
+	fs := a.state.FilterState
+	a.searchRestore = &searchSnapshot{term: fs.SearchTerm, match: fs.SearchMatch, filter: fs.SearchFilter, key: a.selectedLogKey()}
+	a.searchOriginKey = a.searchRestore.key
+	if a.state.FilterState.SearchMatch == "" {
+		a.state.FilterState.SearchMatch = searchInsensitive
+	}
+	a.prompt.Open("search", "", fs.SearchTerm)
+	a.updateSearchPromptHint()
+	a.activeModalName = "prompt"
+}
+
+type searchSnapshot struct {
+	term   string
+	match  string
+	filter bool
+	key    string
+}
+
+// handleSearchPromptKey handles the keys the search prompt adds on top of
+// plain text editing. It returns false for keys left to the prompt.
+func (a *App) handleSearchPromptKey(key string) bool {
+	selected := a.selectedLogKey()
+	switch key {
+	case "tab":
+		a.state.FilterState.SearchMatch = nextSearchMode(a.state.FilterState.SearchMatch)
+	case "ctrl+f":
+		a.state.FilterState.SearchFilter = !a.state.FilterState.SearchFilter
+	default:
+		return false
+	}
+	if idx, ok := a.findLogIndexByKey(selected); ok {
+		a.panes.LogList.scrollOffset = idx
+	}
+	a.applySearchInput(a.prompt.Value())
+	return true
+}
+
+func nextSearchMode(mode string) string {
+	for i, m := range searchModes {
+		if m == mode {
+			return searchModes[(i+1)%len(searchModes)]
+		}
+	}
+	return searchModes[0]
+}
+
+// applySearchInput runs the search as the user types: it narrows the list in
+// filter mode and jumps to the first match from where the search started.
+func (a *App) applySearchInput(term string) {
+	key := a.selectedLogKey()
+	a.state.FilterState.SearchTerm = term
+	if a.state.FilterState.SearchFilter {
+		if idx, ok := a.findLogIndexByKey(key); ok {
+			a.panes.LogList.scrollOffset = idx
+		} else {
+			a.panes.LogList.scrollOffset = 0
+		}
+	} else if matches := a.view().matches; len(matches) > 0 {
+		origin, _ := a.findLogIndexByKey(a.searchOriginKey)
+		idx := sort.SearchInts(matches, origin)
+		a.panes.LogList.scrollOffset = matches[idx%len(matches)]
+	}
+	a.updateSearchPromptHint()
+}
+
+func (a *App) updateSearchPromptHint() {
+	fs := a.state.FilterState
+	mode := "jump"
+	if fs.SearchFilter {
+		mode = "filter"
+	}
+	a.prompt.SetTitle(fmt.Sprintf("[%s, %s]", fs.SearchMatch, mode))
+	hint := "Tab match mode | Ctrl+F filter/jump | Enter keep | Esc cancel"
+	if search := a.currentSearch(); search.err != nil {
+		hint = "Invalid regex: " + search.err.Error()
+	} else if search.active() {
+		hint = fmt.Sprintf("%d matches | ", len(a.view().matches)) + hint
+	}
+	a.prompt.SetHint(hint)
+}
+
+func (a *App) searchPromptActive() bool {
+	return a.activeModalName == "prompt" && a.prompt.Purpose() == "search"
+}
+
+// renderSearchBar renders the search prompt as a status panel line
+func (a *App) renderSearchBar() string {
+	input := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorNeutralText)).Render("/" + a.prompt.InputWithCursor())
+	meta := lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle)).Render(a.prompt.Title() + "  " + a.prompt.Hint())
+	return input + "  " + meta
+}
+
+// cancelSearchPrompt restores the search and selection from before the prompt
+func (a *App) cancelSearchPrompt() {
+	if a.searchRestore == nil {
+		return
+	}
+	r := a.searchRestore
+	a.state.FilterState.SearchTerm = r.term
+	a.state.FilterState.SearchMatch = r.match
+	a.state.FilterState.SearchFilter = r.filter
+	if idx, ok := a.findLogIndexByKey(r.key); ok {
+		a.panes.LogList.scrollOffset = idx
+	}
+	a.searchRestore = nil
+}
+
+// submitSearch keeps the typed search
+func (a *App) submitSearch(term string) {
+	a.searchRestore = nil
+	a.applySearchInput(term)
+	if !a.currentSearch().active() {
+		a.clearSearch()
+		return
+	}
+	a.lastErr = fmt.Sprintf("Search %q: %d matches (n/N to move, Esc clears)", term, len(a.view().matches))
+}
+
+// clearSearch drops the search and keeps the selected entry
+func (a *App) clearSearch() {
+	key := a.selectedLogKey()
+	a.state.FilterState.SearchTerm = ""
+	if idx, ok := a.findLogIndexByKey(key); ok {
+		a.panes.LogList.scrollOffset = idx
+	}
+	a.lastErr = "Search cleared"
+}
+
+// jumpToMatch moves the selection to the next (dir 1) or previous (dir -1)
+// match, wrapping around the list.
+func (a *App) jumpToMatch(dir int) {
+	if !a.currentSearch().active() {
+		a.lastErr = "No search (press / to search)"
+		return
+	}
+	matches := a.view().matches
+	if len(matches) == 0 {
+		a.lastErr = fmt.Sprintf("No matches for %q", a.state.FilterState.SearchTerm)
+		return
+	}
+	current := a.currentSelectedIndex()
+	pos := sort.SearchInts(matches, current)
+	if dir > 0 {
+		if pos < len(matches) && matches[pos] == current {
+			pos++
+		}
+	} else {
+		pos--
+	}
+	pos = (pos + len(matches)) % len(matches)
+	a.panes.LogList.scrollOffset = matches[pos]
+	a.lastErr = fmt.Sprintf("Match %d/%d", pos+1, len(matches))
+}
+
+// searchStatus is the match counter shown in the status panel
+func (a *App) searchStatus() string {
+	search := a.currentSearch()
+	if !search.active() {
+		return ""
+	}
+	matches := a.view().matches
+	current := sort.SearchInts(matches, a.currentSelectedIndex())
+	if current < len(matches) && matches[current] == a.currentSelectedIndex() {
+		return fmt.Sprintf("match:%d/%d", current+1, len(matches))
+	}
+	return fmt.Sprintf("match:-/%d", len(matches))
+}
+
+func (a *App) selectedLogKey() string {
+	if entry := a.getSelectedLog(); entry != nil {
+		return logEntryKey(*entry)
+	}
+	return ""
+}
+
+// preserveSelection runs fn, which changes what the view shows, and moves
+// the selection back to the entry that was selected before
+func (a *App) preserveSelection(fn func()) {
+	key := a.selectedLogKey()
+	fn()
+	if idx, ok := a.findLogIndexByKey(key); ok {
+		a.panes.LogList.scrollOffset = idx
+	}
+}
+
+// jumpToEntry closes the active modal and selects entry in the log list
+func (a *App) jumpToEntry(entry models.LogEntry) {
+	idx, ok := a.findLogIndexByKey(logEntryKey(entry))
+	if !ok {
+		a.lastErr = "The entry is not in the log list (not loaded or filtered out)"
+		return
+	}
+	a.activeModalName = "none"
+	a.panes.LogList.scrollOffset = idx
+	a.lastErr = fmt.Sprintf("Jumped to entry %d", idx+1)
+}
+
+// highlightSearch renders text with search hits highlighted on top of base
+func (a *App) highlightSearch(text string, base lipgloss.Style) string {
+	search := a.currentSearch()
+	if !search.active() {
+		return base.Render(text)
+	}
+	return highlightSpans(text, search.re, base, searchHitStyle())
+}
+
+func searchHitStyle() lipgloss.Style {
+	return lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorBadgeTextDark)).Background(lipgloss.Color(colorGCPWarn))
+}
+
+// highlightSpans styles every match of re with hit and the rest with base.
+// Each segment is rendered on its own so the base style survives the resets
+// that end a highlighted segment.
+func highlightSpans(text string, re *regexp.Regexp, base, hit lipgloss.Style) string {
+	spans := re.FindAllStringIndex(text, -1)
+	if len(spans) == 0 {
+		return base.Render(text)
+	}
+	var sb strings.Builder
+	last := 0
+	for _, span := range spans {
+		if span[0] == span[1] {
+			continue
+		}
+		if span[0] > last {
+			sb.WriteString(base.Render(text[last:span[0]]))
+		}
+		sb.WriteString(hit.Render(text[span[0]:span[1]]))
+		last = span[1]
+	}
+	if last < len(text) {
+		sb.WriteString(base.Render(text[last:]))
+	}
+	return sb.String()
+}
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func searchTestLogs() []models.LogEntry {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	return []models.LogEntry{
+		{Timestamp: base, Severity: "INFO", Message: "GET /healthz 200"},
+		{Timestamp: base.Add(time.Minute), Severity: "ERROR", Message: "Timeout calling billing"},
+		{Timestamp: base.Add(2 * time.Minute), Severity: "INFO", Message: "request done", Labels: map[string]string{"pod": "billing-7d9"}},
+		{Timestamp: base.Add(3 * time.Minute), Severity: "WARNING", Message: "retrying", JSONPayload: map[string]interface{}{"upstream": "billing", "attempt": 2}},
+		{Timestamp: base.Add(4 * time.Minute), Severity: "INFO", Message: "GET /healthz 200"},
+	}
+}
+
+func newSearchTestApp() *App {
+	app := NewApp(&models.AppState{
+		IsReady:      true,
+		LogListState: models.LogListState{Logs: searchTestLogs()},
+	})
+	app.width = 120
+	app.height = 40
+	return app
+}
+
+func TestLogSearchModes(t *testing.T) {
+	entry := models.LogEntry{Message: "Timeout calling Billing"}
+	cases := []struct {
+		term, mode string
+		want       bool
+	}{
+		{"billing", searchPlain, false},
+		{"Billing", searchPlain, true},
+		{"billing", searchInsensitive, true},
+		{"time.*bill", searchRegex, false},
+		{"(?i)time.*bill", searchRegex, true},
+		{"a.b", searchPlain, false},
+	}
+	for _, tc := range cases {
+		if got := newLogSearch(tc.term, tc.mode).Matches(entry); got != tc.want {
+			t.Errorf("search %q (%s) = %v, want %v", tc.term, tc.mode, got, tc.want)
+		}
+	}
+
+	invalid := newLogSearch("([", searchRegex)
+	if invalid.err == nil || invalid.active() || invalid.Matches(entry) {
+		t.Error("Expected invalid regex to be reported and match nothing")
+	}
+}
+
+func TestLogSearchLooksAtLabelsAndPayload(t *testing.T) {
+	logs := searchTestLogs()
+	search := newLogSearch("billing", searchInsensitive)
+	for i, want := range []bool{false, true, true, true, false} {
+		if got := search.Matches(logs[i]); got != want {
+			t.Errorf("entry %d: match = %v, want %v", i, got, want)
+		}
+	}
+	if !newLogSearch(`"attempt":2`, searchPlain).Matches(logs[3]) {
+		t.Error("Expected payload JSON to be searchable")
+	}
+}
+
+func typeSearch(app *App, text string) *App {
+	newModel, _ := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	app = newModel.(*App)
+	for _, r := range text {
+		newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		app = newModel.(*App)
+	}
+	return app
+}
+
+func TestSearchJumpsAsYouTypeAndNavigates(t *testing.T) {
+	app := typeSearch(newSearchTestApp(), "bill")
+	if app.activeModalName != "prompt" || app.currentSelectedIndex() != 1 {
+		t.Fatalf("Expected jump to first match while typing, modal=%q selected=%d", app.activeModalName, app.currentSelectedIndex())
+	}
+	if len(app.viewLogs()) != 5 {
+		t.Errorf("Jump mode should keep all entries, got %d", len(app.viewLogs()))
+	}
+	newModel, _ := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	app = newModel.(*App)
+
+	for _, want := range []int{2, 3, 1} {
+		newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+		app = newModel.(*App)
+		if app.currentSelectedIndex() != want {
+			t.Errorf("n: expected %d, got %d", want, app.currentSelectedIndex())
+		}
+	}
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'N'}})
+	app = newModel.(*App)
+	if app.currentSelectedIndex() != 3 {
+		t.Errorf("N: expected wrap to 3, got %d", app.currentSelectedIndex())
+	}
+	if got := app.searchStatus(); got != "match:3/3" {
+		t.Errorf("Unexpected match counter %q", got)
+	}
+	if !strings.Contains(app.View(), "match:3/3") {
+		t.Error("Expected match counter in the status panel")
+	}
+
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	app = newModel.(*App)
+	if app.currentSearch().active() || app.currentSelectedIndex() != 3 {
+		t.Errorf("Esc should clear the search and keep the selection, selected=%d", app.currentSelectedIndex())
+	}
+}
+
+func TestSearchFilterMode(t *testing.T) {
+	app := newSearchTestApp()
+	app.panes.LogList.scrollOffset = 3
+	app = typeSearch(app, "healthz")
+	newModel, _ := app.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	app = newModel.(*App)
+	if !app.state.FilterState.SearchFilter || len(app.viewLogs()) != 2 {
+		t.Fatalf("Expected filter mode with 2 entries, got %d", len(app.viewLogs()))
+	}
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	app = newModel.(*App)
+	if app.state.FilterState.SearchMatch != searchRegex {
+		t.Errorf("Expected tab to cycle to regex, got %q", app.state.FilterState.SearchMatch)
+	}
+	if !strings.Contains(app.View(), "(5 loaded / 2 shown)") {
+		t.Error("Expected filtered count in the list header")
+	}
+
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	app = newModel.(*App)
+	if app.currentSearch().active() || len(app.viewLogs()) != 5 {
+		t.Error("Esc in the prompt should restore the previous search")
+	}
+	if app.currentSelectedIndex() != 3 {
+		t.Errorf("Esc in the prompt should restore the selection, got %d", app.currentSelectedIndex())
+	}
+}
+
+func TestSearchFilterKeepsSelectionWhenPaging(t *testing.T) {
+	app := newSearchTestApp()
+	app.state.FilterState.SearchTerm = "billing"
+	app.state.FilterState.SearchMatch = searchInsensitive
+	app.state.FilterState.SearchFilter = true
+	app.panes.LogList.scrollOffset = 1
+
+	older := models.LogEntry{Timestamp: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), Severity: "ERROR", Message: "billing down"}
+	newModel, _ := app.Update(queryResultMsg{mode: "prepend", logs: []models.LogEntry{older}})
+	app = newModel.(*App)
+	if len(app.viewLogs()) != 4 {
+		t.Fatalf("Expected 4 matching entries, got %d", len(app.viewLogs()))
+	}
+	if entry := app.getSelectedLog(); entry == nil || entry.Message != "request done" {
+		t.Errorf("Expected selection to stay on the same entry, got %+v", entry)
+	}
+}
+
+func TestHighlightSpans(t *testing.T) {
+	base := lipgloss.NewStyle()
+	hit := lipgloss.NewStyle().Bold(true)
+	out := highlightSpans("a billing b Billing", regexp.MustCompile("(?i)billing"), base, hit)
+	if ansiEscapeRegex.ReplaceAllString(out, "") != "a billing b Billing" {
+		t.Errorf("Highlighting changed the text: %q", out)
+	}
+	if got := highlightSpans("nothing", regexp.MustCompile("x"), base, hit); got != "nothing" {
+		t.Errorf("Expected unchanged text without hits, got %q", got)
+	}
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/user/log-explorer-tui/pkg/config"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// SnapshotSession captures the current working context for persistence.
+func (a *App) SnapshotSession() config.Session {
+	tr := a.state.FilterState.TimeRange
+	sf := a.state.FilterState.Severity
+	session := config.Session{
+		Query:          a.state.CurrentQuery.Filter,
+		TimePreset:     tr.Preset,
+		SeverityMode:   sf.Mode,
+		SeverityLevels: append([]string{}, sf.Levels...),
+		SeverityMin:    sf.MinLevel,
+		DetailMode:     a.detailViewMode,
+		DetailOpen:     a.activeModalName == "detailPopup",
+		ScrollOffset:   a.panes.LogList.scrollOffset,
+	}
+	if tr.Preset == "custom" {
+		session.TimeStart = tr.Start
+		session.TimeEnd = tr.End
+	}
+	if entry := a.getSelectedLog(); entry != nil {
+		session.SelectedKey = logEntryKey(*entry)
+	}
+	return session
+}
+
+// RestoreSessionState seeds the query, time range and severity filter of a
+// saved session into state. Relative presets are recomputed from now.
+func RestoreSessionState(session config.Session, state *models.AppState, now time.Time) {
+	state.CurrentQuery.Filter = session.Query
+
+	switch session.TimePreset {
+	case "":
+	case "custom":
+		if !session.TimeStart.IsZero() && !session.TimeEnd.IsZero() {
+			state.FilterState.TimeRange = models.TimeRange{Start: session.TimeStart, End: session.TimeEnd, Preset: "custom"}
+		}
+	default:
+		for _, preset := range NewTimePicker().GetPresets() {
+			if preset.Key == session.TimePreset && preset.Duration > 0 {
+				state.FilterState.TimeRange = models.TimeRange{Start: now.Add(-preset.Duration), End: now, Preset: preset.Key}
+				break
+			}
+		}
+	}
+
+	if session.SeverityMode != "" {
+		state.FilterState.Severity = models.SeverityFilter{
+			Mode:     session.SeverityMode,
+			Levels:   append([]string{}, session.SeverityLevels...),
+			MinLevel: session.SeverityMin,
+		}
+	}
+}
+
+// RestoreSessionView restores the selection, scroll offset and detail popup
+// of a saved session once the first query result arrives. Display settings
+// such as the timezone and log order come from the config layers instead.
+func (a *App) RestoreSessionView(session config.Session) {
+	a.pendingSelectKey = session.SelectedKey
+	a.pendingScroll = session.ScrollOffset
+	a.pendingDetailMode = session.DetailMode
+	a.pendingDetailOpen = session.DetailOpen
+}
+
+// applyPendingSelection moves to the entry selected when the session was
+// saved, falling back to the saved scroll offset if it is no longer loaded.
+func (a *App) applyPendingSelection() {
+	if a.pendingSelectKey == "" && a.pendingScroll < 0 {
+		return
+	}
+	logs := a.viewLogs()
+	if idx, ok := a.findLogIndexByKey(a.pendingSelectKey); ok && a.pendingSelectKey != "" {
+		a.panes.LogList.scrollOffset = idx
+	} else if a.pendingScroll >= 0 && len(logs) > 0 {
+		a.panes.LogList.scrollOffset = minInt(a.pendingScroll, len(logs)-1)
+	}
+	if a.pendingDetailOpen && len(logs) > 0 {
+		a.activeModalName = "detailPopup"
+		a.resetDetailPopupState()
+		if entry := a.getSelectedLog(); entry != nil {
+			for _, mode := range a.availableDetailModes(*entry) {
+				if mode == a.pendingDetailMode {
+					a.detailViewMode = mode
+					break
+				}
+			}
+		}
+	}
+	a.pendingSelectKey = ""
+	a.pendingScroll = -1
+	a.pendingDetailMode = ""
+	a.pendingDetailOpen = false
+}
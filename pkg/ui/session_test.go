@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/config"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func sessionTestLogs() []models.LogEntry {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	logs := make([]models.LogEntry, 0, 5)
+	for i := 0; i < 5; i++ {
+		logs = append(logs, models.LogEntry{
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+			Severity:  "INFO",
+			Message:   "entry",
+		})
+	}
+	return logs
+}
+
+func TestSnapshotSession(t *testing.T) {
+	state := &models.AppState{
+		IsReady:      true,
+		CurrentQuery: models.Query{Filter: "severity>=ERROR"},
+		FilterState: models.FilterState{
+			TimeRange: models.TimeRange{Start: time.Now().Add(-time.Hour), End: time.Now(), Preset: "1h"},
+			Severity:  models.SeverityFilter{Mode: "range", MinLevel: "ERROR"},
+		},
+		LogListState: models.LogListState{Logs: sessionTestLogs()},
+	}
+	app := NewApp(state)
+	app.panes.LogList.scrollOffset = 2
+
+	session := app.SnapshotSession()
+	if session.Query != "severity>=ERROR" || session.TimePreset != "1h" {
+		t.Fatalf("unexpected query/range in session %+v", session)
+	}
+	if !session.TimeStart.IsZero() {
+		t.Fatalf("relative presets should not store absolute bounds, got %v", session.TimeStart)
+	}
+	if session.SeverityMode != "range" || session.SeverityMin != "ERROR" {
+		t.Fatalf("unexpected severity in session %+v", session)
+	}
+	if session.SelectedKey != logEntryKey(state.LogListState.Logs[2]) || session.ScrollOffset != 2 {
+		t.Fatalf("unexpected selection in session %+v", session)
+	}
+}
+
+func TestRestoreSessionStateRecomputesPresets(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	state := &models.AppState{}
+	RestoreSessionState(config.Session{Query: "q", TimePreset: "7d", SeverityMode: "individual", SeverityLevels: []string{"ERROR"}}, state, now)
+
+	tr := state.FilterState.TimeRange
+	if tr.Preset != "7d" || !tr.End.Equal(now) || !tr.Start.Equal(now.Add(-7*24*time.Hour)) {
+		t.Fatalf("expected 7d range ending now, got %+v", tr)
+	}
+	if state.CurrentQuery.Filter != "q" || len(state.FilterState.Severity.Levels) != 1 {
+		t.Fatalf("unexpected restored state %+v", state)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	RestoreSessionState(config.Session{TimePreset: "custom", TimeStart: start, TimeEnd: start.Add(time.Hour)}, state, now)
+	if !state.FilterState.TimeRange.Start.Equal(start) || state.FilterState.TimeRange.Preset != "custom" {
+		t.Fatalf("expected custom range to be kept, got %+v", state.FilterState.TimeRange)
+	}
+}
+
+func TestRestoreSessionViewReselectsEntry(t *testing.T) {
+	logs := sessionTestLogs()
+	state := &models.AppState{IsReady: true}
+	app := NewApp(state)
+	app.SetLogOrder("latest_top")
+	app.RestoreSessionView(config.Session{
+		SelectedKey: logEntryKey(logs[1]),
+		DetailOpen:  true,
+		DetailMode:  "full",
+	})
+
+	newModel, _ := app.Update(queryResultMsg{filter: "", logs: logs, mode: "replace"})
+	app = newModel.(*App)
+	selected := app.getSelectedLog()
+	if selected == nil || logEntryKey(*selected) != logEntryKey(logs[1]) {
+		t.Fatalf("expected saved entry to be selected, got %+v", selected)
+	}
+	if app.activeModalName != "detailPopup" || app.detailViewMode != "full" {
+		t.Fatalf("expected detail popup in full mode, got %s/%s", app.activeModalName, app.detailViewMode)
+	}
+
+	// The pending selection only applies to the first result.
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	app = newModel.(*App)
+	newModel, _ = app.Update(queryResultMsg{filter: "", logs: logs, mode: "replace"})
+	app = newModel.(*App)
+	if app.panes.LogList.scrollOffset != 0 {
+		t.Fatalf("expected default selection on later results, got %d", app.panes.LogList.scrollOffset)
+	}
+}
+
+func TestRestoreSessionViewWithoutSelection(t *testing.T) {
+	logs := sessionTestLogs()
+	state := &models.AppState{IsReady: true}
+	app := NewApp(state)
+	app.SetTimezoneMode("local")
+	app.SetLogOrder("latest_top")
+	app.RestoreSessionView(config.Session{ScrollOffset: 2, DetailOpen: true, DetailMode: "full"})
+	if app.timezoneMode != "local" || app.logOrder != "latest_top" {
+		t.Errorf("expected the configured display settings to be kept, got %s/%s", app.timezoneMode, app.logOrder)
+	}
+
+	newModel, _ := app.Update(queryResultMsg{filter: "", logs: logs, mode: "replace"})
+	app = newModel.(*App)
+	if app.panes.LogList.scrollOffset != 2 {
+		t.Errorf("expected the saved scroll offset, got %d", app.panes.LogList.scrollOffset)
+	}
+	if app.activeModalName != "detailPopup" || app.detailViewMode != "full" {
+		t.Errorf("expected detail popup in full mode, got %s/%s", app.activeModalName, app.detailViewMode)
+	}
+}
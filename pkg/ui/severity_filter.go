@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/user/log-explorer-tui/pkg/models"
 )
@@ -211,3 +212,35 @@ func (sfp *SeverityFilterPanel) ApplyPreset(preset SeverityPreset) error {
 
 	return nil
 }
+
+// ParseSeverityExpr parses a severity expression such as ">=ERROR", "ERROR" or
+// "WARNING,ERROR" into a filter. A ">=" prefix selects range mode.
+func ParseSeverityExpr(expr string) (models.SeverityFilter, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return models.SeverityFilter{}, fmt.Errorf("empty severity expression")
+	}
+	panel := NewSeverityFilterPanel()
+	if strings.HasPrefix(expr, ">=") {
+		level := strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(expr, ">=")))
+		if !panel.isValidLevel(level) {
+			return models.SeverityFilter{}, fmt.Errorf("invalid severity level: %s", level)
+		}
+		return models.SeverityFilter{Mode: "range", MinLevel: level}, nil
+	}
+	levels := []string{}
+	for _, part := range strings.Split(expr, ",") {
+		level := strings.ToUpper(strings.TrimSpace(part))
+		if level == "" {
+			continue
+		}
+		if !panel.isValidLevel(level) {
+			return models.SeverityFilter{}, fmt.Errorf("invalid severity level: %s", level)
+		}
+		levels = append(levels, level)
+	}
+	if len(levels) == 0 {
+		return models.SeverityFilter{}, fmt.Errorf("empty severity expression")
+	}
+	return models.SeverityFilter{Mode: "individual", Levels: levels}, nil
+}
@@ -363,3 +363,27 @@ func TestGetSeverityLevels(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSeverityExpr(t *testing.T) {
+	filter, err := ParseSeverityExpr(">=error")
+	if err != nil {
+		t.Fatalf("ParseSeverityExpr failed: %v", err)
+	}
+	if filter.Mode != "range" || filter.MinLevel != "ERROR" {
+		t.Errorf("Expected range >= ERROR, got %+v", filter)
+	}
+
+	filter, err = ParseSeverityExpr("WARNING, ERROR")
+	if err != nil {
+		t.Fatalf("ParseSeverityExpr failed: %v", err)
+	}
+	if filter.Mode != "individual" || len(filter.Levels) != 2 || filter.Levels[1] != "ERROR" {
+		t.Errorf("Expected individual levels, got %+v", filter)
+	}
+
+	for _, expr := range []string{"", ">=LOUD", "INFO,NOPE"} {
+		if _, err := ParseSeverityExpr(expr); err == nil {
+			t.Errorf("Expected error for %q", expr)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"github.com/user/log-explorer-tui/pkg/models"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+// SetSeverityInference tells the app that DEFAULT entries get a severity
+// inferred from their payload. The severity filter then keeps DEFAULT
+// entries in the server query and applies the levels to the loaded logs.
+func (a *App) SetSeverityInference(enabled bool) {
+	a.inferSeverity = enabled
+}
+
+// addSeverityFilter adds the severity filter to a server query
+func (a *App) addSeverityFilter(builder *query.Builder) {
+	sf := a.state.FilterState.Severity
+	if !(sf.Mode == "range" && sf.MinLevel != "") && !(sf.Mode == "individual" && len(sf.Levels) > 0) {
+		return
+	}
+	if a.inferSeverity {
+		builder.AddSeverityOrDefault(sf)
+	} else {
+		builder.AddSeverity(sf)
+	}
+}
+
+// localSeverityClause is the severity filter applied to the loaded logs
+// when severities are inferred, since the server only sees DEFAULT
+func (a *App) localSeverityClause() string {
+	if !a.inferSeverity {
+		return ""
+	}
+	return query.NewBuilder("").AddSeverity(a.state.FilterState.Severity).Build()
+}
+
+// entrySeverityBadge is the severity badge of a row. Inferred severities
+// end in "~" instead of the right padding, so the badge keeps its width.
+func (a *App) entrySeverityBadge(entry models.LogEntry) string {
+	if entry.SeverityField == "" {
+		return a.styleSeverityBadge(entry.Severity)
+	}
+	style, text := severityBadge(entry.Severity)
+	return style.Padding(0, 0, 0, 1).Render(text + "~")
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func TestInferredSeverityFilterAppliesLocally(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.SetSeverityInference(true)
+	app.state.FilterState.Severity = models.SeverityFilter{Mode: "range", MinLevel: models.SeverityError}
+	app.state.LogListState.Logs = []models.LogEntry{
+		{ID: "1", Severity: "ERROR", Message: "logged"},
+		{ID: "2", Severity: "ERROR", SeverityField: "jsonPayload.level", Message: "inferred error", JSONPayload: map[string]interface{}{"level": "error"}},
+		{ID: "3", Severity: "INFO", SeverityField: "jsonPayload.level", Message: "inferred info", JSONPayload: map[string]interface{}{"level": "info"}},
+	}
+
+	if filter := app.buildEffectiveFilter(""); !strings.Contains(filter, "(severity>=ERROR OR severity=DEFAULT)") {
+		t.Errorf("Expected the server query to keep DEFAULT entries, got %s", filter)
+	}
+	if shown := app.viewLogs(); len(shown) != 2 || shown[1].ID != "2" {
+		t.Fatalf("Expected the inferred INFO entry to be filtered out locally, got %d entries", len(shown))
+	}
+	view := ansiEscapeRegex.ReplaceAllString(app.View(), "")
+	if !strings.Contains(view, " ERR~  ") || !strings.Contains(view, "(3 loaded / 2 shown)") {
+		t.Error("Expected the inferred badge and the shown count")
+	}
+
+	clause, err := treeFilterClause(app.state.LogListState.Logs[1], jsonTreeLine{segments: []string{"severity"}, value: "ERROR"}, treeFilterInclude)
+	if err != nil || clause != `jsonPayload.level="error"` {
+		t.Errorf("Expected an inferred severity to filter on the payload field, got %q (%v)", clause, err)
+	}
+}
+
+func TestSeverityFilterWithoutInference(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.state.FilterState.Severity = models.SeverityFilter{Mode: "range", MinLevel: models.SeverityError}
+	if filter := app.buildEffectiveFilter(""); strings.Contains(filter, "DEFAULT") || !strings.Contains(filter, "severity>=ERROR") {
+		t.Errorf("Expected a plain severity clause, got %s", filter)
+	}
+	if app.localSeverityClause() != "" {
+		t.Error("Expected no local severity filter without inference")
+	}
+}
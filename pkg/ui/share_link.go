@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -173,3 +174,135 @@ func (slg *ShareLinkGenerator) GetQueryURL(projectID, filter string) string {
 	encoded := url.QueryEscape(filter)
 	return fmt.Sprintf("https://console.cloud.google.com/logs/query?project=%s&query=%s", projectID, encoded)
 }
+
+// ParseConsoleURL parses a Cloud Console Logs Explorer URL such as
+// https://console.cloud.google.com/logs/query;query=...;timeRange=PT1H?project=p
+// The path parameters query, timeRange, duration, startTime and endTime are
+// recognised, as is the legacy ?query= form produced by GetQueryURL.
+func (slg *ShareLinkGenerator) ParseConsoleURL(link string) (models.Query, models.FilterState, error) {
+	parsedURL, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return models.Query{}, models.FilterState{}, fmt.Errorf("invalid URL: %w", err)
+	}
+	if !strings.Contains(parsedURL.EscapedPath(), "/logs") {
+		return models.Query{}, models.FilterState{}, fmt.Errorf("not a Logs Explorer URL: %s", link)
+	}
+
+	query := models.Query{
+		Project: parsedURL.Query().Get("project"),
+		Filter:  parsedURL.Query().Get("query"),
+	}
+	filterState := models.FilterState{
+		CustomFilters: make(map[string]string),
+	}
+
+	segments := strings.Split(parsedURL.EscapedPath(), ";")
+	params := map[string]string{}
+	for _, segment := range segments[1:] {
+		key, value, ok := strings.Cut(segment, "=")
+		if !ok {
+			continue
+		}
+		decoded, err := url.PathUnescape(value)
+		if err != nil {
+			return models.Query{}, models.FilterState{}, fmt.Errorf("invalid %s parameter: %w", key, err)
+		}
+		params[key] = decoded
+	}
+
+	if q, ok := params["query"]; ok {
+		query.Filter = q
+	}
+
+	now := time.Now().UTC()
+	rangeValue := params["timeRange"]
+	if rangeValue == "" {
+		rangeValue = params["duration"]
+	}
+	if rangeValue != "" {
+		if start, end, ok := strings.Cut(rangeValue, "/"); ok {
+			startTime, err := time.Parse(time.RFC3339, start)
+			if err != nil {
+				return models.Query{}, models.FilterState{}, fmt.Errorf("invalid timeRange start: %w", err)
+			}
+			endTime, err := time.Parse(time.RFC3339, end)
+			if err != nil {
+				return models.Query{}, models.FilterState{}, fmt.Errorf("invalid timeRange end: %w", err)
+			}
+			filterState.TimeRange = models.TimeRange{Start: startTime.UTC(), End: endTime.UTC(), Preset: "custom"}
+		} else {
+			d, err := parseISODuration(rangeValue)
+			if err != nil {
+				return models.Query{}, models.FilterState{}, err
+			}
+			tp := NewTimePicker()
+			filterState.TimeRange = models.TimeRange{Start: now.Add(-d), End: now, Preset: tp.PresetKeyForDuration(d)}
+		}
+	}
+	if st := params["startTime"]; st != "" {
+		if t, err := time.Parse(time.RFC3339, st); err == nil {
+			filterState.TimeRange.Start = t.UTC()
+			filterState.TimeRange.Preset = "custom"
+		}
+	}
+	if et := params["endTime"]; et != "" {
+		if t, err := time.Parse(time.RFC3339, et); err == nil {
+			filterState.TimeRange.End = t.UTC()
+			filterState.TimeRange.Preset = "custom"
+		}
+	}
+	if !filterState.TimeRange.Start.IsZero() && filterState.TimeRange.End.IsZero() {
+		filterState.TimeRange.End = now
+	}
+
+	return query, filterState, nil
+}
+
+// parseISODuration parses the ISO 8601 durations used by the console, e.g. PT1H or P7D.
+func parseISODuration(s string) (time.Duration, error) {
+	rest := strings.ToUpper(strings.TrimSpace(s))
+	if !strings.HasPrefix(rest, "P") || len(rest) < 3 {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	rest = rest[1:]
+	var total time.Duration
+	inTime := false
+	number := ""
+	for _, r := range rest {
+		switch {
+		case r == 'T':
+			inTime = true
+		case (r >= '0' && r <= '9') || r == '.':
+			number += string(r)
+		default:
+			if number == "" {
+				return 0, fmt.Errorf("invalid duration: %s", s)
+			}
+			n, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration: %s", s)
+			}
+			var unit time.Duration
+			switch {
+			case r == 'W' && !inTime:
+				unit = 7 * 24 * time.Hour
+			case r == 'D' && !inTime:
+				unit = 24 * time.Hour
+			case r == 'H' && inTime:
+				unit = time.Hour
+			case r == 'M' && inTime:
+				unit = time.Minute
+			case r == 'S' && inTime:
+				unit = time.Second
+			default:
+				return 0, fmt.Errorf("unsupported duration unit in %s", s)
+			}
+			total += time.Duration(n * float64(unit))
+			number = ""
+		}
+	}
+	if number != "" || total <= 0 {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	return total, nil
+}
@@ -225,3 +225,70 @@ func TestGenerateLinkWithSeverity(t *testing.T) {
 		t.Error("Link should contain severity mode")
 	}
 }
+
+func TestParseConsoleURL(t *testing.T) {
+	slg := NewShareLinkGenerator("")
+
+	link := "https://console.cloud.google.com/logs/query;query=severity%3DERROR%0Aresource.type%3D%22k8s_container%22;timeRange=PT1H?project=my-proj"
+	query, filterState, err := slg.ParseConsoleURL(link)
+	if err != nil {
+		t.Fatalf("ParseConsoleURL failed: %v", err)
+	}
+	if query.Project != "my-proj" {
+		t.Errorf("Expected project my-proj, got %q", query.Project)
+	}
+	if query.Filter != "severity=ERROR\nresource.type=\"k8s_container\"" {
+		t.Errorf("Unexpected filter %q", query.Filter)
+	}
+	if filterState.TimeRange.Preset != "1h" {
+		t.Errorf("Expected 1h preset, got %q", filterState.TimeRange.Preset)
+	}
+	if got := filterState.TimeRange.End.Sub(filterState.TimeRange.Start); got != time.Hour {
+		t.Errorf("Expected 1h range, got %v", got)
+	}
+}
+
+func TestParseConsoleURLAbsoluteRange(t *testing.T) {
+	slg := NewShareLinkGenerator("")
+
+	link := "https://console.cloud.google.com/logs/query;timeRange=2024-01-01T00:00:00Z%2F2024-01-02T00:00:00Z?project=p"
+	_, filterState, err := slg.ParseConsoleURL(link)
+	if err != nil {
+		t.Fatalf("ParseConsoleURL failed: %v", err)
+	}
+	if filterState.TimeRange.Preset != "custom" {
+		t.Errorf("Expected custom preset, got %q", filterState.TimeRange.Preset)
+	}
+	if !filterState.TimeRange.Start.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected start %v", filterState.TimeRange.Start)
+	}
+	if !filterState.TimeRange.End.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected end %v", filterState.TimeRange.End)
+	}
+}
+
+func TestParseConsoleURLRoundTripsGetQueryURL(t *testing.T) {
+	slg := NewShareLinkGenerator("")
+
+	query, _, err := slg.ParseConsoleURL(slg.GetQueryURL("proj", "severity>=WARNING"))
+	if err != nil {
+		t.Fatalf("ParseConsoleURL failed: %v", err)
+	}
+	if query.Project != "proj" || query.Filter != "severity>=WARNING" {
+		t.Errorf("Unexpected query %+v", query)
+	}
+}
+
+func TestParseConsoleURLInvalid(t *testing.T) {
+	slg := NewShareLinkGenerator("")
+
+	cases := []string{
+		"https://example.com/other",
+		"https://console.cloud.google.com/logs/query;timeRange=P1X?project=p",
+	}
+	for _, link := range cases {
+		if _, _, err := slg.ParseConsoleURL(link); err == nil {
+			t.Errorf("Expected error for %q", link)
+		}
+	}
+}
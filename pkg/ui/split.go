@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// Split layouts show the active tab next to a partner tab. The selection in
+// the partner follows the active tab by timestamp.
+const (
+	splitOff     = ""
+	splitStacked = "stacked"
+	splitSide    = "side"
+)
+
+// cycleSplitMode steps off -> stacked -> side by side -> off. Turning the
+// split on with a single tab opens a second tab to pair with.
+func (a *App) cycleSplitMode() tea.Cmd {
+	switch a.splitMode {
+	case splitOff:
+		a.splitMode = splitStacked
+	case splitStacked:
+		a.splitMode = splitSide
+	default:
+		a.splitMode = splitOff
+		a.lastErr = "Split view: OFF"
+		return nil
+	}
+
+	var cmd tea.Cmd
+	if len(a.tabs) < 2 {
+		partnerID := a.activeTabID()
+		cmd = a.openNewTab()
+		a.splitPartnerID = partnerID
+	}
+	idx := a.splitPartnerIndex()
+	if idx < 0 {
+		a.splitMode = splitOff
+		return cmd
+	}
+	a.syncSplitPartner()
+	a.lastErr = fmt.Sprintf("Split view: %s with %s (w switches pane)", a.splitMode, a.tabs[idx].name)
+	return cmd
+}
+
+// splitPartnerIndex returns the tab shown next to the active one, picking the
+// nearest other tab when the partner was closed or became active. It returns
+// -1 when the split is off or there is no other tab.
+func (a *App) splitPartnerIndex() int {
+	if a.splitMode == splitOff || len(a.tabs) < 2 {
+		return -1
+	}
+	if idx := a.tabIndexByID(a.splitPartnerID); idx >= 0 && idx != a.activeTab {
+		return idx
+	}
+	idx := a.activeTab + 1
+	if idx >= len(a.tabs) {
+		idx = a.activeTab - 1
+	}
+	a.splitPartnerID = a.tabs[idx].id
+	return idx
+}
+
+// swapSplitFocus moves the focus to the partner pane.
+func (a *App) swapSplitFocus() {
+	idx := a.splitPartnerIndex()
+	if idx < 0 {
+		a.lastErr = "Split view is off (\\ turns it on)"
+		return
+	}
+	previous := a.activeTabID()
+	a.switchTab(idx)
+	a.splitPartnerID = previous
+}
+
+// syncSplitPartner moves the partner selection to the entry nearest in time
+// to the active selection.
+func (a *App) syncSplitPartner() {
+	idx := a.splitPartnerIndex()
+	selected := a.getSelectedLog()
+	if idx < 0 || selected == nil {
+		return
+	}
+	ts := selected.Timestamp
+	a.withWorkspace(idx, func() {
+		if logs := a.viewLogs(); len(logs) > 0 {
+			a.panes.LogList.scrollOffset = nearestLogIndex(logs, ts)
+		}
+	})
+}
+
+// nearestLogIndex returns the index of the entry closest to ts. Ties go to
+// the earlier index.
+func nearestLogIndex(logs []models.LogEntry, ts time.Time) int {
+	best := 0
+	var bestDiff time.Duration = -1
+	for i, entry := range logs {
+		diff := entry.Timestamp.Sub(ts)
+		if diff < 0 {
+			diff = -diff
+		}
+		if bestDiff < 0 || diff < bestDiff {
+			best = i
+			bestDiff = diff
+		}
+	}
+	return best
+}
+
+// splitPaneHeight is the renderLogsPanel height of each stacked pane, so the
+// two panes together take the lines of a single logs panel.
+func splitPaneHeight(logsHeight int) int {
+	return maxInt(2, (logsHeight-2)/2)
+}
+
+// renderSplitLogs renders the active tab and its partner in tab order. The
+// window returned is the one of the active pane.
+func (a *App) renderSplitLogs(logsHeight int) (string, int, int) {
+	partner := a.splitPartnerIndex()
+	first, second := a.activeTab, partner
+	if partner < a.activeTab {
+		first, second = partner, a.activeTab
+	}
+
+	height := logsHeight
+	width := a.width
+	widths := []int{a.width, a.width}
+	if a.splitMode == splitStacked {
+		height = splitPaneHeight(logsHeight)
+	} else {
+		widths = []int{a.width / 2, a.width - a.width/2}
+	}
+
+	var panes []string
+	var windowStart, windowEnd int
+	for i, idx := range []int{first, second} {
+		a.width = widths[i]
+		label := a.splitPaneTitle(idx)
+		if idx == a.activeTab {
+			var pane string
+			pane, windowStart, windowEnd = a.renderLogsPanelTitled(height, label)
+			panes = append(panes, pane)
+			continue
+		}
+		a.withWorkspace(idx, func() {
+			pane, _, _ := a.renderLogsPanelTitled(height, label)
+			panes = append(panes, pane)
+		})
+	}
+	a.width = width
+
+	if a.splitMode == splitStacked {
+		return renderVerticalSplit(panes, []int{height + 2, height + 2}) + "\n", windowStart, windowEnd
+	}
+	return renderHorizontalSplit(panes, widths) + "\n", windowStart, windowEnd
+}
+
+func (a *App) splitPaneTitle(idx int) string {
+	name := a.tabs[idx].name
+	if idx == a.activeTab {
+		return lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorGCPBlueLight)).Render("▶ LOG STREAM · " + name)
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle)).Render("  LOG STREAM · " + name)
+}
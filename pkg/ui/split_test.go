@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func TestNearestLogIndex(t *testing.T) {
+	logs := sessionTestLogs()
+	base := logs[0].Timestamp
+	cases := []struct {
+		at   time.Time
+		want int
+	}{
+		{base.Add(-time.Hour), 0},
+		{base.Add(2*time.Minute + 10*time.Second), 2},
+		{base.Add(2*time.Minute + 50*time.Second), 3},
+		{base.Add(time.Hour), 4},
+	}
+	for _, tc := range cases {
+		if got := nearestLogIndex(logs, tc.at); got != tc.want {
+			t.Errorf("nearestLogIndex(%s) = %d, want %d", tc.at.Format(time.TimeOnly), got, tc.want)
+		}
+	}
+}
+
+func newSplitTestApp(t *testing.T) *App {
+	t.Helper()
+	app := newTabsTestApp(t)
+	app.vimMode = true
+	app.openNewTab()
+	base := app.tabs[0].state.LogListState.Logs[0].Timestamp
+	server := make([]models.LogEntry, 0, 10)
+	for i := 0; i < 10; i++ {
+		server = append(server, models.LogEntry{
+			Timestamp: base.Add(time.Duration(i*30) * time.Second),
+			Severity:  "INFO",
+			Message:   "server",
+		})
+	}
+	app.state.LogListState.Logs = server
+	app.switchTab(0)
+	return app
+}
+
+func TestSplitViewSyncsSelectionByTime(t *testing.T) {
+	app := newSplitTestApp(t)
+	newModel, _ := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'\\'}})
+	app = newModel.(*App)
+	if app.splitMode != splitStacked || app.splitPartnerIndex() != 1 {
+		t.Fatalf("Expected stacked split with tab 2, got %q partner=%d", app.splitMode, app.splitPartnerIndex())
+	}
+
+	for i := 0; i < 2; i++ {
+		newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+		app = newModel.(*App)
+	}
+	// Client entry at +2m; the server entry at +2m is index 4.
+	if got := app.tabs[1].scrollOffset; got != 4 {
+		t.Errorf("Expected partner selection at 4, got %d", got)
+	}
+
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	app = newModel.(*App)
+	if app.activeTab != 1 || app.splitPartnerIndex() != 0 {
+		t.Fatalf("Expected focus on tab 2, active=%d partner=%d", app.activeTab, app.splitPartnerIndex())
+	}
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	app = newModel.(*App)
+	// Server entry at +2m30s; the client entry at +2m is the nearest.
+	if got := app.tabs[0].scrollOffset; got != 2 {
+		t.Errorf("Expected client selection at 2, got %d", got)
+	}
+}
+
+func TestSplitViewCycleOpensPartnerTab(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.cycleSplitMode()
+	if len(app.tabs) != 2 || app.splitPartnerIndex() != 0 {
+		t.Fatalf("Expected a second tab paired with the first, tabs=%d partner=%d", len(app.tabs), app.splitPartnerIndex())
+	}
+	app.cycleSplitMode()
+	if app.splitMode != splitSide {
+		t.Errorf("Expected side by side, got %q", app.splitMode)
+	}
+	app.cycleSplitMode()
+	if app.splitMode != splitOff || app.splitPartnerIndex() != -1 {
+		t.Errorf("Expected split off, got %q", app.splitMode)
+	}
+}
+
+func TestSplitViewRender(t *testing.T) {
+	for _, mode := range []string{splitStacked, splitSide} {
+		app := newSplitTestApp(t)
+		app.splitMode = mode
+		view := app.View()
+		lines := strings.Split(strings.TrimSuffix(view, "\n"), "\n")
+		if len(lines) > app.height-1 {
+			t.Errorf("%s: view has %d lines, height %d", mode, len(lines), app.height)
+		}
+		for i, line := range lines {
+			if w := lipgloss.Width(line); w != app.width {
+				t.Errorf("%s: line %d has width %d, want %d", mode, i, w, app.width)
+				break
+			}
+		}
+		if !strings.Contains(view, "▶ LOG STREAM · tab1") || !strings.Contains(view, "LOG STREAM · tab2") {
+			t.Errorf("%s: expected both pane titles", mode)
+		}
+		if mode == splitSide && !strings.Contains(view, "┓┏") {
+			t.Errorf("Expected panes next to each other")
+		}
+	}
+}
+
+func TestRenderSplitHelpersUseDisplayWidth(t *testing.T) {
+	styled := lipgloss.NewStyle().Bold(true).Render("abc")
+	out := renderHorizontalSplit([]string{styled + "\nx\n", "right"}, []int{5, 5})
+	lines := strings.Split(out, "\n")
+	if len(lines) != 2 || lipgloss.Width(lines[0]) != 10 || lipgloss.Width(lines[1]) != 10 {
+		t.Errorf("Unexpected horizontal split %q", out)
+	}
+
+	out = renderVerticalSplit([]string{"a\nb\nc\n", "d\n"}, []int{2, 2})
+	if out != "a\nb\nd\n" {
+		t.Errorf("Unexpected vertical split %q", out)
+	}
+}
@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+const defaultStreamInterval = 2 * time.Second
+
+// streamTickMsg asks the tab with tabID to fetch entries newer than it has.
+type streamTickMsg struct {
+	tabID int
+}
+
+func (a *App) streamInterval() time.Duration {
+	if a.state.StreamState.RefreshInterval > 0 {
+		return a.state.StreamState.RefreshInterval
+	}
+	return defaultStreamInterval
+}
+
+// startStreamCmd schedules the first tick for a tab unless one is pending.
+func (a *App) startStreamCmd(tabID int) tea.Cmd {
+	if a.queryExec == nil || a.streamTicking[tabID] {
+		return nil
+	}
+	a.streamTicking[tabID] = true
+	return a.nextStreamTickCmd(tabID, a.streamInterval())
+}
+
+func (a *App) nextStreamTickCmd(tabID int, interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return streamTickMsg{tabID: tabID}
+	})
+}
+
+// handleStreamTick fetches newer entries for the ticking tab, whether or not
+// it is active, and schedules the next tick while streaming stays enabled.
+func (a *App) handleStreamTick(msg streamTickMsg) tea.Cmd {
+	idx := a.tabIndexByID(msg.tabID)
+	if idx < 0 {
+		delete(a.streamTicking, msg.tabID)
+		return nil
+	}
+	var fetch tea.Cmd
+	enabled := false
+	interval := defaultStreamInterval
+	a.withWorkspace(idx, func() {
+		enabled = a.state.StreamState.Enabled
+		interval = a.streamInterval()
+		if enabled {
+			fetch = a.streamFetchCmd(msg.tabID)
+		}
+	})
+	if !enabled {
+		delete(a.streamTicking, msg.tabID)
+		return nil
+	}
+	return tea.Batch(fetch, a.nextStreamTickCmd(msg.tabID, interval))
+}
+
+// streamFetchCmd queries entries newer than the newest loaded one. Ticks are
+// skipped while a previous fetch or a full query is still running.
+func (a *App) streamFetchCmd(tabID int) tea.Cmd {
+	if a.streamPending || a.state.LogListState.IsLoading {
+		return nil
+	}
+	a.streamPending = true
+	filter := a.buildStreamFilter()
+	project := a.state.CurrentProject
+	return func() tea.Msg {
+		logs, err := a.execQuery(project, filter)
+		return queryResultMsg{filter: filter, logs: logs, err: err, mode: "stream", tabID: tabID, stream: true}
+	}
+}
+
+// buildStreamFilter is the effective filter without the time range end, so
+// entries arriving after the range was chosen are still picked up.
+func (a *App) buildStreamFilter() string {
+	builder := query.NewBuilder("")
+	if base := sanitizeFilterForExecution(a.state.CurrentQuery.Filter); base != "" {
+		builder.AddCustomFilter(base)
+	}
+	for _, clause := range query.CustomFilterClauses(a.state.FilterState.CustomFilters) {
+		builder.AddCustomFilter(clause)
+	}
+	a.addSeverityFilter(builder)
+	since := a.newestLoadedTimestamp()
+	if since.IsZero() {
+		since = a.state.FilterState.TimeRange.Start
+	}
+	if !since.IsZero() {
+		builder.AddCustomFilter(fmt.Sprintf("timestamp>%q", since.UTC().Format(time.RFC3339Nano)))
+	}
+	return builder.Build()
+}
+
+// applyStreamResult merges streamed entries. When the selection was on the
+// latest entry it follows the tail; otherwise the selected entry is kept.
+func (a *App) applyStreamResult(msg queryResultMsg) {
+	a.streamPending = false
+	a.state.StreamState.LastFetchTime = time.Now()
+	if msg.err != nil {
+		a.lastErr = fmt.Sprintf("Stream error: %v", msg.err)
+		return
+	}
+	loaded := a.state.LogListState.Logs
+	shown := a.viewLogs()
+	followTail := len(shown) == 0
+	selectedKey := ""
+	if len(shown) > 0 {
+		idx := a.currentSelectedIndex()
+		selectedKey = logEntryKey(shown[idx])
+		if a.logOrder == "latest_bottom" {
+			followTail = idx == len(shown)-1
+		} else {
+			followTail = idx == 0
+		}
+	}
+
+	a.state.LogListState.Logs = a.sortLogsForDisplay(mergeUniqueLogs(loaded, msg.logs, false))
+	added := len(a.state.LogListState.Logs) - len(loaded)
+	if added == 0 {
+		return
+	}
+	if followTail {
+		if a.logOrder == "latest_bottom" {
+			a.panes.LogList.scrollOffset = maxInt(0, len(a.viewLogs())-1)
+		} else {
+			a.panes.LogList.scrollOffset = 0
+		}
+	} else if idx, ok := a.findLogIndexByKey(selectedKey); ok {
+		a.panes.LogList.scrollOffset = idx
+	}
+	a.lastErr = fmt.Sprintf("Stream: +%d logs", added)
+}
@@ -0,0 +1,280 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// workspace is a tab. The active tab's data lives in App (a.state, scroll
+// and loading flags); inactive tabs keep a snapshot that is swapped back in
+// when they are selected or when a background result arrives.
+type workspace struct {
+	id            int
+	name          string
+	state         models.AppState
+	scrollOffset  int
+	loadingOlder  bool
+	loadingNewer  bool
+	streamPending bool
+	cacheHits     int
+	view          *logView
+	zoomStack     []models.TimeRange
+	metricFields  []string
+	metricStat    string
+}
+
+// activeTabID is the tab whose workspace is loaded: the active tab, or the
+// tab withWorkspace swapped in, so commands built there report back to it
+func (a *App) activeTabID() int {
+	if a.loadedTabID != 0 {
+		return a.loadedTabID
+	}
+	return a.tabs[a.activeTab].id
+}
+
+func (a *App) tabIndexByID(id int) int {
+	for i, ws := range a.tabs {
+		if ws.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// captureWorkspace stores the live tab data of App into ws.
+func (a *App) captureWorkspace(ws *workspace) {
+	ws.state = *a.state
+	ws.scrollOffset = a.panes.LogList.scrollOffset
+	ws.loadingOlder = a.loadingOlder
+	ws.loadingNewer = a.loadingNewer
+	ws.streamPending = a.streamPending
+	ws.cacheHits = a.cacheHits
+	ws.view = a.viewCache
+	ws.zoomStack = a.timelineZoomStack
+	ws.metricFields = a.metricFields
+	ws.metricStat = a.metricStat
+}
+
+// loadWorkspace makes ws the live tab data of App.
+func (a *App) loadWorkspace(ws *workspace) {
+	*a.state = ws.state
+	a.panes.LogList.scrollOffset = ws.scrollOffset
+	a.loadingOlder = ws.loadingOlder
+	a.loadingNewer = ws.loadingNewer
+	a.streamPending = ws.streamPending
+	a.cacheHits = ws.cacheHits
+	a.viewCache = ws.view
+	a.timelineZoomStack = ws.zoomStack
+	a.metricFields = ws.metricFields
+	a.metricStat = ws.metricStat
+}
+
+// switchTab activates the tab at idx, wrapping around at both ends.
+func (a *App) switchTab(idx int) {
+	if len(a.tabs) < 2 {
+		a.lastErr = "Only one tab open (ctrl+t opens another)"
+		return
+	}
+	idx = (idx + len(a.tabs)) % len(a.tabs)
+	if idx == a.activeTab {
+		return
+	}
+	a.captureWorkspace(a.tabs[a.activeTab])
+	a.activeTab = idx
+	a.loadWorkspace(a.tabs[idx])
+	a.leaveEmptyMetricChart()
+	a.state.StreamState.NewLogsCount = 0
+	a.resyncFilterControls()
+	a.lastErr = fmt.Sprintf("Tab %d/%d: %s", idx+1, len(a.tabs), a.tabs[idx].name)
+}
+
+// openNewTab opens a tab with the current project, query and filters and runs
+// the query in it.
+func (a *App) openNewTab() tea.Cmd {
+	return a.openTabWithFilter(a.state.CurrentQuery.Filter)
+}
+
+// openTabWithFilter opens a tab like openNewTab with filter as its query.
+func (a *App) openTabWithFilter(filter string) tea.Cmd {
+	a.captureWorkspace(a.tabs[a.activeTab])
+
+	state := *a.state
+	state.CurrentQuery.Filter = filter
+	customFilters := make(map[string]string, len(a.state.FilterState.CustomFilters))
+	for k, v := range a.state.FilterState.CustomFilters {
+		customFilters[k] = v
+	}
+	state.FilterState.CustomFilters = customFilters
+	state.FilterState.LocalFilters = append([]models.LocalFilter(nil), a.state.FilterState.LocalFilters...)
+	state.LogListState = models.LogListState{
+		Logs:      []models.LogEntry{},
+		Columns:   append([]models.Column(nil), a.state.LogListState.Columns...),
+		SortField: a.state.LogListState.SortField,
+		SortDesc:  a.state.LogListState.SortDesc,
+	}
+	state.StreamState.Enabled = false
+	state.StreamState.NewLogsCount = 0
+
+	ws := &workspace{
+		id:           a.nextTabID,
+		name:         fmt.Sprintf("tab%d", a.nextTabID),
+		state:        state,
+		metricFields: slices.Clone(a.metricFields),
+		metricStat:   a.metricStat,
+	}
+	a.nextTabID++
+	a.tabs = append(a.tabs, ws)
+	a.activeTab = len(a.tabs) - 1
+	a.loadWorkspace(ws)
+	a.resyncFilterControls()
+	a.lastErr = fmt.Sprintf("Opened tab %d/%d", a.activeTab+1, len(a.tabs))
+
+	if a.queryExec == nil {
+		return nil
+	}
+	effective := a.buildEffectiveFilter("")
+	if strings.TrimSpace(effective) == "" {
+		a.lastErr += " (press q to write a query)"
+		return nil
+	}
+	return a.executePrimaryQueryCmd(effective)
+}
+
+// closeActiveTab closes the active tab; the last remaining tab stays open.
+func (a *App) closeActiveTab() {
+	if len(a.tabs) < 2 {
+		a.lastErr = "Cannot close the last tab"
+		return
+	}
+	closed := a.tabs[a.activeTab]
+	delete(a.streamTicking, closed.id)
+	a.tabs = append(a.tabs[:a.activeTab], a.tabs[a.activeTab+1:]...)
+	if a.activeTab >= len(a.tabs) {
+		a.activeTab = len(a.tabs) - 1
+	}
+	if len(a.tabs) < 2 {
+		a.splitMode = splitOff
+	}
+	a.loadWorkspace(a.tabs[a.activeTab])
+	a.leaveEmptyMetricChart()
+	a.state.StreamState.NewLogsCount = 0
+	a.resyncFilterControls()
+	a.lastErr = fmt.Sprintf("Closed %s", closed.name)
+}
+
+func (a *App) renameActiveTab(name string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		a.lastErr = "Tab name cannot be empty"
+		return
+	}
+	a.tabs[a.activeTab].name = name
+	a.lastErr = "Renamed tab: " + name
+}
+
+// resyncFilterControls resets the shared time picker and severity panel to
+// the active tab's filter state.
+func (a *App) resyncFilterControls() {
+	a.timePicker.Reset()
+	a.severityFilter.Reset()
+	a.syncFilterControls()
+}
+
+// withWorkspace runs fn with the tab at idx swapped in as the live tab, then
+// restores the active tab. Modal and status line are left untouched.
+func (a *App) withWorkspace(idx int, fn func()) {
+	if idx == a.activeTab {
+		fn()
+		return
+	}
+	status := a.lastErr
+	modal := a.activeModalName
+	active := a.tabs[a.activeTab]
+	target := a.tabs[idx]
+
+	a.captureWorkspace(active)
+	a.loadWorkspace(target)
+	a.loadedTabID = target.id
+	fn()
+	a.loadedTabID = 0
+	a.captureWorkspace(target)
+	a.loadWorkspace(active)
+
+	a.lastErr = status
+	a.activeModalName = modal
+}
+
+// applyBackgroundResult applies a query result to the inactive tab that
+// issued it. It returns the follow-up command the result schedules for that
+// tab.
+func (a *App) applyBackgroundResult(msg queryResultMsg) tea.Cmd {
+	idx := a.tabIndexByID(msg.tabID)
+	if idx < 0 {
+		return nil
+	}
+	before := len(a.tabs[idx].state.LogListState.Logs)
+	msg.tabID = 0
+	// A session selection waiting for the active tab must not be consumed here.
+	pendingKey, pendingScroll := a.pendingSelectKey, a.pendingScroll
+	a.pendingSelectKey, a.pendingScroll = "", -1
+	var cmd tea.Cmd
+	a.withWorkspace(idx, func() {
+		_, cmd = a.Update(msg)
+	})
+	a.pendingSelectKey, a.pendingScroll = pendingKey, pendingScroll
+	if idx == a.splitPartnerIndex() && !msg.stream {
+		a.syncSplitPartner()
+	}
+	ws := a.tabs[idx]
+	if msg.stream {
+		ws.state.StreamState.NewLogsCount += maxInt(0, len(ws.state.LogListState.Logs)-before)
+		return cmd
+	}
+	if msg.err != nil {
+		a.lastErr = fmt.Sprintf("[%s] query error: %v", ws.name, msg.err)
+		return cmd
+	}
+	a.lastErr = fmt.Sprintf("[%s] loaded %d logs in background", ws.name, len(ws.state.LogListState.Logs))
+	return cmd
+}
+
+func (a *App) renderTabStrip() string {
+	activeStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorSelectionFG)).Background(lipgloss.Color(colorSelectionBG)).Padding(0, 1)
+	idleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle)).Padding(0, 1)
+	parts := make([]string, 0, len(a.tabs))
+	for i, ws := range a.tabs {
+		state := ws.state
+		if i == a.activeTab {
+			state = *a.state
+		}
+		label := fmt.Sprintf("%d:%s", i+1, ws.name)
+		if state.LogListState.IsLoading {
+			label += " …"
+		}
+		if state.StreamState.Enabled {
+			label += " ●"
+		}
+		if state.StreamState.NewLogsCount > 0 && i != a.activeTab {
+			label += fmt.Sprintf(" +%d", state.StreamState.NewLogsCount)
+		}
+		if i == a.activeTab {
+			parts = append(parts, activeStyle.Render(label))
+		} else {
+			parts = append(parts, idleStyle.Render(label))
+		}
+	}
+	line := strings.Join(parts, " ")
+	if lipgloss.Width(line) > a.width {
+		plain := []rune(ansiEscapeRegex.ReplaceAllString(line, ""))
+		line = string(plain[:maxInt(0, a.width-1)]) + "…"
+	}
+	if lipgloss.Width(line) < a.width {
+		line += strings.Repeat(" ", a.width-lipgloss.Width(line))
+	}
+	return line
+}
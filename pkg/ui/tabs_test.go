@@ -0,0 +1,207 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func newTabsTestApp(t *testing.T) *App {
+	t.Helper()
+	state := &models.AppState{
+		IsReady:        true,
+		CurrentProject: "api-project",
+		CurrentQuery:   models.Query{Filter: `resource.type="api"`},
+		LogListState:   models.LogListState{Logs: sessionTestLogs()},
+	}
+	app := NewApp(state)
+	app.width = 120
+	app.height = 40
+	return app
+}
+
+func TestOpenSwitchAndCloseTabs(t *testing.T) {
+	app := newTabsTestApp(t)
+	var projects []string
+	app.SetProjectQueryExecutor(func(project, filter string) ([]models.LogEntry, error) {
+		projects = append(projects, project)
+		return []models.LogEntry{{Timestamp: time.Now(), Severity: "ERROR", Message: "worker"}}, nil
+	})
+
+	newModel, cmd := app.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	app = newModel.(*App)
+	if len(app.tabs) != 2 || app.activeTab != 1 {
+		t.Fatalf("Expected second tab to be active, got %d tabs active=%d", len(app.tabs), app.activeTab)
+	}
+	if len(app.state.LogListState.Logs) != 0 {
+		t.Errorf("Expected new tab to start without logs, got %d", len(app.state.LogListState.Logs))
+	}
+	if app.state.CurrentQuery.Filter != `resource.type="api"` {
+		t.Errorf("Expected new tab to inherit the query, got %q", app.state.CurrentQuery.Filter)
+	}
+	if cmd == nil {
+		t.Fatal("Expected new tab to run its query")
+	}
+	msg := cmd()
+
+	// Switch back before the result arrives: it must land in the second tab.
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'['}})
+	app = newModel.(*App)
+	if app.activeTab != 0 {
+		t.Fatalf("Expected first tab after [, got %d", app.activeTab)
+	}
+	newModel, _ = app.Update(msg)
+	app = newModel.(*App)
+	if len(app.state.LogListState.Logs) != 5 {
+		t.Errorf("Background result changed the active tab: %d logs", len(app.state.LogListState.Logs))
+	}
+	if got := len(app.tabs[1].state.LogListState.Logs); got != 1 {
+		t.Errorf("Expected background tab to hold 1 log, got %d", got)
+	}
+	if app.tabs[1].state.LogListState.IsLoading {
+		t.Error("Expected background tab to finish loading")
+	}
+	if len(projects) != 1 || projects[0] != "api-project" {
+		t.Errorf("Expected query against the tab project, got %v", projects)
+	}
+
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{']'}})
+	app = newModel.(*App)
+	if app.activeTab != 1 || len(app.state.LogListState.Logs) != 1 {
+		t.Fatalf("Expected second tab with its logs, active=%d logs=%d", app.activeTab, len(app.state.LogListState.Logs))
+	}
+
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	app = newModel.(*App)
+	if len(app.tabs) != 1 || len(app.state.LogListState.Logs) != 5 {
+		t.Errorf("Expected first tab after close, tabs=%d logs=%d", len(app.tabs), len(app.state.LogListState.Logs))
+	}
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	app = newModel.(*App)
+	if len(app.tabs) != 1 {
+		t.Error("The last tab must stay open")
+	}
+}
+
+func TestBackgroundWorkspaceCommandsReportToTheirTab(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) { return nil, nil })
+	app.openNewTab()
+	app.switchTab(0)
+	var cmd tea.Cmd
+	app.withWorkspace(1, func() {
+		cmd = app.runQueryCmd("severity>=ERROR", "replace")
+	})
+	if app.activeTabID() != app.tabs[0].id {
+		t.Fatalf("Expected the active tab after withWorkspace, got %d", app.activeTabID())
+	}
+	msg, ok := cmd().(queryResultMsg)
+	if !ok || msg.tabID != app.tabs[1].id {
+		t.Errorf("Expected the command built for the background tab to report to it, got %+v", msg)
+	}
+}
+
+func TestTabsKeepSeparateProjects(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.openNewTab()
+	app.state.CurrentProject = "worker-project"
+	app.switchTab(0)
+	if app.state.CurrentProject != "api-project" {
+		t.Errorf("Expected first tab project, got %q", app.state.CurrentProject)
+	}
+	app.switchTab(1)
+	if app.state.CurrentProject != "worker-project" {
+		t.Errorf("Expected second tab project, got %q", app.state.CurrentProject)
+	}
+}
+
+func TestRenameTabPrompt(t *testing.T) {
+	app := newTabsTestApp(t)
+	newModel, _ := app.Update(tea.KeyMsg{Type: tea.KeyF2})
+	app = newModel.(*App)
+	if app.activeModalName != "prompt" {
+		t.Fatalf("Expected prompt modal, got %q", app.activeModalName)
+	}
+	app.prompt.SetValue("")
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("worker")})
+	app = newModel.(*App)
+	newModel, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	app = newModel.(*App)
+	if app.tabs[0].name != "worker" {
+		t.Errorf("Expected renamed tab, got %q", app.tabs[0].name)
+	}
+	if app.activeModalName != "none" {
+		t.Errorf("Expected prompt to close, got %q", app.activeModalName)
+	}
+}
+
+func TestTabStripRendering(t *testing.T) {
+	app := newTabsTestApp(t)
+	if strings.Contains(app.renderTopBar(), "1:tab1") {
+		t.Error("Tab strip should be hidden with a single tab")
+	}
+	app.openNewTab()
+	app.renameActiveTab("worker")
+	app.tabs[0].state.StreamState.NewLogsCount = 3
+	bar := app.renderTopBar()
+	for _, want := range []string{"1:tab1 +3", "2:worker"} {
+		if !strings.Contains(bar, want) {
+			t.Errorf("Expected top bar to contain %q, got %q", want, bar)
+		}
+	}
+}
+
+func TestStreamResultFollowsTail(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.logOrder = "latest_bottom"
+	app.panes.LogList.scrollOffset = 4
+	newest := app.state.LogListState.Logs[4].Timestamp
+	app.streamPending = true
+
+	app.applyStreamResult(queryResultMsg{stream: true, logs: []models.LogEntry{
+		{Timestamp: newest.Add(time.Minute), Severity: "INFO", Message: "new"},
+	}})
+	if app.streamPending {
+		t.Error("Expected pending flag to clear")
+	}
+	if len(app.state.LogListState.Logs) != 6 || app.panes.LogList.scrollOffset != 5 {
+		t.Errorf("Expected selection to follow the tail, logs=%d offset=%d", len(app.state.LogListState.Logs), app.panes.LogList.scrollOffset)
+	}
+
+	app.panes.LogList.scrollOffset = 1
+	app.applyStreamResult(queryResultMsg{stream: true, logs: []models.LogEntry{
+		{Timestamp: newest.Add(2 * time.Minute), Severity: "INFO", Message: "newer"},
+	}})
+	if app.panes.LogList.scrollOffset != 1 {
+		t.Errorf("Expected selection to stay put, got %d", app.panes.LogList.scrollOffset)
+	}
+}
+
+func TestStreamTickStopsForClosedTab(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.SetProjectQueryExecutor(func(project, filter string) ([]models.LogEntry, error) {
+		return nil, nil
+	})
+	app.streamTicking[42] = true
+	if cmd := app.handleStreamTick(streamTickMsg{tabID: 42}); cmd != nil {
+		t.Error("Expected no command for a closed tab")
+	}
+	if app.streamTicking[42] {
+		t.Error("Expected ticking flag to be cleared")
+	}
+
+	app.state.StreamState.Enabled = true
+	app.streamTicking[1] = true
+	if cmd := app.handleStreamTick(streamTickMsg{tabID: 1}); cmd == nil {
+		t.Error("Expected fetch and next tick while streaming")
+	}
+	if !app.streamPending {
+		t.Error("Expected a stream fetch to be pending")
+	}
+	if filter := app.buildStreamFilter(); !strings.Contains(filter, "timestamp>") {
+		t.Errorf("Expected stream filter to start after the newest entry, got %q", filter)
+	}
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Palette colors used across the app. They are variables so config.json can
+// override them through ApplyColorOverrides, at startup and when the project
+// is switched.
+var (
+	colorGCPBlue       = "33"
+	colorGCPBlueDark   = "24"
+	colorGCPBlueLight  = "117"
+	colorGCPGreen      = "42"
+	colorGCPWarn       = "220"
+	colorGCPError      = "203"
+	colorNeutralText   = "252"
+	colorNeutralSubtle = "244"
+	colorSelectionBG   = "25"
+	colorSelectionFG   = "230"
+	colorBadgeTextDark = "16"
+	colorBadgeTextLite = "230"
+)
+
+// themeColors maps config color names to palette entries.
+var themeColors = map[string]*string{
+	"primary":      &colorGCPBlue,
+	"primaryDark":  &colorGCPBlueDark,
+	"primaryLight": &colorGCPBlueLight,
+	"success":      &colorGCPGreen,
+	"warning":      &colorGCPWarn,
+	"error":        &colorGCPError,
+	"text":         &colorNeutralText,
+	"subtle":       &colorNeutralSubtle,
+	"selectionBg":  &colorSelectionBG,
+	"selectionFg":  &colorSelectionFG,
+	"badgeDark":    &colorBadgeTextDark,
+	"badgeLight":   &colorBadgeTextLite,
+}
+
+// themeDefaults are the built-in palette colors by name
+var themeDefaults = func() map[string]string {
+	defaults := make(map[string]string, len(themeColors))
+	for name, color := range themeColors {
+		defaults[name] = *color
+	}
+	return defaults
+}()
+
+// resetThemeColors restores the built-in palette before the overrides of
+// another project are applied
+func resetThemeColors() {
+	for name, color := range themeDefaults {
+		*themeColors[name] = color
+	}
+}
+
+// ThemeColorNames returns the color names accepted by ApplyColorOverrides.
+func ThemeColorNames() []string {
+	names := make([]string, 0, len(themeColors))
+	for name := range themeColors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyColorOverrides replaces palette colors by name. Values are lipgloss
+// colors (ANSI codes or hex). Unknown names are rejected without applying any.
+func ApplyColorOverrides(overrides map[string]string) error {
+	unknown := []string{}
+	for name := range overrides {
+		if _, ok := themeColors[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown color name(s) %s; valid names: %s", strings.Join(unknown, ", "), strings.Join(ThemeColorNames(), ", "))
+	}
+	for name, value := range overrides {
+		*themeColors[name] = value
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyColorOverrides(t *testing.T) {
+	original := colorGCPBlue
+	defer func() { colorGCPBlue = original }()
+
+	if err := ApplyColorOverrides(map[string]string{"primary": "#123456"}); err != nil {
+		t.Fatalf("ApplyColorOverrides failed: %v", err)
+	}
+	if colorGCPBlue != "#123456" {
+		t.Errorf("expected primary color override, got %s", colorGCPBlue)
+	}
+}
+
+func TestApplyColorOverridesRejectsUnknownNames(t *testing.T) {
+	original := colorGCPError
+	defer func() { colorGCPError = original }()
+
+	err := ApplyColorOverrides(map[string]string{"error": "9", "sparkle": "1"})
+	if err == nil || !strings.Contains(err.Error(), "sparkle") {
+		t.Fatalf("expected error naming unknown color, got %v", err)
+	}
+	if colorGCPError != original {
+		t.Error("no overrides should be applied when a name is unknown")
+	}
+}
@@ -2,6 +2,8 @@ package ui
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/user/log-explorer-tui/pkg/models"
@@ -363,3 +365,67 @@ func (tp *TimePicker) parseCustomTime(input string) (time.Time, error) {
 	}
 	return time.Time{}, fmt.Errorf("expected YYYY-MM-DD HH:MM:SS or RFC3339")
 }
+
+// ParseRangeDuration parses a relative range such as "15m", "1h", "7d" or "2w".
+// Day and week suffixes are accepted in addition to time.ParseDuration units.
+func ParseRangeDuration(input string) (time.Duration, error) {
+	s := strings.TrimSpace(input)
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	var d time.Duration
+	if unit > 0 {
+		n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", input)
+		}
+		d = time.Duration(n * float64(unit))
+	} else {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", input)
+		}
+		d = parsed
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive: %q", input)
+	}
+	return d, nil
+}
+
+// ParseTimeBound parses an absolute timestamp or a relative duration measured back from now.
+func ParseTimeBound(input string, now time.Time) (time.Time, error) {
+	s := strings.TrimSpace(input)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty time")
+	}
+	if s == "now" {
+		return now, nil
+	}
+	tp := &TimePicker{}
+	if t, err := tp.parseCustomTime(s); err == nil {
+		return t, nil
+	}
+	d, err := ParseRangeDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: expected RFC3339, YYYY-MM-DD HH:MM:SS or a duration like 2h", input)
+	}
+	return now.Add(-d), nil
+}
+
+// PresetKeyForDuration returns the picker preset key matching d, or "custom".
+func (tp *TimePicker) PresetKeyForDuration(d time.Duration) string {
+	for _, preset := range tp.GetPresets() {
+		if preset.Duration > 0 && preset.Duration == d {
+			return preset.Key
+		}
+	}
+	return "custom"
+}
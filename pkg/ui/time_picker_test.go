@@ -343,3 +343,43 @@ func TestApplyCustomInputs(t *testing.T) {
 		t.Fatalf("unexpected parsed end: %v", end)
 	}
 }
+
+func TestParseRangeDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"15m": 15 * time.Minute,
+		"1h":  time.Hour,
+		"7d":  7 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+	}
+	for input, want := range cases {
+		got, err := ParseRangeDuration(input)
+		if err != nil {
+			t.Fatalf("ParseRangeDuration(%q) failed: %v", input, err)
+		}
+		if got != want {
+			t.Errorf("ParseRangeDuration(%q) = %v, want %v", input, got, want)
+		}
+	}
+
+	for _, input := range []string{"", "abc", "-1h", "0d"} {
+		if _, err := ParseRangeDuration(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	got, err := ParseTimeBound("2h", now)
+	if err != nil || !got.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("Expected relative bound, got %v (%v)", got, err)
+	}
+	got, err = ParseTimeBound("2024-01-01T00:00:00Z", now)
+	if err != nil || !got.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected absolute bound, got %v (%v)", got, err)
+	}
+	if _, err := ParseTimeBound("yesterday", now); err == nil {
+		t.Error("Expected error for unparseable bound")
+	}
+}
@@ -2,6 +2,8 @@ package ui
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/user/log-explorer-tui/pkg/models"
@@ -10,6 +12,7 @@ import (
 // TimelineBuilder builds log count timelines and graphs
 type TimelineBuilder struct {
 	bucketSize time.Duration
+	chartMode  string
 }
 
 // NewTimelineBuilder creates a new timeline builder
@@ -32,7 +35,7 @@ func (tb *TimelineBuilder) BuildTimeline(logs []models.LogEntry) []models.LogGra
 	buckets := make(map[int64]*models.LogGraphPoint)
 
 	for _, log := range logs {
-		bucketTime := log.Timestamp.Truncate(tb.bucketSize)
+		bucketTime := tb.bucketStart(log.Timestamp)
 		bucketKey := bucketTime.Unix()
 
 		if _, exists := buckets[bucketKey]; !exists {
@@ -58,6 +61,11 @@ func (tb *TimelineBuilder) BuildTimeline(logs []models.LogEntry) []models.LogGra
 	return points
 }
 
+// bucketStart returns the start of the bucket holding t
+func (tb *TimelineBuilder) bucketStart(t time.Time) time.Time {
+	return t.Truncate(tb.bucketSize)
+}
+
 // BuildSeverityDistribution creates a distribution of logs by severity
 func (tb *TimelineBuilder) BuildSeverityDistribution(logs []models.LogEntry) map[string]int {
 	distribution := make(map[string]int)
@@ -92,10 +100,7 @@ func (tb *TimelineBuilder) RenderSparkline(points []models.LogGraphPoint, width
 	}
 
 	// Downsample to fit width
-	step := len(points) / width
-	if step < 1 {
-		step = 1
-	}
+	step := sparklineStep(len(points), width)
 
 	sparkchars := []rune{'▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
 
@@ -112,6 +117,57 @@ func (tb *TimelineBuilder) RenderSparkline(points []models.LogGraphPoint, width
 	return sparkline
 }
 
+// bucketSizes are the bucket sizes AdaptiveBucketSize picks from
+var bucketSizes = []time.Duration{
+	time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+	time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour,
+}
+
+// AdaptiveBucketSize returns the smallest round bucket size that splits
+// span into at most columns buckets
+func AdaptiveBucketSize(span time.Duration, columns int) time.Duration {
+	if columns < 1 {
+		columns = 1
+	}
+	for _, size := range bucketSizes {
+		if span/size < time.Duration(columns) {
+			return size
+		}
+	}
+	return bucketSizes[len(bucketSizes)-1]
+}
+
+// sparklineStep is how many points each sparkline column stands for
+func sparklineStep(points, width int) int {
+	if width < 10 {
+		width = 10
+	}
+	step := points / width
+	if step < 1 {
+		step = 1
+	}
+	return step
+}
+
+// FillTimeline adds empty buckets between start and end, so gaps show on
+// the sparkline and every timeline over the same range has the same buckets
+func (tb *TimelineBuilder) FillTimeline(points []models.LogGraphPoint, start, end time.Time) []models.LogGraphPoint {
+	byBucket := make(map[int64]models.LogGraphPoint, len(points))
+	for _, point := range points {
+		byBucket[point.Timestamp.Unix()] = point
+	}
+	var filled []models.LogGraphPoint
+	for ts := start.Truncate(tb.bucketSize); !ts.After(end); ts = ts.Add(tb.bucketSize) {
+		point, ok := byBucket[ts.Unix()]
+		if !ok {
+			point = models.LogGraphPoint{Timestamp: ts, Severity: make(map[string]int)}
+		}
+		filled = append(filled, point)
+	}
+	return filled
+}
+
 // RenderDistributionBar renders a simple bar chart for severity distribution
 func (tb *TimelineBuilder) RenderDistributionBar(distribution map[string]int, maxWidth int) string {
 	if len(distribution) == 0 {
@@ -213,8 +269,112 @@ func (tb *TimelineBuilder) GetTimelineStats(points []models.LogGraphPoint) map[s
 	}
 }
 
+// Anomaly detection compares each bucket with the median and the median
+// absolute deviation (MAD) of the buckets before it
+const (
+	anomalyBaseline    = 12   // buckets in the rolling baseline
+	anomalyMinBaseline = 4    // buckets needed before a bucket is judged
+	anomalyThreshold   = 3.5  // robust z-score that flags a bucket
+	anomalyMinChange   = 5    // smallest change in entries worth flagging
+	anomalyMinErrors   = 3    // smallest error count worth flagging
+	anomalyMinRatioMAD = 0.05 // floor for the error ratio deviation
+)
+
+// Anomaly kinds
+const (
+	AnomalySpike  = "spike"
+	AnomalyDrop   = "drop"
+	AnomalyErrors = "errors"
+)
+
+// TimelineAnomaly is a bucket whose volume or error ratio deviates from the
+// buckets before it
+type TimelineAnomaly struct {
+	Index     int // position in the points
+	Timestamp time.Time
+	Kind      string
+	Score     float64 // robust z-score
+}
+
+// DetectAnomalies flags buckets whose count or error ratio deviates from a
+// rolling baseline. Points should have no gaps; see FillTimeline.
+func (tb *TimelineBuilder) DetectAnomalies(points []models.LogGraphPoint) []TimelineAnomaly {
+	var anomalies []TimelineAnomaly
+	for i := anomalyMinBaseline; i < len(points); i++ {
+		baseline := points[maxInt(0, i-anomalyBaseline):i]
+		counts := make([]float64, 0, len(baseline))
+		var ratios []float64
+		for _, point := range baseline {
+			counts = append(counts, float64(point.Count))
+			if point.Count > 0 {
+				ratios = append(ratios, float64(errorCount(point))/float64(point.Count))
+			}
+		}
+
+		point := points[i]
+		anomaly := TimelineAnomaly{Index: i, Timestamp: point.Timestamp}
+		if errors := errorCount(point); errors >= anomalyMinErrors && len(ratios) > 0 {
+			median, mad := medianMAD(ratios)
+			ratio := float64(errors) / float64(point.Count)
+			anomaly.Score = (ratio - median) / math.Max(1.4826*mad, anomalyMinRatioMAD)
+			if anomaly.Score >= anomalyThreshold {
+				anomaly.Kind = AnomalyErrors
+				anomalies = append(anomalies, anomaly)
+				continue
+			}
+		}
+		median, mad := medianMAD(counts)
+		change := float64(point.Count) - median
+		anomaly.Score = change / math.Max(1.4826*mad, 1)
+		switch {
+		case math.Abs(change) < anomalyMinChange:
+		case anomaly.Score >= anomalyThreshold:
+			anomaly.Kind = AnomalySpike
+			anomalies = append(anomalies, anomaly)
+		case anomaly.Score <= -anomalyThreshold:
+			anomaly.Kind = AnomalyDrop
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+	return anomalies
+}
+
 // Helper functions
 
+// errorCount counts the entries of a point at ERROR or above
+func errorCount(point models.LogGraphPoint) int {
+	count := 0
+	for severity, n := range point.Severity {
+		if models.SeverityRank(severity) >= models.SeverityRank(models.SeverityError) {
+			count += n
+		}
+	}
+	return count
+}
+
+// medianMAD returns the median of values and their median absolute deviation
+func medianMAD(values []float64) (float64, float64) {
+	median := medianOf(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	return median, medianOf(deviations)
+}
+
+func medianOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
 func (tb *TimelineBuilder) sortPoints(points []models.LogGraphPoint) {
 	// Simple bubble sort for small datasets
 	for i := 0; i < len(points); i++ {
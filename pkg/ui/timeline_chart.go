@@ -0,0 +1,271 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// Timeline chart modes
+const (
+	ChartSparkline = "sparkline"
+	ChartStacked   = "stacked"
+)
+
+const (
+	chartAxisWidth    = 5  // y axis label and the axis line
+	chartMinAxisWidth = 30 // narrower charts leave out the y axis
+	chartTickGap      = 3  // columns between time tick labels
+)
+
+// chartBands are the severity bands of the stacked chart, bottom first, so
+// errors always sit on the baseline
+var chartBands = []struct {
+	label string
+	color *string
+}{
+	{"ERR", &colorGCPError},
+	{"WRN", &colorGCPWarn},
+	{"INF", &colorGCPBlue},
+	{"DBG", &colorNeutralSubtle},
+}
+
+// chartBlocks are the lower block characters by eighths
+var chartBlocks = []rune{' ', '▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// chartBand returns the band of a severity
+func chartBand(severity string) int {
+	rank := models.SeverityRank(severity)
+	switch {
+	case rank >= models.SeverityRank(models.SeverityError):
+		return 0
+	case rank >= models.SeverityRank(models.SeverityWarning):
+		return 1
+	case rank >= models.SeverityRank(models.SeverityInfo):
+		return 2
+	}
+	return 3
+}
+
+// TimelineChart is a timeline rendered over several rows
+type TimelineChart struct {
+	Rows   []string // plot rows, top first, each with its y axis label
+	Axis   string   // time tick labels under the plot
+	Step   int      // points per column
+	Offset int      // columns before the plot
+	legend []chartLegendItem
+}
+
+// chartLegendItem is a colored legend entry. The detail is left out when
+// the legend is short of room.
+type chartLegendItem struct {
+	label  string
+	detail string
+	color  string
+}
+
+// chartColumn is the count per band of the points drawn in one column
+type chartColumn struct {
+	bands [4]int
+	total int
+}
+
+// GetChartMode returns the chart mode, ChartSparkline or ChartStacked
+func (tb *TimelineBuilder) GetChartMode() string {
+	if tb.chartMode == "" {
+		return ChartSparkline
+	}
+	return tb.chartMode
+}
+
+// SetChartMode sets the chart mode
+func (tb *TimelineBuilder) SetChartMode(mode string) {
+	tb.chartMode = mode
+}
+
+// RenderStackedChart renders points as a chart of the given width and number
+// of rows. Each row has eight steps of resolution from block characters, and
+// columns sum the points they stand for instead of sampling them. Tick
+// labels are in loc.
+func (tb *TimelineBuilder) RenderStackedChart(points []models.LogGraphPoint, width, rows int, loc *time.Location) TimelineChart {
+	if width < 10 {
+		width = 10
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	chart, plotWidth := newTimelineChart(width)
+	chart.Step = maxInt(1, (len(points)+plotWidth-1)/plotWidth)
+
+	var columns []chartColumn
+	var starts []time.Time
+	var totals [4]int
+	maxTotal := 0
+	for i := 0; i < len(points); i += chart.Step {
+		column := chartColumn{}
+		for _, point := range points[i:minInt(i+chart.Step, len(points))] {
+			for severity, n := range point.Severity {
+				column.bands[chartBand(severity)] += n
+				totals[chartBand(severity)] += n
+			}
+			column.total += point.Count
+		}
+		maxTotal = maxInt(maxTotal, column.total)
+		columns = append(columns, column)
+		starts = append(starts, points[i].Timestamp)
+	}
+
+	units := rows * 8
+	tops := make([][4]int, len(columns))
+	for i, column := range columns {
+		tops[i] = stackBands(column.bands, maxTotal, units)
+	}
+	for row := rows - 1; row >= 0; row-- {
+		var sb strings.Builder
+		if chart.Offset > 0 {
+			sb.WriteString(chartAxisLabel(row, rows, compactCount(maxTotal), compactCount(maxTotal*(rows/2)/rows)))
+		}
+		for i := range columns {
+			sb.WriteString(chartCell(tops[i], row*8))
+		}
+		chart.Rows = append(chart.Rows, sb.String())
+	}
+	chart.Axis = strings.Repeat(" ", chart.Offset) + tb.chartTicks(starts, chart.Step, plotWidth, loc)
+	for b, band := range chartBands {
+		chart.legend = append(chart.legend, chartLegendItem{label: band.label, detail: formatCount(totals[b]), color: *band.color})
+	}
+	return chart
+}
+
+// newTimelineChart returns a chart with a y axis when width leaves room for
+// one, and the width left for the plot
+func newTimelineChart(width int) (TimelineChart, int) {
+	chart := TimelineChart{Step: 1}
+	if width >= chartMinAxisWidth {
+		chart.Offset = chartAxisWidth
+	}
+	return chart, width - chart.Offset
+}
+
+// stackBands returns the top of every band in eighths of a row, scaled so
+// maxTotal fills all units. A band with entries gets at least one unit.
+func stackBands(bands [4]int, maxTotal, units int) [4]int {
+	var tops [4]int
+	top := 0
+	for b, n := range bands {
+		if n > 0 && maxTotal > 0 {
+			top += maxInt(1, (n*units+maxTotal/2)/maxTotal)
+		}
+		tops[b] = minInt(top, units)
+	}
+	return tops
+}
+
+// chartCell renders the cell of a column starting at unit lo. A band ending
+// inside the cell is drawn as a partial block over the color of the band
+// above it.
+func chartCell(tops [4]int, lo int) string {
+	band := 0
+	for band < len(tops) && tops[band] <= lo {
+		band++
+	}
+	if band == len(tops) {
+		return " "
+	}
+	fill := minInt(tops[band]-lo, 8)
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color(*chartBands[band].color))
+	if fill < 8 {
+		for above := band + 1; above < len(tops); above++ {
+			if tops[above] > tops[band] {
+				style = style.Background(lipgloss.Color(*chartBands[above].color))
+				break
+			}
+		}
+	}
+	return style.Render(string(chartBlocks[fill]))
+}
+
+// chartAxisLabel renders the y axis of a row: the top row shows the top of
+// the scale and, from four rows up, the middle row shows the value at its top
+func chartAxisLabel(row, rows int, top, middle string) string {
+	label := ""
+	switch {
+	case row == rows-1:
+		label = top
+	case rows >= 4 && row == rows/2-1:
+		label = middle
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle)).Render(fmt.Sprintf("%*s┤", chartAxisWidth-1, label))
+}
+
+// chartTicks renders time labels under the plot, one every few columns,
+// with a layout that fits the span of a column
+func (tb *TimelineBuilder) chartTicks(starts []time.Time, step, width int, loc *time.Location) string {
+	if len(starts) == 0 {
+		return ""
+	}
+	perColumn := tb.bucketSize * time.Duration(step)
+	span := perColumn * time.Duration(len(starts))
+	layout := "15:04"
+	switch {
+	case perColumn >= 12*time.Hour:
+		layout = "01-02"
+	case span > 24*time.Hour:
+		layout = "01-02 15:04"
+	case perColumn < time.Minute && span < 10*time.Minute:
+		layout = "15:04:05"
+	}
+	line := []rune(strings.Repeat(" ", minInt(width, len(starts))))
+	for col := 0; col+len(layout) <= len(line); col += len(layout) + chartTickGap {
+		copy(line[col:], []rune(starts[col].In(loc).Format(layout)))
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle)).Render(string(line))
+}
+
+// Legend renders the colored legend entries with their details. When the
+// details do not all fit width it shows the labels alone, as many as fit.
+func (c TimelineChart) Legend(width int) string {
+	texts := make([]string, len(c.legend))
+	full := 0
+	for i, item := range c.legend {
+		texts[i] = strings.TrimSpace(item.label + " " + item.detail)
+		full += len(texts[i]) + 4
+	}
+	sep := "  "
+	if full-2 > width {
+		sep = " "
+		for i, item := range c.legend {
+			texts[i] = item.label
+		}
+	}
+	var parts []string
+	used := 0
+	for i, item := range c.legend {
+		if used+len(texts[i])+2 > width {
+			break
+		}
+		used += len(texts[i]) + 2 + len(sep)
+		swatch := lipgloss.NewStyle().Foreground(lipgloss.Color(item.color)).Render("■")
+		parts = append(parts, swatch+" "+texts[i])
+	}
+	return strings.Join(parts, sep)
+}
+
+// compactCount renders a count in at most four characters, e.g. "950",
+// "1.2k", "12k" or "3.4M"
+func compactCount(n int) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprintf("%d", n)
+	case n < 9950:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	case n < 999500:
+		return fmt.Sprintf("%dk", (n+500)/1000)
+	case n < 9950000:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	}
+	return fmt.Sprintf("%dM", (n+500000)/1000000)
+}
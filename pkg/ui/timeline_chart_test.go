@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func chartTestPoints() []models.LogGraphPoint {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var points []models.LogGraphPoint
+	for i := 0; i < 20; i++ {
+		severity := map[string]int{"INFO": 6, "DEBUG": 2}
+		if i == 12 {
+			severity = map[string]int{"ERROR": 8, "WARNING": 4, "INFO": 4}
+		}
+		count := 0
+		for _, n := range severity {
+			count += n
+		}
+		points = append(points, models.LogGraphPoint{Timestamp: start.Add(time.Duration(i) * time.Minute), Count: count, Severity: severity})
+	}
+	return points
+}
+
+func TestStackBands(t *testing.T) {
+	// 8 errors, 4 warnings and 4 info out of 16 over two rows
+	if got := stackBands([4]int{8, 4, 4, 0}, 16, 16); got != [4]int{8, 12, 16, 16} {
+		t.Errorf("Unexpected band tops %v", got)
+	}
+	// A single error still shows
+	if got := stackBands([4]int{1, 0, 999, 0}, 1000, 16); got[0] != 1 {
+		t.Errorf("Expected the error band to get a unit, got %v", got)
+	}
+	if chartBand("CRITICAL") != 0 || chartBand("NOTICE") != 2 || chartBand("") != 3 {
+		t.Error("Unexpected severity bands")
+	}
+	for n, want := range map[int]string{950: "950", 1234: "1.2k", 9990: "10k", 45600: "46k", 3400000: "3.4M"} {
+		if got := compactCount(n); got != want {
+			t.Errorf("compactCount(%d) = %s, want %s", n, got, want)
+		}
+	}
+}
+
+func TestRenderStackedChart(t *testing.T) {
+	tb := NewTimelineBuilder(time.Minute)
+	berlin := time.FixedZone("CEST", 2*60*60)
+	chart := tb.RenderStackedChart(chartTestPoints(), 60, 4, berlin)
+	if len(chart.Rows) != 4 || chart.Step != 1 || chart.Offset != chartAxisWidth {
+		t.Fatalf("Expected 4 rows with a y axis, got %d rows, step %d, offset %d", len(chart.Rows), chart.Step, chart.Offset)
+	}
+	rows := make([]string, len(chart.Rows))
+	for i, row := range chart.Rows {
+		rows[i] = ansiEscapeRegex.ReplaceAllString(row, "")
+	}
+	if !strings.HasPrefix(rows[0], "  16┤") || !strings.HasPrefix(rows[2], "   8┤") {
+		t.Errorf("Expected the scale on the y axis, got %q and %q", rows[0], rows[2])
+	}
+	// The busiest minute fills the top row, the others reach half way
+	if got := []rune(rows[0])[chartAxisWidth+12]; got != '█' {
+		t.Errorf("Expected the busiest column to fill the top row, got %q", got)
+	}
+	if got := []rune(rows[1])[chartAxisWidth]; got != ' ' {
+		t.Errorf("Expected a quiet column to stop at half height, got %q", got)
+	}
+	axis := ansiEscapeRegex.ReplaceAllString(chart.Axis, "")
+	if !strings.HasPrefix(axis, "     12:00   12:08") {
+		t.Errorf("Expected tick labels in the display timezone, got %q", axis)
+	}
+	legend := ansiEscapeRegex.ReplaceAllString(chart.Legend(60), "")
+	if legend != "■ ERR 8  ■ WRN 4  ■ INF 118  ■ DBG 38" {
+		t.Errorf("Unexpected legend %q", legend)
+	}
+	if legend := ansiEscapeRegex.ReplaceAllString(chart.Legend(12), ""); legend != "■ ERR ■ WRN" {
+		t.Errorf("Expected the band names alone at narrow widths, got %q", legend)
+	}
+
+	// Narrow charts drop the y axis and sum points into columns
+	narrow := tb.RenderStackedChart(chartTestPoints(), 12, 2, time.UTC)
+	if narrow.Offset != 0 || narrow.Step != 2 {
+		t.Errorf("Expected no axis and 2 points a column, got offset %d, step %d", narrow.Offset, narrow.Step)
+	}
+	for _, row := range narrow.Rows {
+		if width := len([]rune(ansiEscapeRegex.ReplaceAllString(row, ""))); width != 10 {
+			t.Errorf("Expected 10 columns, got %d", width)
+		}
+	}
+}
+
+func TestTimelineChartToggle(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.SetTimelineBucket(time.Minute)
+	app.state.LogListState.Logs = anomalyTestLogs()
+
+	app = pressKeys(app, runeKey('V'))
+	if app.timelineBuilder.GetChartMode() != ChartStacked {
+		t.Fatalf("Expected V to switch to the stacked chart")
+	}
+	panel := ansiEscapeRegex.ReplaceAllString(app.renderGraphPanel(), "")
+	for _, want := range []string{"Timeline ■ ERR 30  ■ WRN 0  ■ INF 36", "  30┤", "10:00", "▲"} {
+		if !strings.Contains(panel, want) {
+			t.Errorf("Expected %q in the graph panel, got:\n%s", want, panel)
+		}
+	}
+	if strings.Contains(panel, "Severity Mix") {
+		t.Error("Expected the legend to replace the severity mix")
+	}
+	if lines := strings.Count(panel, "\n"); lines != app.timelineChartRows()+6 {
+		t.Errorf("Unexpected graph panel height %d", lines)
+	}
+
+	app = pressKeys(app, runeKey('V'))
+	if !strings.Contains(app.renderGraphPanel(), "Severity Mix") {
+		t.Error("Expected V to switch back to the sparkline")
+	}
+}
@@ -0,0 +1,331 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// maxTimelineBuckets caps the empty buckets filled in with a fixed bucket
+// size over a long span of loaded entries
+const maxTimelineBuckets = 5000
+
+// timelineColumns is the width of the sparkline, or of the chart plot, in
+// the graph panel
+func (a *App) timelineColumns() int {
+	if a.timelineBuilder.GetChartMode() != ChartSparkline {
+		return maxInt(10, a.panelInnerWidth()-chartAxisWidth)
+	}
+	return maxInt(10, a.width-24)
+}
+
+// timelineSpan returns the time the timeline covers: the time range and the
+// loaded entries when buckets follow the range, else the loaded entries
+func (a *App) timelineSpan() (time.Time, time.Time, bool) {
+	if len(a.state.LogListState.Logs) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	start, end := a.oldestLoadedTimestamp(), a.newestLoadedTimestamp()
+	if tr := a.state.FilterState.TimeRange; a.timelineAuto && !tr.Start.IsZero() && !tr.End.IsZero() {
+		if tr.Start.Before(start) {
+			start = tr.Start
+		}
+		if tr.End.After(end) {
+			end = tr.End
+		}
+	}
+	return start, end, true
+}
+
+// syncTimelineBucket picks the bucket size that fits the span into the
+// sparkline or chart in auto mode. Update calls it after every message, as
+// the span, the panel width and the chart mode all change the size.
+func (a *App) syncTimelineBucket() {
+	if !a.timelineAuto {
+		return
+	}
+	if start, end, ok := a.timelineSpan(); ok {
+		a.timelineBuilder.SetBucketSize(AdaptiveBucketSize(end.Sub(start), a.timelineColumns()))
+	}
+}
+
+// timelinePoints returns the timeline of the loaded entries with the empty
+// buckets filled in, in the buckets syncTimelineBucket picked
+func (a *App) timelinePoints() []models.LogGraphPoint {
+	start, end, ok := a.timelineSpan()
+	if !ok {
+		return nil
+	}
+	points := a.timelineBuilder.BuildTimeline(a.state.LogListState.Logs)
+	if end.Sub(start)/a.timelineBuilder.GetBucketSize() > maxTimelineBuckets {
+		return points
+	}
+	return a.timelineBuilder.FillTimeline(points, start, end)
+}
+
+// formatBucketSize renders a bucket size such as "30s", "5m", "3h" or "1d"
+func formatBucketSize(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Minute && d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return fmt.Sprintf("%ds", d/time.Second)
+}
+
+// decorateSparkline colors the sparkline columns: anomalies in red and,
+// while the timeline has focus, the brushed span and the cursor. step is
+// the number of points per column, as in RenderSparkline.
+func (a *App) decorateSparkline(spark string, step int, anomalies []TimelineAnomaly) string {
+	flagged := map[int]bool{}
+	for _, anomaly := range anomalies {
+		flagged[anomaly.Index/step] = true
+	}
+	lo, hi := -1, -1
+	if a.timelineFocused {
+		lo, hi = a.timelineSelection()
+		lo, hi = lo/step, hi/step
+	}
+	anomalyStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorGCPError))
+	brushStyle := lipgloss.NewStyle().Background(lipgloss.Color(colorSelectionBG))
+	var sb strings.Builder
+	for col, r := range []rune(spark) {
+		style := lipgloss.NewStyle()
+		styled := false
+		if flagged[col] {
+			style, styled = anomalyStyle, true
+		}
+		if col >= lo && col <= hi {
+			style, styled = style.Inherit(brushStyle), true
+			if col == a.timelineCursor/step {
+				style = style.Reverse(true)
+			}
+		}
+		if styled {
+			sb.WriteString(style.Render(string(r)))
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// timelineSelection returns the first and last bucket of the brushed span,
+// or the cursor bucket twice
+func (a *App) timelineSelection() (int, int) {
+	if a.timelineBrush < 0 {
+		return a.timelineCursor, a.timelineCursor
+	}
+	return minInt(a.timelineBrush, a.timelineCursor), maxInt(a.timelineBrush, a.timelineCursor)
+}
+
+// timelineCursorLine describes the selected buckets and the keys
+func (a *App) timelineCursorLine(points []models.LogGraphPoint) string {
+	if len(points) == 0 {
+		return "No timeline data | Esc done"
+	}
+	lo, hi := a.timelineSelection()
+	hi = minInt(hi, len(points)-1)
+	count, errors := 0, 0
+	for _, point := range points[lo : hi+1] {
+		count += point.Count
+		errors += errorCount(point)
+	}
+	bucket := a.timelineBuilder.GetBucketSize()
+	span := fmt.Sprintf("%s - %s", a.displayTime(points[lo].Timestamp).Format("01-02 15:04:05"), a.displayTime(points[hi].Timestamp.Add(bucket)).Format("01-02 15:04:05"))
+	keys := "h/l bucket | Space brush | Enter zoom | u zoom out | Esc done"
+	if a.timelineBuilder.GetChartMode() == ChartMetrics {
+		keys = "h/l bucket | Space brush | Enter zoom | u zoom out | s " + a.metricStat + " | Esc done"
+	}
+	return fmt.Sprintf("%s  %s entries, %s errors | %s", span, formatCount(count), formatCount(errors), keys)
+}
+
+// focusTimeline moves the keyboard focus to the timeline, with the cursor on
+// the bucket of the selected entry
+func (a *App) focusTimeline() {
+	points := a.timelinePoints()
+	if len(points) == 0 {
+		a.lastErr = "No timeline data"
+		return
+	}
+	a.timelineFocused = true
+	a.timelineBrush = -1
+	a.timelineCursor = len(points) - 1
+	if entry := a.getSelectedLog(); entry != nil {
+		a.timelineCursor = bucketIndex(points, entry.Timestamp, a.timelineBuilder.GetBucketSize())
+	}
+}
+
+// bucketIndex returns the index of the bucket holding ts
+func bucketIndex(points []models.LogGraphPoint, ts time.Time, bucket time.Duration) int {
+	start := ts.Truncate(bucket)
+	for i, point := range points {
+		if !point.Timestamp.Before(start) {
+			return i
+		}
+	}
+	return len(points) - 1
+}
+
+// moveTimelineCursor moves the cursor by delta buckets and selects the first
+// entry of that bucket in the list
+func (a *App) moveTimelineCursor(points []models.LogGraphPoint, delta int) {
+	a.timelineCursor = clampInt(a.timelineCursor+delta, 0, len(points)-1)
+	bucket := a.timelineBuilder.GetBucketSize()
+	start := points[a.timelineCursor].Timestamp
+	for i, entry := range a.viewLogs() {
+		if !entry.Timestamp.Before(start) && entry.Timestamp.Before(start.Add(bucket)) {
+			a.panes.LogList.scrollOffset = i
+			return
+		}
+	}
+}
+
+// zoomTimeRange sets the time range and reruns the query. The previous
+// range is kept so u on the timeline can go back to it.
+func (a *App) zoomTimeRange(tr models.TimeRange) tea.Cmd {
+	a.timelineZoomStack = append(a.timelineZoomStack, a.state.FilterState.TimeRange)
+	a.state.FilterState.TimeRange = tr
+	a.resyncFilterControls()
+	a.timelineBrush = -1
+	a.timelineCursor = 0
+	if a.queryExec == nil {
+		return nil
+	}
+	return a.executePrimaryQueryCmd(a.buildEffectiveFilter(""))
+}
+
+// zoomTimelineIn zooms into the selected buckets
+func (a *App) zoomTimelineIn(points []models.LogGraphPoint) tea.Cmd {
+	lo, hi := a.timelineSelection()
+	hi = minInt(hi, len(points)-1)
+	tr := models.TimeRange{
+		Start:  points[lo].Timestamp,
+		End:    points[hi].Timestamp.Add(a.timelineBuilder.GetBucketSize()),
+		Preset: "custom",
+	}
+	cmd := a.zoomTimeRange(tr)
+	a.lastErr = fmt.Sprintf("Zoomed to %s - %s", a.displayTime(tr.Start).Format("01-02 15:04:05"), a.displayTime(tr.End).Format("01-02 15:04:05"))
+	return cmd
+}
+
+// zoomTimelineOut goes back to the time range before the last zoom
+func (a *App) zoomTimelineOut() tea.Cmd {
+	if len(a.timelineZoomStack) == 0 {
+		a.lastErr = "Nothing to zoom out to"
+		return nil
+	}
+	last := len(a.timelineZoomStack) - 1
+	a.state.FilterState.TimeRange = a.timelineZoomStack[last]
+	a.timelineZoomStack = a.timelineZoomStack[:last]
+	a.resyncFilterControls()
+	a.timelineBrush = -1
+	a.timelineCursor = 0
+	a.lastErr = fmt.Sprintf("Zoomed out (%d left)", len(a.timelineZoomStack))
+	if a.queryExec == nil {
+		return nil
+	}
+	return a.executePrimaryQueryCmd(a.buildEffectiveFilter(""))
+}
+
+// handleTimelineInput handles keys while the timeline has focus. Keys it
+// does not use fall through to the log list.
+func (a *App) handleTimelineInput(msg tea.KeyMsg) (tea.Cmd, bool) {
+	points := a.timelinePoints()
+	if len(points) == 0 {
+		a.timelineFocused = false
+		return nil, false
+	}
+	a.timelineCursor = clampInt(a.timelineCursor, 0, len(points)-1)
+	switch msg.String() {
+	case "esc", "tab":
+		a.timelineFocused = false
+		a.timelineBrush = -1
+	case "h", "left":
+		a.moveTimelineCursor(points, -1)
+	case "l", "right":
+		a.moveTimelineCursor(points, 1)
+	case "H", "home":
+		a.moveTimelineCursor(points, -len(points))
+	case "L", "end":
+		a.moveTimelineCursor(points, len(points))
+	case " ", "v":
+		if a.timelineBrush >= 0 {
+			a.timelineBrush = -1
+		} else {
+			a.timelineBrush = a.timelineCursor
+		}
+	case "enter":
+		return a.zoomTimelineIn(points), true
+	case "u", "backspace":
+		return a.zoomTimelineOut(), true
+	case "s":
+		if a.timelineBuilder.GetChartMode() != ChartMetrics {
+			return nil, false
+		}
+		a.cycleMetricStat()
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// toggleTimelineChart cycles the graph panel through the sparkline, the
+// stacked severity chart and, when fields are plotted, the metric chart
+func (a *App) toggleTimelineChart() {
+	switch a.timelineBuilder.GetChartMode() {
+	case ChartSparkline:
+		a.timelineBuilder.SetChartMode(ChartStacked)
+		a.lastErr = "Timeline: stacked severity chart"
+	case ChartStacked:
+		if len(a.metricFields) > 0 {
+			a.timelineBuilder.SetChartMode(ChartMetrics)
+			a.lastErr = "Timeline: plotted fields"
+			return
+		}
+		fallthrough
+	default:
+		a.timelineBuilder.SetChartMode(ChartSparkline)
+		a.lastErr = "Timeline: sparkline"
+	}
+}
+
+// timelineChartRows is the height of the stacked chart, smaller on short
+// terminals so the log list keeps its room
+func (a *App) timelineChartRows() int {
+	return clampInt(a.height/8, 2, 8)
+}
+
+// chartMarks renders the line under the stacked chart that points at the
+// anomalies and, while the timeline has focus, the brushed span and the
+// cursor. It is empty when there is nothing to point at.
+func (a *App) chartMarks(chart TimelineChart, points int, anomalies []TimelineAnomaly) string {
+	if len(anomalies) == 0 && !a.timelineFocused {
+		return ""
+	}
+	marks := make([]string, (points+chart.Step-1)/chart.Step)
+	for i := range marks {
+		marks[i] = " "
+	}
+	anomalyStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorGCPError))
+	for _, anomaly := range anomalies {
+		marks[anomaly.Index/chart.Step] = anomalyStyle.Render("▲")
+	}
+	if a.timelineFocused {
+		lo, hi := a.timelineSelection()
+		for col := lo / chart.Step; col <= hi/chart.Step && col < len(marks); col++ {
+			marks[col] = "─"
+		}
+		if col := a.timelineCursor / chart.Step; col < len(marks) {
+			marks[col] = a.selectedRowStyle().Render("▲")
+		}
+	}
+	return strings.Repeat(" ", chart.Offset) + strings.Join(marks, "")
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func TestAdaptiveBucketSize(t *testing.T) {
+	tests := []struct {
+		span time.Duration
+		want time.Duration
+	}{
+		{10 * time.Minute, 10 * time.Second},
+		{time.Hour, time.Minute},
+		{24 * time.Hour, 30 * time.Minute},
+		{30 * 24 * time.Hour, 12 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := AdaptiveBucketSize(tt.span, 96); got != tt.want {
+			t.Errorf("AdaptiveBucketSize(%v) = %v, want %v", tt.span, got, tt.want)
+		}
+	}
+	if got := formatBucketSize(30 * time.Minute); got != "30m" {
+		t.Errorf("Expected 30m, got %s", got)
+	}
+}
+
+func TestTimelineFocusBrushZoomAndBack(t *testing.T) {
+	app := newTabsTestApp(t)
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	hour := models.TimeRange{Start: start, End: start.Add(time.Hour), Preset: "1h"}
+	app.state.FilterState.TimeRange = hour
+	app.state.LogListState.Logs = anomalyTestLogs() // 10:00 - 10:09, newest first
+	var ran []string
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		ran = append(ran, filter)
+		return nil, nil
+	})
+	app.panes.LogList.scrollOffset = len(app.viewLogs()) - 1 // 10:00:00
+
+	app = pressKeys(app, tea.KeyMsg{Type: tea.KeyTab})
+	if !app.timelineFocused || app.timelineBuilder.GetBucketSize() != time.Minute {
+		t.Fatalf("Expected the timeline focused with 1m buckets for an hour, got %v", app.timelineBuilder.GetBucketSize())
+	}
+	if app.timelineCursor != 0 {
+		t.Errorf("Expected the cursor on the bucket of the selected entry, got %d", app.timelineCursor)
+	}
+
+	// Moving the cursor selects the bucket in the list
+	app = pressKeys(app, runeKey('l'), runeKey('l'))
+	if selected := app.getSelectedLog(); selected == nil || !strings.HasPrefix(selected.ID, "2-") {
+		t.Errorf("Expected an entry of 10:02 selected, got %+v", selected)
+	}
+	view := ansiEscapeRegex.ReplaceAllString(app.View(), "")
+	if !strings.Contains(view, "05-01 10:02:00 - 05-01 10:03:00  4 entries, 0 errors") {
+		t.Errorf("Expected the cursor line in the graph panel")
+	}
+
+	// Brush 10:02 - 10:04 and zoom in
+	app = pressKeys(app, tea.KeyMsg{Type: tea.KeySpace}, runeKey('l'), runeKey('l'))
+	_, cmd := app.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected Enter to rerun the query")
+	}
+	cmd()
+	tr := app.state.FilterState.TimeRange
+	if !tr.Start.Equal(start.Add(2*time.Minute)) || !tr.End.Equal(start.Add(5*time.Minute)) {
+		t.Errorf("Expected 10:02 - 10:05, got %v - %v", tr.Start, tr.End)
+	}
+	if !strings.Contains(ran[len(ran)-1], `timestamp>="2024-05-01T10:02:00Z"`) {
+		t.Errorf("Unexpected zoom query %q", ran[len(ran)-1])
+	}
+
+	// u restores the hour
+	_, cmd = app.handleKeyPress(runeKey('u'))
+	if cmd == nil || app.state.FilterState.TimeRange != hour || len(app.timelineZoomStack) != 0 {
+		t.Errorf("Expected the previous range back, got %v", app.state.FilterState.TimeRange)
+	}
+	if _, cmd := app.handleKeyPress(runeKey('u')); cmd != nil || app.lastErr != "Nothing to zoom out to" {
+		t.Errorf("Expected nothing to zoom out to, got %q", app.lastErr)
+	}
+
+	// Keys the timeline does not use still reach the list
+	before := app.panes.LogList.scrollOffset
+	app = pressKeys(app, runeKey('k'))
+	if !app.timelineFocused || app.panes.LogList.scrollOffset != before-1 {
+		t.Errorf("Expected k to move the list selection, got %d from %d", app.panes.LogList.scrollOffset, before)
+	}
+	app = pressKeys(app, tea.KeyMsg{Type: tea.KeyEsc})
+	if app.timelineFocused {
+		t.Error("Expected Esc to leave the timeline")
+	}
+}
@@ -0,0 +1,294 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+// ChartMetrics plots numeric fields instead of entry counts
+const ChartMetrics = "metrics"
+
+// Statistics a metric series can plot
+const (
+	MetricAvg = "avg"
+	MetricP95 = "p95"
+	MetricMax = "max"
+	MetricMin = "min"
+)
+
+// metricStats is the order the plotted statistic cycles through
+var metricStats = []string{MetricAvg, MetricP95, MetricMax, MetricMin}
+
+// metricColors are the colors of the overlaid series, in order
+var metricColors = []*string{&colorGCPBlueLight, &colorGCPGreen, &colorGCPWarn, &colorGCPError}
+
+// brailleDots are the dot bits of a braille cell by column and by row from
+// the top
+var brailleDots = [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+
+// MetricPoint summarizes the values of a numeric field in one bucket
+type MetricPoint struct {
+	Timestamp time.Time
+	Count     int
+	Min       float64
+	Avg       float64
+	Max       float64
+	P95       float64
+}
+
+// Stat returns one of the statistics of the point
+func (p MetricPoint) Stat(stat string) float64 {
+	switch stat {
+	case MetricMin:
+		return p.Min
+	case MetricMax:
+		return p.Max
+	case MetricP95:
+		return p.P95
+	}
+	return p.Avg
+}
+
+// MetricSeries is a numeric field plotted on the timeline
+type MetricSeries struct {
+	Field  string
+	Stat   string
+	Points []MetricPoint
+}
+
+// NumericFieldValue returns the value of a field as a number. Durations such
+// as the httpRequest latency "0.250s" are read in seconds.
+func NumericFieldValue(entry models.LogEntry, field string) (float64, bool) {
+	value, ok := entry.FieldValue(field)
+	if !ok {
+		return 0, false
+	}
+	value = strings.TrimSpace(value)
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f, !math.IsNaN(f) && !math.IsInf(f, 0)
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d.Seconds(), true
+	}
+	return 0, false
+}
+
+// BuildMetricSeries summarizes the numeric values of field per bucket, in
+// the buckets BuildTimeline uses. Buckets without a value are left out.
+func (tb *TimelineBuilder) BuildMetricSeries(logs []models.LogEntry, field string) []MetricPoint {
+	buckets := make(map[int64][]float64)
+	starts := make(map[int64]time.Time)
+	for _, log := range logs {
+		value, ok := NumericFieldValue(log, field)
+		if !ok {
+			continue
+		}
+		start := tb.bucketStart(log.Timestamp)
+		buckets[start.Unix()] = append(buckets[start.Unix()], value)
+		starts[start.Unix()] = start
+	}
+
+	points := make([]MetricPoint, 0, len(buckets))
+	for key, values := range buckets {
+		sort.Float64s(values)
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		rank := (95*len(values) + 99) / 100
+		points = append(points, MetricPoint{
+			Timestamp: starts[key],
+			Count:     len(values),
+			Min:       values[0],
+			Avg:       sum / float64(len(values)),
+			Max:       values[len(values)-1],
+			P95:       values[maxInt(0, rank-1)],
+		})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+	return points
+}
+
+// mergeMetricPoints combines the points drawn in one dot column. The p95 of
+// the merged points is approximated by the largest of their p95s.
+func mergeMetricPoints(points []MetricPoint) MetricPoint {
+	merged := points[0]
+	sum := merged.Avg * float64(merged.Count)
+	for _, point := range points[1:] {
+		merged.Min = math.Min(merged.Min, point.Min)
+		merged.Max = math.Max(merged.Max, point.Max)
+		merged.P95 = math.Max(merged.P95, point.P95)
+		merged.Count += point.Count
+		sum += point.Avg * float64(point.Count)
+	}
+	merged.Avg = sum / float64(merged.Count)
+	return merged
+}
+
+// RenderMetricChart overlays the series as braille lines over the buckets
+// of points, with two dot columns and four dot rows per cell. Each series
+// has its own scale, from zero or its lowest value to its highest; the y
+// axis shows the scale of the first series. Tick labels are in loc.
+func (tb *TimelineBuilder) RenderMetricChart(points []models.LogGraphPoint, series []MetricSeries, width, rows int, loc *time.Location) TimelineChart {
+	if width < 10 {
+		width = 10
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	chart, plotWidth := newTimelineChart(width)
+	// Narrow buckets get a cell each, drawn over both dot columns; past the
+	// plot width every dot column sums perDot buckets
+	perDot := 1
+	dotColumns := 2 * len(points)
+	if len(points) > plotWidth {
+		perDot = (len(points) + 2*plotWidth - 1) / (2 * plotWidth)
+		chart.Step = 2 * perDot
+		dotColumns = (len(points) + perDot - 1) / perDot
+	}
+	bucketsOf := func(x int) []models.LogGraphPoint {
+		if chart.Step == 1 {
+			return points[x/2 : x/2+1]
+		}
+		return points[x*perDot : minInt((x+1)*perDot, len(points))]
+	}
+
+	columns := (dotColumns + 1) / 2
+	cells := make([][]rune, rows)
+	colors := make([][]string, rows)
+	for r := range cells {
+		cells[r] = make([]rune, columns)
+		colors[r] = make([]string, columns)
+	}
+	dots := rows * 4
+	top, middle := "", ""
+	for i, s := range series {
+		color := *metricColors[i%len(metricColors)]
+		byBucket := make(map[int64]MetricPoint, len(s.Points))
+		for _, point := range s.Points {
+			byBucket[point.Timestamp.Unix()] = point
+		}
+		values := make([]float64, dotColumns)
+		has := make([]bool, dotColumns)
+		lo, hi := 0.0, math.Inf(-1)
+		for x := range values {
+			var merged []MetricPoint
+			for _, bucket := range bucketsOf(x) {
+				if point, ok := byBucket[bucket.Timestamp.Unix()]; ok {
+					merged = append(merged, point)
+				}
+			}
+			if len(merged) == 0 {
+				continue
+			}
+			values[x], has[x] = mergeMetricPoints(merged).Stat(s.Stat), true
+			lo, hi = math.Min(lo, values[x]), math.Max(hi, values[x])
+		}
+		item := chartLegendItem{label: metricLabel(s), detail: "no values", color: color}
+		if math.IsInf(hi, -1) {
+			chart.legend = append(chart.legend, item)
+			continue
+		}
+		if hi <= lo {
+			hi = lo + 1
+		}
+		item.detail = "↑" + compactValue(hi)
+		chart.legend = append(chart.legend, item)
+		if i == 0 {
+			top, middle = compactValue(hi), compactValue(lo+(hi-lo)*float64(rows/2)/float64(rows))
+		}
+
+		prev := -1
+		for x, value := range values {
+			if !has[x] {
+				prev = -1
+				continue
+			}
+			y := int(math.Round((value - lo) / (hi - lo) * float64(dots-1)))
+			from, to := y, y
+			if prev >= 0 {
+				from, to = minInt(prev, y), maxInt(prev, y)
+			}
+			for dy := from; dy <= to; dy++ {
+				r, c := rows-1-dy/4, x/2
+				cells[r][c] |= brailleDots[x%2][3-dy%4]
+				colors[r][c] = color
+			}
+			prev = y
+		}
+	}
+
+	for r := range cells {
+		var sb strings.Builder
+		if chart.Offset > 0 {
+			sb.WriteString(chartAxisLabel(rows-1-r, rows, top, middle))
+		}
+		for c, bits := range cells[r] {
+			if bits == 0 {
+				sb.WriteByte(' ')
+				continue
+			}
+			sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(colors[r][c])).Render(string(0x2800 + bits)))
+		}
+		chart.Rows = append(chart.Rows, sb.String())
+	}
+	var starts []time.Time
+	for i := 0; i < len(points); i += chart.Step {
+		starts = append(starts, points[i].Timestamp)
+	}
+	chart.Axis = strings.Repeat(" ", chart.Offset) + tb.chartTicks(starts, chart.Step, plotWidth, loc)
+	return chart
+}
+
+// metricLabel names a series by the last segment of its field and its
+// statistic, e.g. "latency_ms avg"
+func metricLabel(s MetricSeries) string {
+	parts := models.SplitFieldPath(s.Field)
+	if len(parts) == 0 {
+		return s.Field + " " + s.Stat
+	}
+	return parts[len(parts)-1] + " " + s.Stat
+}
+
+// compactValue renders a value in about four characters for the y axis
+func compactValue(v float64) string {
+	if math.Abs(v) >= 1000 {
+		sign := ""
+		if v < 0 {
+			sign = "-"
+		}
+		return sign + compactCount(int(math.Abs(v)))
+	}
+	return formatMetricValue(v, 3)
+}
+
+// formatMetricValue renders a value with about digits significant digits
+// and no trailing zeros, e.g. 12.5, 850 or 0.25
+func formatMetricValue(v float64, digits int) string {
+	if v != 0 && math.Abs(v) < 1 {
+		return strconv.FormatFloat(v, 'g', maxInt(1, digits-1), 64)
+	}
+	decimals := 0
+	for limit := math.Pow(10, float64(digits-1)); decimals < digits && math.Abs(v) < limit && limit > 1; limit /= 10 {
+		decimals++
+	}
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// metricSummary renders the statistics of a point, e.g.
+// "min 12 avg 48.3 max 850 p95 420 (34 values)"
+func metricSummary(p MetricPoint) string {
+	return fmt.Sprintf("min %s avg %s max %s p95 %s (%s values)",
+		formatMetricValue(p.Min, 4), formatMetricValue(p.Avg, 4), formatMetricValue(p.Max, 4), formatMetricValue(p.P95, 4), formatCount(p.Count))
+}
@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func metricTestLogs(start time.Time) []models.LogEntry {
+	var logs []models.LogEntry
+	for i := 0; i < 40; i++ {
+		logs = append(logs, models.LogEntry{
+			ID:          "m" + formatCount(i),
+			Timestamp:   start.Add(time.Duration(i) * 15 * time.Second),
+			JSONPayload: map[string]interface{}{"latency_ms": float64(10 * (i%4 + 1)), "queue_depth": float64(i / 4)},
+			HTTPRequest: &models.HTTPRequest{Latency: "0.250s"},
+		})
+	}
+	logs = append(logs, models.LogEntry{ID: "text", Timestamp: start, JSONPayload: map[string]interface{}{"latency_ms": "slow"}})
+	return logs
+}
+
+func TestNumericFieldValue(t *testing.T) {
+	logs := metricTestLogs(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	if v, ok := NumericFieldValue(logs[1], "jsonPayload.latency_ms"); !ok || v != 20 {
+		t.Errorf("Expected 20, got %v %v", v, ok)
+	}
+	if v, ok := NumericFieldValue(logs[0], "httpRequest.latency"); !ok || v != 0.25 {
+		t.Errorf("Expected the latency in seconds, got %v %v", v, ok)
+	}
+	if _, ok := NumericFieldValue(logs[len(logs)-1], "jsonPayload.latency_ms"); ok {
+		t.Error("Expected a text value to be skipped")
+	}
+}
+
+func TestBuildMetricSeries(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tb := NewTimelineBuilder(time.Minute)
+	points := tb.BuildMetricSeries(metricTestLogs(start), "jsonPayload.latency_ms")
+	if len(points) != 10 {
+		t.Fatalf("Expected 10 buckets, got %d", len(points))
+	}
+	want := MetricPoint{Timestamp: start, Count: 4, Min: 10, Avg: 25, Max: 40, P95: 40}
+	if points[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, points[0])
+	}
+	if got := metricSummary(points[0]); got != "min 10 avg 25 max 40 p95 40 (4 values)" {
+		t.Errorf("Unexpected summary %q", got)
+	}
+	merged := mergeMetricPoints([]MetricPoint{points[0], {Count: 2, Min: 5, Avg: 100, Max: 190, P95: 190}})
+	if merged.Count != 6 || merged.Min != 5 || merged.Avg != 50 || merged.Max != 190 {
+		t.Errorf("Unexpected merge %+v", merged)
+	}
+	for v, want := range map[float64]string{850: "850", 12.5: "12.5", 0.25: "0.25", 4: "4", 45600: "46k"} {
+		if got := compactValue(v); got != want {
+			t.Errorf("compactValue(%v) = %s, want %s", v, got, want)
+		}
+	}
+}
+
+func TestRenderMetricChart(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tb := NewTimelineBuilder(time.Minute)
+	logs := metricTestLogs(start)
+	points := tb.FillTimeline(tb.BuildTimeline(logs), start, start.Add(9*time.Minute))
+	series := []MetricSeries{
+		{Field: "jsonPayload.queue_depth", Stat: MetricMax, Points: tb.BuildMetricSeries(logs, "jsonPayload.queue_depth")},
+		{Field: "jsonPayload.missing", Stat: MetricAvg},
+	}
+	chart := tb.RenderMetricChart(points, series, 40, 2, time.UTC)
+	if len(chart.Rows) != 2 || chart.Step != 1 {
+		t.Fatalf("Expected 2 rows with a bucket a column, got %d rows, step %d", len(chart.Rows), chart.Step)
+	}
+	top := ansiEscapeRegex.ReplaceAllString(chart.Rows[0], "")
+	bottom := ansiEscapeRegex.ReplaceAllString(chart.Rows[1], "")
+	if !strings.HasPrefix(top, "   9┤") {
+		t.Errorf("Expected the scale of the first series, got %q", top)
+	}
+	// The queue grows by one a minute: the line starts low and ends high
+	if []rune(bottom)[chartAxisWidth] == ' ' || []rune(top)[chartAxisWidth+9] == ' ' {
+		t.Errorf("Expected a rising line, got\n%s\n%s", top, bottom)
+	}
+	if legend := ansiEscapeRegex.ReplaceAllString(chart.Legend(60), ""); legend != "■ queue_depth max ↑9  ■ missing avg no values" {
+		t.Errorf("Unexpected legend %q", legend)
+	}
+
+	// More buckets than columns are summed per dot column
+	long := tb.FillTimeline(points, start, start.Add(19*time.Minute))
+	narrow := tb.RenderMetricChart(long, series, 12, 2, time.UTC)
+	if narrow.Offset != 0 || narrow.Step != 2 {
+		t.Errorf("Expected no axis and 2 buckets a column, got offset %d, step %d", narrow.Offset, narrow.Step)
+	}
+}
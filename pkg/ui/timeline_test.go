@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -221,6 +223,54 @@ func TestGetTimelineStatsEmpty(t *testing.T) {
 	}
 }
 
+func anomalyTestPoints(counts []int, errors map[int]int) []models.LogGraphPoint {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	points := make([]models.LogGraphPoint, len(counts))
+	for i, count := range counts {
+		points[i] = models.LogGraphPoint{
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+			Count:     count,
+			Severity:  map[string]int{models.SeverityInfo: count - errors[i], models.SeverityError: errors[i]},
+		}
+	}
+	return points
+}
+
+func TestFillTimeline(t *testing.T) {
+	tb := NewTimelineBuilder(time.Minute)
+	points := anomalyTestPoints([]int{3, 4}, nil)
+	points[1].Timestamp = points[0].Timestamp.Add(3 * time.Minute)
+
+	filled := tb.FillTimeline(points, points[0].Timestamp, points[1].Timestamp)
+	if len(filled) != 4 || filled[1].Count != 0 || filled[3].Count != 4 {
+		t.Errorf("Expected 4 buckets with the gap empty, got %+v", filled)
+	}
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	tb := NewTimelineBuilder(time.Minute)
+
+	steady := anomalyTestPoints([]int{20, 22, 19, 21, 20, 23, 21, 20}, map[int]int{1: 1})
+	if anomalies := tb.DetectAnomalies(steady); len(anomalies) != 0 {
+		t.Errorf("Expected no anomalies in steady traffic, got %+v", anomalies)
+	}
+
+	counts := []int{20, 22, 19, 21, 20, 80, 21, 20, 22, 2, 21, 20}
+	anomalies := tb.DetectAnomalies(anomalyTestPoints(counts, map[int]int{7: 10}))
+	var got []string
+	for _, anomaly := range anomalies {
+		got = append(got, fmt.Sprintf("%d:%s", anomaly.Index, anomaly.Kind))
+	}
+	if want := "5:spike,7:errors,9:drop"; strings.Join(got, ",") != want {
+		t.Errorf("Expected %s, got %v", want, got)
+	}
+
+	// Too few buckets for a baseline
+	if anomalies := tb.DetectAnomalies(anomalyTestPoints([]int{1, 1, 90}, nil)); len(anomalies) != 0 {
+		t.Errorf("Expected no anomalies without a baseline, got %+v", anomalies)
+	}
+}
+
 // Helper functions
 
 func containsSparklineChar(s string) bool {
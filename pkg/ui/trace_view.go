@@ -0,0 +1,400 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/user/log-explorer-tui/pkg/models"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+// Trace view layouts
+const (
+	traceLayoutTree      = "tree"
+	traceLayoutWaterfall = "waterfall"
+)
+
+// traceSpan groups the entries of one span. Timing comes from the entry
+// timestamps, so a span with a single entry has no duration.
+type traceSpan struct {
+	id       string // "" for entries without a span
+	entries  []models.LogEntry
+	start    time.Time
+	end      time.Time
+	severity string // highest severity of the entries
+	service  string
+	depth    int
+	children []*traceSpan
+}
+
+func (s *traceSpan) duration() time.Duration {
+	return s.end.Sub(s.start)
+}
+
+// traceRow is a row of the trace view: a span, or one of its entries when
+// the span is expanded
+type traceRow struct {
+	span  *traceSpan
+	entry *models.LogEntry
+}
+
+// traceView is the state of the trace view
+type traceView struct {
+	trace    string
+	focus    string // key of the entry the view was opened from
+	loading  bool
+	spans    []*traceSpan // in tree order
+	start    time.Time
+	end      time.Time
+	expanded map[string]bool
+	cursor   int
+	layout   string
+}
+
+type traceResultMsg struct {
+	trace string
+	logs  []models.LogEntry
+	err   error
+}
+
+// buildTraceSpans groups entries by span and nests each span under the
+// tightest span whose time range contains it. Entries without a span come
+// last at the top level.
+func buildTraceSpans(logs []models.LogEntry) []*traceSpan {
+	byID := map[string]*traceSpan{}
+	var spans []*traceSpan
+	for _, entry := range logs {
+		span, ok := byID[entry.SpanID]
+		if !ok {
+			span = &traceSpan{id: entry.SpanID, start: entry.Timestamp, end: entry.Timestamp}
+			byID[entry.SpanID] = span
+			spans = append(spans, span)
+		}
+		span.entries = append(span.entries, entry)
+		if entry.Timestamp.Before(span.start) {
+			span.start = entry.Timestamp
+		}
+		if entry.Timestamp.After(span.end) {
+			span.end = entry.Timestamp
+		}
+		if models.SeverityRank(entry.Severity) > models.SeverityRank(span.severity) {
+			span.severity = entry.Severity
+		}
+		if span.service == "" {
+			span.service = traceService(entry)
+		}
+	}
+	for _, span := range spans {
+		sort.SliceStable(span.entries, func(i, j int) bool {
+			return span.entries[i].Timestamp.Before(span.entries[j].Timestamp)
+		})
+	}
+	// Containers sort before the spans they contain: by start, longest first
+	sort.SliceStable(spans, func(i, j int) bool {
+		if (spans[i].id == "") != (spans[j].id == "") {
+			return spans[j].id == ""
+		}
+		if !spans[i].start.Equal(spans[j].start) {
+			return spans[i].start.Before(spans[j].start)
+		}
+		return spans[i].duration() > spans[j].duration()
+	})
+	var roots []*traceSpan
+	for i, span := range spans {
+		var parent *traceSpan
+		for _, candidate := range spans[:i] {
+			if span.id == "" || candidate.id == "" {
+				continue
+			}
+			if candidate.start.After(span.start) || candidate.end.Before(span.end) {
+				continue
+			}
+			if parent == nil || candidate.duration() <= parent.duration() {
+				parent = candidate
+			}
+		}
+		if parent == nil {
+			roots = append(roots, span)
+			continue
+		}
+		parent.children = append(parent.children, span)
+	}
+	var ordered []*traceSpan
+	var walk func(span *traceSpan, depth int)
+	walk = func(span *traceSpan, depth int) {
+		span.depth = depth
+		ordered = append(ordered, span)
+		for _, child := range span.children {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+	return ordered
+}
+
+// traceService names what wrote an entry: the service, the container or the
+// log
+func traceService(entry models.LogEntry) string {
+	for _, key := range []string{"service_name", "container_name", "function_name", "module_id"} {
+		if value := entry.Resource.Labels[key]; value != "" {
+			return value
+		}
+	}
+	return models.LogID(entry.LogName)
+}
+
+// shortTraceID is the trace ID without the projects/<p>/traces/ prefix
+func shortTraceID(trace string) string {
+	if i := strings.LastIndex(trace, "/"); i >= 0 {
+		return trace[i+1:]
+	}
+	return trace
+}
+
+func (t *traceView) rows() []traceRow {
+	var rows []traceRow
+	for _, span := range t.spans {
+		rows = append(rows, traceRow{span: span})
+		if !t.expanded[span.id] {
+			continue
+		}
+		for i := range span.entries {
+			rows = append(rows, traceRow{span: span, entry: &span.entries[i]})
+		}
+	}
+	return rows
+}
+
+// openTraceView queries every entry of the selected entry's trace across the
+// time range and shows them grouped by span
+func (a *App) openTraceView() tea.Cmd {
+	entry := a.getSelectedLog()
+	if entry == nil {
+		return nil
+	}
+	if entry.Trace == "" {
+		a.lastErr = "The selected entry has no trace"
+		return nil
+	}
+	a.trace = traceView{
+		trace:    entry.Trace,
+		focus:    logEntryKey(*entry),
+		loading:  true,
+		expanded: map[string]bool{},
+		layout:   traceLayoutTree,
+	}
+	a.activeModalName = "trace"
+	trace := entry.Trace
+	if a.queryExec == nil {
+		var logs []models.LogEntry
+		for _, loaded := range a.state.LogListState.Logs {
+			if loaded.Trace == trace {
+				logs = append(logs, loaded)
+			}
+		}
+		a.applyTraceResult(traceResultMsg{trace: trace, logs: logs})
+		return nil
+	}
+	builder := query.NewBuilder("").AddCustomFilter("trace=" + strconv.Quote(trace))
+	if tr := a.state.FilterState.TimeRange; !tr.Start.IsZero() && !tr.End.IsZero() {
+		builder.AddTimeRange(tr)
+	}
+	filter := builder.Build()
+	project := a.state.CurrentProject
+	return func() tea.Msg {
+		logs, err := a.execQuery(project, filter)
+		return traceResultMsg{trace: trace, logs: logs, err: err}
+	}
+}
+
+// applyTraceResult builds the spans of a trace query result and puts the
+// cursor on the span of the entry the view was opened from
+func (a *App) applyTraceResult(msg traceResultMsg) {
+	if msg.trace != a.trace.trace {
+		return
+	}
+	a.trace.loading = false
+	if msg.err != nil {
+		a.lastErr = fmt.Sprintf("Trace query failed: %v", msg.err)
+		return
+	}
+	a.trace.spans = buildTraceSpans(msg.logs)
+	for i, span := range a.trace.spans {
+		if i == 0 || span.start.Before(a.trace.start) {
+			a.trace.start = span.start
+		}
+		if span.end.After(a.trace.end) {
+			a.trace.end = span.end
+		}
+	}
+	a.lastErr = fmt.Sprintf("Trace %s: %d spans, %d entries", shortTraceID(msg.trace), len(a.trace.spans), len(msg.logs))
+	for i, row := range a.trace.rows() {
+		for _, entry := range row.span.entries {
+			if logEntryKey(entry) == a.trace.focus {
+				a.trace.expanded[row.span.id] = true
+				a.trace.cursor = i
+				return
+			}
+		}
+	}
+}
+
+// handleTraceInput handles keys in the trace view
+func (a *App) handleTraceInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rows := a.trace.rows()
+	switch msg.String() {
+	case "esc", "T":
+		a.activeModalName = "none"
+	case "j", "down":
+		a.trace.cursor = minInt(a.trace.cursor+1, maxInt(0, len(rows)-1))
+	case "k", "up":
+		a.trace.cursor = maxInt(0, a.trace.cursor-1)
+	case "tab", "v":
+		if a.trace.layout == traceLayoutTree {
+			a.trace.layout = traceLayoutWaterfall
+		} else {
+			a.trace.layout = traceLayoutTree
+		}
+	case "l", "right", " ":
+		if a.trace.cursor < len(rows) {
+			a.trace.expanded[rows[a.trace.cursor].span.id] = true
+		}
+	case "h", "left":
+		if a.trace.cursor < len(rows) {
+			a.collapseTraceSpan(rows[a.trace.cursor].span)
+		}
+	case "enter":
+		if a.trace.cursor >= len(rows) {
+			return a, nil
+		}
+		row := rows[a.trace.cursor]
+		if row.entry == nil {
+			if a.trace.expanded[row.span.id] {
+				a.collapseTraceSpan(row.span)
+			} else {
+				a.trace.expanded[row.span.id] = true
+			}
+			return a, nil
+		}
+		a.jumpToEntry(*row.entry)
+	}
+	return a, nil
+}
+
+// collapseTraceSpan hides the entries of span and moves the cursor to its row
+func (a *App) collapseTraceSpan(span *traceSpan) {
+	delete(a.trace.expanded, span.id)
+	for i, row := range a.trace.rows() {
+		if row.span == span && row.entry == nil {
+			a.trace.cursor = i
+			return
+		}
+	}
+}
+
+func (a *App) renderTracePopup() string {
+	var sb strings.Builder
+	popupWidth := minInt(maxInt(70, a.width-10), 140)
+	inner := a.popupInnerWidth(popupWidth)
+	subtle := lipgloss.NewStyle().Foreground(lipgloss.Color(colorNeutralSubtle))
+	t := &a.trace
+
+	sb.WriteString(a.popupTop(popupWidth, "TRACE "+strings.ToUpper(t.layout)))
+	sb.WriteString(a.popupLine(popupWidth, shortTraceID(t.trace)))
+	rows := t.rows()
+	switch {
+	case t.loading:
+		sb.WriteString(a.popupLine(popupWidth, subtle.Render("Querying the entries of the trace...")))
+	default:
+		entries := 0
+		severity := ""
+		for _, span := range t.spans {
+			entries += len(span.entries)
+			if models.SeverityRank(span.severity) > models.SeverityRank(severity) {
+				severity = span.severity
+			}
+		}
+		summary := fmt.Sprintf("%d spans | %d entries | %s", len(t.spans), entries, formatLatency(t.end.Sub(t.start)))
+		if severity != "" {
+			summary += " | worst " + severityStyle(severity).Render(severity)
+		}
+		sb.WriteString(a.popupLine(popupWidth, subtle.Render(summary)))
+	}
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+	if !t.loading && len(rows) == 0 {
+		sb.WriteString(a.popupLine(popupWidth, "No entries found for this trace"))
+	}
+
+	visible := maxInt(3, a.height-14)
+	start := clampInt(t.cursor-visible/2, 0, maxInt(0, len(rows)-visible))
+	end := minInt(len(rows), start+visible)
+	for i := start; i < end; i++ {
+		line := a.renderTraceRow(rows[i], inner)
+		if i == t.cursor {
+			line = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(colorSelectionFG)).Background(lipgloss.Color(colorSelectionBG)).Render(ansiEscapeRegex.ReplaceAllString(line, ""))
+		}
+		sb.WriteString(a.popupLine(popupWidth, line))
+	}
+	sb.WriteString(a.popupSeparator(popupWidth, '━'))
+	sb.WriteString(a.popupLine(popupWidth, "Enter expand span / jump to entry in the list | h collapse | Tab tree/waterfall | Esc back"))
+	sb.WriteString(a.popupBottom(popupWidth, '━'))
+	return sb.String()
+}
+
+// renderTraceRow renders a span or entry row in at most width cells
+func (a *App) renderTraceRow(row traceRow, width int) string {
+	t := &a.trace
+	indent := strings.Repeat("  ", row.span.depth)
+	if t.layout == traceLayoutWaterfall {
+		indent = ""
+	}
+	if row.entry != nil {
+		offset := fitCell("+"+formatLatency(row.entry.Timestamp.Sub(t.start)), 8, columnAlignRight)
+		_, badge := severityBadge(row.entry.Severity)
+		prefix := fmt.Sprintf("%s    %s %-5s ", indent, offset, badge)
+		return severityStyle(row.entry.Severity).Render(prefix) + truncate(listMessage(*row.entry), maxInt(10, width-lipgloss.Width(prefix)))
+	}
+
+	marker := "▸"
+	if t.expanded[row.span.id] {
+		marker = "▾"
+	}
+	name := row.span.id
+	if name == "" {
+		name = "(no span)"
+	}
+	duration := "-"
+	if d := row.span.duration(); d > 0 {
+		duration = formatLatency(d)
+	}
+	const statsWidth = 12
+	stats := fmt.Sprintf("%3d  %s", len(row.span.entries), fitCell(duration, 7, columnAlignRight))
+	sev := severityStyle(row.span.severity)
+	label := func(width int) string {
+		// fitCell collapses spaces, so the tree indent goes in front of it
+		return indent + fitCell(marker+" "+truncate(name, 16)+" "+row.span.service, maxInt(8, width-len(indent)), columnAlignLeft)
+	}
+	if t.layout == traceLayoutTree {
+		return label(maxInt(20, width-statsWidth-10)) + " " + stats + "  " + sev.Render(row.span.severity)
+	}
+
+	labelWidth := minInt(36, width/3)
+	barWidth := maxInt(10, width-labelWidth-statsWidth-2)
+	total := t.end.Sub(t.start)
+	offset, length := 0, 1
+	if total > 0 {
+		offset = int(float64(row.span.start.Sub(t.start)) / float64(total) * float64(barWidth-1))
+		length = maxInt(1, int(float64(row.span.duration())/float64(total)*float64(barWidth)))
+	}
+	offset = clampInt(offset, 0, barWidth-1)
+	length = minInt(length, barWidth-offset)
+	bar := strings.Repeat(" ", offset) + sev.Render(strings.Repeat("█", length)) + strings.Repeat(" ", barWidth-offset-length)
+	return label(labelWidth) + " " + bar + " " + stats
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+const testTrace = "projects/api-project/traces/4bf92f3577b34da6a3ce929d0e0e4736"
+
+func traceTestLogs() []models.LogEntry {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	entry := func(id, span, severity string, offset time.Duration) models.LogEntry {
+		return models.LogEntry{
+			ID:        id,
+			Timestamp: base.Add(offset),
+			Severity:  severity,
+			Message:   "step " + id,
+			Trace:     testTrace,
+			SpanID:    span,
+			Resource:  models.Resource{Type: "cloud_run_revision", Labels: map[string]string{"service_name": "svc-" + span}},
+		}
+	}
+	return []models.LogEntry{
+		entry("1", "root", "INFO", 0),
+		entry("2", "db", "INFO", 20*time.Millisecond),
+		entry("3", "db", "ERROR", 80*time.Millisecond),
+		entry("4", "cache", "INFO", 90*time.Millisecond),
+		entry("5", "root", "INFO", 200*time.Millisecond),
+		entry("6", "", "DEBUG", 50*time.Millisecond),
+	}
+}
+
+func TestBuildTraceSpans(t *testing.T) {
+	spans := buildTraceSpans(traceTestLogs())
+	var got []string
+	for _, span := range spans {
+		got = append(got, strings.Repeat(">", span.depth)+span.id)
+	}
+	if strings.Join(got, ",") != "root,>db,>cache," {
+		t.Fatalf("Unexpected span tree %v", got)
+	}
+	root, db := spans[0], spans[1]
+	if root.duration() != 200*time.Millisecond || db.duration() != 60*time.Millisecond {
+		t.Errorf("Unexpected span durations root=%v db=%v", root.duration(), db.duration())
+	}
+	if db.severity != "ERROR" || root.severity != "INFO" {
+		t.Errorf("Expected severity roll-up per span, got root=%s db=%s", root.severity, db.severity)
+	}
+	if db.service != "svc-db" {
+		t.Errorf("Expected the span service, got %q", db.service)
+	}
+}
+
+func TestTraceViewQueriesTraceAndJumpsBack(t *testing.T) {
+	app := newTabsTestApp(t)
+	logs := traceTestLogs()
+	app.state.LogListState.Logs = logs
+	var ran string
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		ran = filter
+		return logs, nil
+	})
+	app.panes.LogList.scrollOffset = 2 // entry 3, in span db
+
+	_, cmd := app.handleKeyPress(runeKey('T'))
+	if cmd == nil || app.activeModalName != "trace" {
+		t.Fatal("Expected T to open the trace view and query the trace")
+	}
+	model, _ := app.Update(cmd())
+	app = model.(*App)
+	if ran != `trace="`+testTrace+`"` {
+		t.Errorf("Unexpected trace query %q", ran)
+	}
+	rows := app.trace.rows()
+	if row := rows[app.trace.cursor]; row.span.id != "db" || row.entry != nil || !app.trace.expanded["db"] {
+		t.Fatalf("Expected the cursor on the expanded db span, got %+v", row)
+	}
+
+	view := ansiEscapeRegex.ReplaceAllString(app.View(), "")
+	for _, want := range []string{"TRACE TREE", "4bf92f3577b34da6a3ce929d0e0e4736", "4 spans | 6 entries", "+80ms"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the trace view", want)
+		}
+	}
+	app = pressKeys(app, tea.KeyMsg{Type: tea.KeyTab})
+	if view := ansiEscapeRegex.ReplaceAllString(app.View(), ""); !strings.Contains(view, "TRACE WATERFALL") || !strings.Contains(view, "█") {
+		t.Error("Expected the waterfall layout with span bars")
+	}
+
+	// The first entry row of db is entry 2
+	app = pressKeys(app, runeKey('j'), tea.KeyMsg{Type: tea.KeyEnter})
+	if app.activeModalName != "none" {
+		t.Fatalf("Expected Enter on an entry to return to the list, got %q", app.activeModalName)
+	}
+	if selected := app.getSelectedLog(); selected == nil || selected.ID != "2" {
+		t.Errorf("Expected entry 2 selected, got %+v", selected)
+	}
+}
+
+func TestTraceViewNeedsTrace(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.state.LogListState.Logs = []models.LogEntry{{ID: "1", Message: "no trace"}}
+	if cmd := app.openTraceView(); cmd != nil || app.activeModalName == "trace" {
+		t.Error("Expected no trace view for an entry without a trace")
+	}
+	if !strings.Contains(app.lastErr, "no trace") {
+		t.Errorf("Expected a status message, got %q", app.lastErr)
+	}
+}
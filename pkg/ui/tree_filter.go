@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+	"github.com/user/log-explorer-tui/pkg/query"
+)
+
+// Tree filter actions on the selected JSON tree node
+const (
+	treeFilterInclude = "include" // path="value"
+	treeFilterExclude = "exclude" // NOT path="value"
+	treeFilterExists  = "exists"  // path:*
+)
+
+// detailNodeField maps JSON tree path segments to the Cloud Logging field
+// they came from. Array indexes are dropped: a comparison on a repeated
+// field matches when any element does.
+func detailNodeField(entry models.LogEntry, keys []string) ([]string, error) {
+	var fields []string
+	for _, key := range keys {
+		if !strings.HasPrefix(key, "[") {
+			fields = append(fields, key)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("select a field below the root")
+	}
+	switch fields[0] {
+	case "payload":
+		if entry.JSONPayload == nil {
+			return nil, fmt.Errorf("JSON parsed from textPayload cannot be filtered in the query")
+		}
+		return append([]string{"jsonPayload"}, fields[1:]...), nil
+	case "severity":
+		if entry.SeverityField != "" {
+			// The server only has DEFAULT; filter on the level in the payload
+			return models.SplitFieldPath(entry.SeverityField), nil
+		}
+	case "severityInferredFrom":
+		return nil, fmt.Errorf("select the severity or the payload field instead")
+	case "message":
+		if entry.MessageField != "" {
+			return models.SplitFieldPath(entry.MessageField), nil
+		}
+		if entry.JSONPayload != nil && entry.TextPayload == "" {
+			if _, ok := entry.JSONPayload["message"]; !ok {
+				return nil, fmt.Errorf("the message summarizes the payload; select a payload field")
+			}
+		}
+		if entry.TextPayload != "" || entry.JSONPayload == nil {
+			return []string{"textPayload"}, nil
+		}
+		return []string{"jsonPayload", "message"}, nil
+	}
+	return fields, nil
+}
+
+// treeFilterClause builds the query clause for an action on a tree node
+func treeFilterClause(entry models.LogEntry, line jsonTreeLine, action string) (string, error) {
+	fields, err := detailNodeField(entry, line.segments)
+	if err != nil {
+		return "", err
+	}
+	path := query.FieldPath(fields...)
+	if action == treeFilterExists {
+		return path + ":*", nil
+	}
+	value, ok := query.FormatFilterValue(line.value)
+	if line.segments[0] == "severity" && entry.SeverityField != "" {
+		raw, _ := entry.FieldValue(entry.SeverityField)
+		value, ok = strconv.Quote(raw), true
+	}
+	if fields[0] == "timestamp" {
+		// The tree shows the display time zone; filter on the exact instant
+		value, ok = strconv.Quote(entry.Timestamp.UTC().Format(time.RFC3339Nano)), true
+	}
+	if !ok {
+		return "", fmt.Errorf("select a value to match (* filters on presence)")
+	}
+	if action == treeFilterExclude {
+		return "NOT " + path + "=" + value, nil
+	}
+	return path + "=" + value, nil
+}
+
+// filterBySelectedNode appends a clause for the selected JSON tree node to
+// the current query and re-runs it
+func (a *App) filterBySelectedNode(action string) tea.Cmd {
+	entry := a.getSelectedLog()
+	if entry == nil || a.detailViewMode != "json-tree" {
+		a.lastErr = "Switch to the JSON tree view to filter by a value"
+		return nil
+	}
+	lines := a.currentJSONTreeLines()
+	if a.detailCursor < 0 || a.detailCursor >= len(lines) {
+		a.lastErr = "No payload node selected"
+		return nil
+	}
+	clause, err := treeFilterClause(*entry, lines[a.detailCursor], action)
+	if err != nil {
+		a.lastErr = "Cannot filter: " + err.Error()
+		return nil
+	}
+	a.activeModalName = "none"
+	a.detailScroll = 0
+	a.detailCursor = 0
+	a.lastErr = "Added to query: " + clause
+	return a.appendToQueryAndRun(clause)
+}
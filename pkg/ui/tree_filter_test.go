@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/user/log-explorer-tui/pkg/models"
+)
+
+func treeFilterTestEntry() models.LogEntry {
+	return models.LogEntry{
+		Timestamp:   time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Severity:    "ERROR",
+		Message:     "payment failed",
+		Labels:      map[string]string{"k8s-pod/app": "billing"},
+		Resource:    models.Resource{Type: "k8s_container", Labels: map[string]string{"namespace_name": "payments"}},
+		JSONPayload: map[string]interface{}{"message": "payment failed", "status": float64(502), "tags": []interface{}{"retry"}, "user": map[string]interface{}{"id": "u-1"}},
+	}
+}
+
+func treeLineAt(t *testing.T, app *App, path string) int {
+	t.Helper()
+	for i, line := range app.currentJSONTreeLines() {
+		if line.path == path {
+			return i
+		}
+	}
+	t.Fatalf("No tree line %q", path)
+	return -1
+}
+
+func TestTreeFilterClause(t *testing.T) {
+	entry := treeFilterTestEntry()
+	cases := []struct {
+		keys   []string
+		value  interface{}
+		action string
+		want   string
+	}{
+		{[]string{"payload", "user", "id"}, "u-1", treeFilterInclude, `jsonPayload.user.id="u-1"`},
+		{[]string{"payload", "status"}, float64(502), treeFilterExclude, `NOT jsonPayload.status=502`},
+		{[]string{"payload", "tags", "[0]"}, "retry", treeFilterInclude, `jsonPayload.tags="retry"`},
+		{[]string{"payload", "user"}, map[string]interface{}{"id": "u-1"}, treeFilterExists, `jsonPayload.user:*`},
+		{[]string{"labels", "k8s-pod/app"}, "billing", treeFilterInclude, `labels."k8s-pod/app"="billing"`},
+		{[]string{"resource", "labels", "namespace_name"}, "payments", treeFilterExclude, `NOT resource.labels.namespace_name="payments"`},
+		{[]string{"severity"}, "ERROR", treeFilterInclude, `severity="ERROR"`},
+		{[]string{"message"}, "payment failed", treeFilterInclude, `jsonPayload.message="payment failed"`},
+		{[]string{"timestamp"}, "2024-05-01T12:00:00+02:00", treeFilterInclude, `timestamp="2024-05-01T10:00:00Z"`},
+	}
+	for _, tc := range cases {
+		got, err := treeFilterClause(entry, jsonTreeLine{segments: tc.keys, value: tc.value}, tc.action)
+		if err != nil || got != tc.want {
+			t.Errorf("%v %s: got %q (%v), want %q", tc.keys, tc.action, got, err, tc.want)
+		}
+	}
+
+	if _, err := treeFilterClause(entry, jsonTreeLine{segments: []string{"payload", "user"}, value: map[string]interface{}{}}, treeFilterInclude); err == nil {
+		t.Error("Expected an error matching an object by value")
+	}
+	ruled := entry
+	ruled.MessageField = "jsonPayload.msg"
+	if got, _ := treeFilterClause(ruled, jsonTreeLine{segments: []string{"message"}, value: "payment failed"}, treeFilterInclude); got != `jsonPayload.msg="payment failed"` {
+		t.Errorf("Expected the message to filter on the field it was taken from, got %q", got)
+	}
+	summary := models.LogEntry{Message: "a=1", JSONPayload: map[string]interface{}{"a": float64(1)}}
+	if _, err := treeFilterClause(summary, jsonTreeLine{segments: []string{"message"}, value: "a=1"}, treeFilterInclude); err == nil {
+		t.Error("Expected an error filtering on a payload summary")
+	}
+	parsed := models.LogEntry{TextPayload: `{"a":"b"}`}
+	if _, err := treeFilterClause(parsed, jsonTreeLine{segments: []string{"payload", "a"}, value: "b"}, treeFilterInclude); err == nil {
+		t.Error("Expected an error for JSON parsed from textPayload")
+	}
+}
+
+func TestTreeFilterAppendsToQueryAndRuns(t *testing.T) {
+	app := newTabsTestApp(t)
+	app.state.LogListState.Logs = []models.LogEntry{treeFilterTestEntry()}
+	var filters []string
+	app.SetQueryExecutor(func(filter string) ([]models.LogEntry, error) {
+		filters = append(filters, filter)
+		return nil, nil
+	})
+	newModel, _ := app.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	app = newModel.(*App)
+	app.detailTreeExpanded["$.payload"] = true
+	app.detailCursor = treeLineAt(t, app, "$.payload.status")
+
+	newModel, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'!'}})
+	app = newModel.(*App)
+	if want := "resource.type=\"api\"\nNOT jsonPayload.status=502"; app.state.CurrentQuery.Filter != want {
+		t.Errorf("Unexpected query %q", app.state.CurrentQuery.Filter)
+	}
+	if app.activeModalName != "none" || cmd == nil {
+		t.Fatalf("Expected the popup to close and the query to run, modal=%q", app.activeModalName)
+	}
+	cmd()
+	if len(filters) != 1 || !strings.Contains(filters[0], "NOT jsonPayload.status=502") {
+		t.Errorf("Expected the clause in the executed filter, got %v", filters)
+	}
+}